@@ -44,7 +44,112 @@ type AzureValidatorSpec struct {
 	// +kubebuilder:validation:MaxItems=5
 	// +kubebuilder:validation:XValidation:message="QuotaRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
 	QuotaRules []QuotaRule `json:"quotaRules,omitempty" yaml:"quotaRules,omitempty"`
-	Auth       AzureAuth   `json:"auth" yaml:"auth"`
+	// Rules for validating that resource providers are registered in a subscription.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ResourceProviderRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ResourceProviderRules []ResourceProviderRule `json:"resourceProviderRules,omitempty" yaml:"resourceProviderRules,omitempty"`
+	// Rules for validating that VM sizes are offered to a subscription in a location, and
+	// optionally in availability zones, without restrictions.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="VMSizeRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	VMSizeRules []VMSizeRule `json:"vmSizeRules,omitempty" yaml:"vmSizeRules,omitempty"`
+	// Rules for validating that the legal terms of marketplace images have been accepted in a
+	// subscription and that the images exist in a location.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="MarketplaceImageRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	MarketplaceImageRules []MarketplaceImageRule `json:"marketplaceImageRules,omitempty" yaml:"marketplaceImageRules,omitempty"`
+	// Rules for validating that images exist in a private or RBAC-shared Azure Compute Gallery and
+	// that image versions are replicated to target regions.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="GalleryImageRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	GalleryImageRules []GalleryImageRule `json:"galleryImageRules,omitempty" yaml:"galleryImageRules,omitempty"`
+	// Rules for validating that subnets of virtual networks exist, have enough free IP addresses,
+	// and optionally have required delegations and service endpoints.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="SubnetRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	SubnetRules []SubnetRule `json:"subnetRules,omitempty" yaml:"subnetRules,omitempty"`
+	// Rules for validating that network security groups allow required network flows.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="NetworkSecurityGroupRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	NetworkSecurityGroupRules []NetworkSecurityGroupRule `json:"networkSecurityGroupRules,omitempty" yaml:"networkSecurityGroupRules,omitempty"`
+	// Rules for validating that no Azure Policy assignments deny the deployment of resources.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="PolicyRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	PolicyRules []PolicyRule `json:"policyRules,omitempty" yaml:"policyRules,omitempty"`
+	// Rules for validating that Key Vault secrets, keys, and certificates exist, are usable, and can
+	// be read by a principal.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="KeyVaultRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	KeyVaultRules []KeyVaultRule `json:"keyVaultRules,omitempty" yaml:"keyVaultRules,omitempty"`
+	// Rules for validating that storage accounts are configured as required and have required blob
+	// containers.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="StorageAccountRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	StorageAccountRules []StorageAccountRule `json:"storageAccountRules,omitempty" yaml:"storageAccountRules,omitempty"`
+	// Rules for validating that DNS zones exist, that principals can create records in them, and that
+	// private DNS zones are linked to virtual networks.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="DNSZoneRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	DNSZoneRules []DNSZoneRule `json:"dnsZoneRules,omitempty" yaml:"dnsZoneRules,omitempty"`
+	// Rules for validating that resource groups exist with an expected location and tags, or don't
+	// exist.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ResourceGroupRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ResourceGroupRules []ResourceGroupRule `json:"resourceGroupRules,omitempty" yaml:"resourceGroupRules,omitempty"`
+	// Rules for validating that user-assigned managed identities exist and have federated identity
+	// credentials.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ManagedIdentityRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ManagedIdentityRules []ManagedIdentityRule `json:"managedIdentityRules,omitempty" yaml:"managedIdentityRules,omitempty"`
+	// Rules for validating that the client secrets and certificates of Microsoft Entra ID applications
+	// don't expire soon.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ApplicationCredentialRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ApplicationCredentialRules []ApplicationCredentialRule `json:"applicationCredentialRules,omitempty" yaml:"applicationCredentialRules,omitempty"`
+	// Rules for validating that AKS clusters run a supported Kubernetes version, are healthy, and are
+	// configured as expected.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="AKSClusterRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	AKSClusterRules []AKSClusterRule `json:"aksClusterRules,omitempty" yaml:"aksClusterRules,omitempty"`
+	// Rules for validating that subscriptions are enabled, in the expected tenant and management group,
+	// and don't have spending limits.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="SubscriptionRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	SubscriptionRules []SubscriptionRule `json:"subscriptionRules,omitempty" yaml:"subscriptionRules,omitempty"`
+	// Rules for validating that no management locks block operations at a scope.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="LockRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	LockRules []LockRule `json:"lockRules,omitempty" yaml:"lockRules,omitempty"`
+	// Rules for validating that custom role definitions have the expected permissions and assignable
+	// scopes.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="RoleDefinitionRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	RoleDefinitionRules []RoleDefinitionRule `json:"roleDefinitionRules,omitempty" yaml:"roleDefinitionRules,omitempty"`
+	// Rules for validating that resources in a subscription or resource group have required tags.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="TagRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	TagRules []TagRule `json:"tagRules,omitempty" yaml:"tagRules,omitempty"`
+	// Rules for validating the results of Azure Resource Graph queries.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ResourceGraphQueryRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ResourceGraphQueryRules []ResourceGraphQueryRule `json:"resourceGraphQueryRules,omitempty" yaml:"resourceGraphQueryRules,omitempty"`
+	// Rules for validating the Microsoft Entra ID groups principals are members of.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="GroupMembershipRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	GroupMembershipRules []GroupMembershipRule `json:"groupMembershipRules,omitempty" yaml:"groupMembershipRules,omitempty"`
+	// Rules for validating that preview features are registered in subscriptions.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="FeatureRegistrationRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	FeatureRegistrationRules []FeatureRegistrationRule `json:"featureRegistrationRules,omitempty" yaml:"featureRegistrationRules,omitempty"`
+	// Rules for validating the unallocated capacity of capacity reservation groups.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="CapacityReservationRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	CapacityReservationRules []CapacityReservationRule `json:"capacityReservationRules,omitempty" yaml:"capacityReservationRules,omitempty"`
+	// Rules for validating budgets and the spend against them.
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="BudgetRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	BudgetRules []BudgetRule `json:"budgetRules,omitempty" yaml:"budgetRules,omitempty"`
+	Auth        AzureAuth    `json:"auth" yaml:"auth"`
 }
 
 var _ plugins.PluginSpec = (*AzureValidatorSpec)(nil)
@@ -56,7 +161,14 @@ func (s AzureValidatorSpec) PluginCode() string {
 
 // ResultCount returns the number of validation results expected for an AzureValidatorSpec.
 func (s AzureValidatorSpec) ResultCount() int {
-	return len(s.RBACRules) + len(s.CommunityGalleryImageRules) + len(s.QuotaRules)
+	return len(s.RBACRules) + len(s.CommunityGalleryImageRules) + len(s.QuotaRules) +
+		len(s.ResourceProviderRules) + len(s.VMSizeRules) + len(s.MarketplaceImageRules) +
+		len(s.GalleryImageRules) + len(s.SubnetRules) + len(s.NetworkSecurityGroupRules) +
+		len(s.PolicyRules) + len(s.KeyVaultRules) + len(s.StorageAccountRules) + len(s.DNSZoneRules) +
+		len(s.ResourceGroupRules) + len(s.ManagedIdentityRules) + len(s.ApplicationCredentialRules) +
+		len(s.AKSClusterRules) + len(s.SubscriptionRules) + len(s.LockRules) + len(s.RoleDefinitionRules) +
+		len(s.TagRules) + len(s.ResourceGraphQueryRules) + len(s.GroupMembershipRules) +
+		len(s.FeatureRegistrationRules) + len(s.CapacityReservationRules) + len(s.BudgetRules)
 }
 
 // RBACRule verifies that a security principal has permissions via role assignments and that no deny
@@ -98,6 +210,7 @@ func (r *RBACRule) SetName(name string) {
 
 // CommunityGalleryImageRule verifies that one or more images in a community gallery exist and are
 // accessible by a particular subscription.
+// +kubebuilder:validation:XValidation:message="At least one of images or imageRequirements must be provided",rule="(has(self.images) && size(self.images) > 0) || (has(self.imageRequirements) && size(self.imageRequirements) > 0)"
 type CommunityGalleryImageRule struct {
 	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
 
@@ -108,9 +221,13 @@ type CommunityGalleryImageRule struct {
 	// Gallery is the community gallery.
 	Gallery CommunityGallery `json:"gallery" yaml:"gallery"`
 	// Images is a list of image names.
-	// +kubebuilder:validation:MinItems=1
 	// +kubebuilder:validation:MaxItems=1000
-	Images []string `json:"images" yaml:"images"`
+	Images []string `json:"images,omitempty" yaml:"images,omitempty"`
+	// ImageRequirements is a list of images with requirements for their versions, the regions
+	// their versions are published to, and their properties. Images listed here don't also need to
+	// be listed in Images.
+	// +kubebuilder:validation:MaxItems=1000
+	ImageRequirements []CommunityGalleryImage `json:"imageRequirements,omitempty" yaml:"imageRequirements,omitempty"`
 	// SubscriptionID is the ID of the subscription.
 	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
 }
@@ -186,6 +303,1101 @@ type CommunityGallery struct {
 	Name string `json:"name" yaml:"name"`
 }
 
+// CommunityGalleryImage is an image in a community gallery, with requirements for it.
+type CommunityGalleryImage struct {
+	// Name is the name of the image.
+	Name string `json:"name" yaml:"name"`
+	// Version is the version of the image that must exist, must not be past its end of life date,
+	// and must not be excluded from latest. If set to "latest", the latest version of the image
+	// must exist and must not be past its end of life date. If not provided, only the image is
+	// checked.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Regions is a list of regions (e.g. "eastus"), in addition to the location of the gallery,
+	// that the image version must be published to. Ignored if Version is not provided.
+	// +kubebuilder:validation:MaxItems=100
+	Regions []string `json:"regions,omitempty" yaml:"regions,omitempty"`
+	// OSType is the type of OS the image must contain. If not provided, it is not checked.
+	// +kubebuilder:validation:Enum=Linux;Windows
+	OSType string `json:"osType,omitempty" yaml:"osType,omitempty"`
+	// OSState is the state the OS in the image must be in. If not provided, it is not checked.
+	// +kubebuilder:validation:Enum=Generalized;Specialized
+	OSState string `json:"osState,omitempty" yaml:"osState,omitempty"`
+	// HyperVGeneration is the Hyper-V generation of VMs the image must be for. If not provided,
+	// it is not checked.
+	// +kubebuilder:validation:Enum=V1;V2
+	HyperVGeneration string `json:"hyperVGeneration,omitempty" yaml:"hyperVGeneration,omitempty"`
+	// Architecture is the CPU architecture the image must support. If not provided, it is not
+	// checked.
+	// +kubebuilder:validation:Enum=x64;Arm64
+	Architecture string `json:"architecture,omitempty" yaml:"architecture,omitempty"`
+	// SecurityType is the security type the image must support (e.g. "TrustedLaunch"). Images
+	// without a security type feature have the security type "Standard". If not provided, it is
+	// not checked.
+	SecurityType string `json:"securityType,omitempty" yaml:"securityType,omitempty"`
+}
+
+// ResourceProviderRule verifies that one or more resource providers are registered in a
+// subscription.
+type ResourceProviderRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// Namespaces is a list of resource provider namespaces (e.g. "Microsoft.Compute") that must be
+	// in the "Registered" state.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=100
+	Namespaces []string `json:"namespaces" yaml:"namespaces"`
+	// SubscriptionID is the ID of the subscription.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*ResourceProviderRule)(nil)
+
+// Name returns the name of the resource provider rule.
+func (r ResourceProviderRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the resource provider rule.
+func (r *ResourceProviderRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// VMSizeRule verifies that one or more VM sizes are offered to a subscription in a location, and
+// optionally in availability zones, and that no restrictions prevent the subscription from using
+// them there.
+type VMSizeRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// Location is the location the VM sizes must be offered in (e.g. "eastus").
+	Location string `json:"location" yaml:"location"`
+	// Zones is an optional list of availability zones (e.g. "1", "2", "3") in the location that
+	// the VM sizes must be offered in. If not provided, only the location is checked.
+	// +kubebuilder:validation:MaxItems=3
+	Zones []string `json:"zones,omitempty" yaml:"zones,omitempty"`
+	// VMSizes is a list of VM sizes (e.g. "Standard_D4s_v5").
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=100
+	VMSizes []string `json:"vmSizes" yaml:"vmSizes"`
+	// SubscriptionID is the ID of the subscription.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*VMSizeRule)(nil)
+
+// Name returns the name of the VM size rule.
+func (r VMSizeRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the VM size rule.
+func (r *VMSizeRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// MarketplaceImageRule verifies that the legal terms of one or more plan-based marketplace images
+// have been accepted in a subscription and that the images exist in a location.
+type MarketplaceImageRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// Location is the location the images must exist in (e.g. "westus").
+	Location string `json:"location" yaml:"location"`
+	// Images is a list of marketplace images.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=100
+	Images []MarketplaceImage `json:"images" yaml:"images"`
+	// SubscriptionID is the ID of the subscription.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*MarketplaceImageRule)(nil)
+
+// Name returns the name of the marketplace image rule.
+func (r MarketplaceImageRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the marketplace image rule.
+func (r *MarketplaceImageRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// MarketplaceImage is a plan-based image published to the Azure Marketplace.
+type MarketplaceImage struct {
+	// Publisher is the publisher of the image (e.g. "cognosys").
+	Publisher string `json:"publisher" yaml:"publisher"`
+	// Offer is the offer of the image.
+	Offer string `json:"offer" yaml:"offer"`
+	// Plan is the plan of the image whose legal terms must be accepted.
+	Plan string `json:"plan" yaml:"plan"`
+	// SKU is the SKU of the image. If not provided, Plan is used, because the plan of a
+	// marketplace image is usually the same as its SKU.
+	SKU string `json:"sku,omitempty" yaml:"sku,omitempty"`
+	// Version is the version of the image that must exist. If not provided or set to "latest", at
+	// least one version of the image must exist.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// GalleryImageRule verifies that one or more image definitions exist in a private or RBAC-shared
+// Azure Compute Gallery and, optionally, that image versions are replicated to target regions.
+type GalleryImageRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// Gallery is the Azure Compute Gallery.
+	Gallery Gallery `json:"gallery" yaml:"gallery"`
+	// Images is a list of images in the gallery.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=1000
+	Images []GalleryImage `json:"images" yaml:"images"`
+	// SubscriptionID is the ID of the subscription the gallery is in.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*GalleryImageRule)(nil)
+
+// Name returns the name of the gallery image rule.
+func (r GalleryImageRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the gallery image rule.
+func (r *GalleryImageRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// Gallery is an Azure Compute Gallery in a particular resource group.
+type Gallery struct {
+	// ResourceGroup is the resource group of the gallery.
+	ResourceGroup string `json:"resourceGroup" yaml:"resourceGroup"`
+	// Name is the name of the gallery.
+	Name string `json:"name" yaml:"name"`
+}
+
+// GalleryImage is an image definition in an Azure Compute Gallery.
+type GalleryImage struct {
+	// Name is the name of the image definition.
+	Name string `json:"name" yaml:"name"`
+	// Version is the image version that must exist, with a "Succeeded" provisioning state. If not
+	// provided, only the image definition is checked.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Regions is a list of regions (e.g. "westus") the image version must be replicated to. Ignored
+	// if Version is not provided.
+	// +kubebuilder:validation:MaxItems=100
+	Regions []string `json:"regions,omitempty" yaml:"regions,omitempty"`
+}
+
+// SubnetRule verifies that a subnet of a virtual network exists, has at least a minimum number of
+// free IP addresses, and optionally has delegations and service endpoints.
+type SubnetRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// ResourceGroup is the resource group of the virtual network.
+	ResourceGroup string `json:"resourceGroup" yaml:"resourceGroup"`
+	// VirtualNetwork is the name of the virtual network.
+	VirtualNetwork string `json:"virtualNetwork" yaml:"virtualNetwork"`
+	// Subnet is the name of the subnet in the virtual network.
+	Subnet string `json:"subnet" yaml:"subnet"`
+	// MinFreeIPs is the minimum number of IPv4 addresses in the subnet that must be free. Free IP
+	// addresses are the addresses in the subnet's address prefixes, minus the 5 addresses Azure
+	// reserves in each prefix, minus the IP configurations already in the subnet.
+	// +kubebuilder:validation:Minimum=0
+	MinFreeIPs int `json:"minFreeIPs,omitempty" yaml:"minFreeIPs,omitempty"`
+	// Delegations is a list of services (e.g. "Microsoft.ContainerService/managedClusters") the
+	// subnet must be delegated to.
+	// +kubebuilder:validation:MaxItems=20
+	Delegations []string `json:"delegations,omitempty" yaml:"delegations,omitempty"`
+	// ServiceEndpoints is a list of services (e.g. "Microsoft.Storage") the subnet must have
+	// service endpoints for.
+	// +kubebuilder:validation:MaxItems=20
+	ServiceEndpoints []string `json:"serviceEndpoints,omitempty" yaml:"serviceEndpoints,omitempty"`
+	// SubscriptionID is the ID of the subscription the virtual network is in.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*SubnetRule)(nil)
+
+// Name returns the name of the subnet rule.
+func (r SubnetRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the subnet rule.
+func (r *SubnetRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// NetworkSecurityGroupRule verifies that a network security group allows one or more network
+// flows. The network security group is either specified by name or is the one associated with a
+// subnet of a virtual network. Only that network security group is checked; network security
+// groups associated with network interfaces, and the effective security rules that combine them,
+// are not.
+// +kubebuilder:validation:XValidation:message="Exactly one of networkSecurityGroup or virtualNetwork and subnet must be provided",rule="has(self.networkSecurityGroup) ? !has(self.virtualNetwork) && !has(self.subnet) : has(self.virtualNetwork) && has(self.subnet)"
+type NetworkSecurityGroupRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// ResourceGroup is the resource group of the network security group, or of the virtual network
+	// if VirtualNetwork and Subnet are provided.
+	ResourceGroup string `json:"resourceGroup" yaml:"resourceGroup"`
+	// NetworkSecurityGroup is the name of the network security group.
+	NetworkSecurityGroup string `json:"networkSecurityGroup,omitempty" yaml:"networkSecurityGroup,omitempty"`
+	// VirtualNetwork is the name of the virtual network of the subnet whose network security group
+	// is checked.
+	VirtualNetwork string `json:"virtualNetwork,omitempty" yaml:"virtualNetwork,omitempty"`
+	// Subnet is the name of the subnet whose network security group is checked. If the subnet has
+	// no network security group, all flows are allowed.
+	Subnet string `json:"subnet,omitempty" yaml:"subnet,omitempty"`
+	// Flows is a list of network flows that must be allowed by the network security group.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=100
+	Flows []NetworkFlow `json:"flows" yaml:"flows"`
+	// SubscriptionID is the ID of the subscription the network security group or virtual network
+	// is in.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*NetworkSecurityGroupRule)(nil)
+
+// Name returns the name of the network security group rule.
+func (r NetworkSecurityGroupRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the network security group rule.
+func (r *NetworkSecurityGroupRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// NetworkFlow is network traffic that a network security group is evaluated against. Flows don't
+// have a source port, so allow rules that restrict source ports never allow flows, and deny rules
+// that restrict source ports always deny them.
+type NetworkFlow struct {
+	// Direction is the direction of the flow relative to the network security group.
+	// +kubebuilder:validation:Enum=Inbound;Outbound
+	Direction string `json:"direction" yaml:"direction"`
+	// Protocol is the network protocol of the flow.
+	// +kubebuilder:validation:Enum=Tcp;Udp;Icmp
+	Protocol string `json:"protocol" yaml:"protocol"`
+	// Port is the destination port of the flow. Ignored for the Icmp protocol.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty" yaml:"port,omitempty"`
+	// SourceAddressPrefix is the source of the flow. Can be an IP address, a CIDR block, or a
+	// service tag (e.g. "VirtualNetwork").
+	SourceAddressPrefix string `json:"sourceAddressPrefix" yaml:"sourceAddressPrefix"`
+	// DestinationAddressPrefix is the destination of the flow. Can be an IP address, a CIDR block,
+	// or a service tag (e.g. "Internet").
+	DestinationAddressPrefix string `json:"destinationAddressPrefix" yaml:"destinationAddressPrefix"`
+}
+
+// PolicyRule verifies that no Azure Policy assignments that apply to a scope have a "deny" or
+// "denyAction" effect whose conditions match resources that will be deployed to the scope.
+type PolicyRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// Scope is the scope resources will be deployed to (e.g.
+	// "/subscriptions/9b16dd0b-1bea-4c9a-a291-65e6f44c4745/resourceGroups/rg1"). Policy assignments
+	// at this scope and at all scopes above it are checked.
+	Scope string `json:"scope" yaml:"scope"`
+	// Resources is a list of descriptions of resources that will be deployed to the scope.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=20
+	Resources []PolicyResource `json:"resources" yaml:"resources"`
+}
+
+var _ validationrule.Interface = (*PolicyRule)(nil)
+
+// Name returns the name of the policy rule.
+func (r PolicyRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the policy rule.
+func (r *PolicyRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// PolicyResource is a description of a resource that policy conditions are evaluated against.
+// Policy conditions that depend on properties not described here can't be fully evaluated.
+type PolicyResource struct {
+	// Type is the resource type (e.g. "Microsoft.Compute/virtualMachines").
+	Type string `json:"type" yaml:"type"`
+	// Location is the location of the resource (e.g. "eastus").
+	Location string `json:"location,omitempty" yaml:"location,omitempty"`
+	// SKU is the SKU name of the resource (e.g. "Standard_D4s_v5"). It is matched against policy
+	// conditions on fields that are aliases ending in "sku.name".
+	SKU string `json:"sku,omitempty" yaml:"sku,omitempty"`
+	// Tags are the tags the resource will have.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// KeyVaultRule verifies that a Key Vault exists and that secrets, keys, and certificates in it
+// exist, are enabled, and are not expired. Optionally, it also verifies that a principal can read
+// them, using access policies or Azure RBAC depending on the vault's permission model.
+type KeyVaultRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// ResourceGroup is the resource group of the key vault.
+	ResourceGroup string `json:"resourceGroup" yaml:"resourceGroup"`
+	// Vault is the name of the key vault.
+	Vault string `json:"vault" yaml:"vault"`
+	// Secrets is a list of names of secrets that must be in the key vault.
+	// +kubebuilder:validation:MaxItems=20
+	Secrets []string `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+	// Keys is a list of names of keys that must be in the key vault.
+	// +kubebuilder:validation:MaxItems=20
+	Keys []string `json:"keys,omitempty" yaml:"keys,omitempty"`
+	// Certificates is a list of names of certificates that must be in the key vault.
+	// +kubebuilder:validation:MaxItems=20
+	Certificates []string `json:"certificates,omitempty" yaml:"certificates,omitempty"`
+	// PrincipalID is the ID of a principal (e.g. the object ID of a managed identity) that must be
+	// able to read the secrets, keys, and certificates. If not provided, access isn't checked.
+	PrincipalID string `json:"principalId,omitempty" yaml:"principalId,omitempty"`
+	// SubscriptionID is the ID of the subscription the key vault is in.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*KeyVaultRule)(nil)
+
+// Name returns the name of the key vault rule.
+func (r KeyVaultRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the key vault rule.
+func (r *KeyVaultRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// StorageAccountRule verifies that a storage account exists, that its settings match the settings
+// specified in the rule, and that it has required blob containers. Settings not specified in the
+// rule aren't checked.
+type StorageAccountRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// ResourceGroup is the resource group of the storage account.
+	ResourceGroup string `json:"resourceGroup" yaml:"resourceGroup"`
+	// StorageAccount is the name of the storage account.
+	StorageAccount string `json:"storageAccount" yaml:"storageAccount"`
+	// MinimumTLSVersion is the lowest TLS version the storage account's minimum TLS version may be.
+	// +kubebuilder:validation:Enum=TLS1_0;TLS1_1;TLS1_2;TLS1_3
+	MinimumTLSVersion string `json:"minimumTLSVersion,omitempty" yaml:"minimumTLSVersion,omitempty"`
+	// AllowBlobPublicAccess is whether the storage account must allow (true) or disallow (false)
+	// public access to blobs and containers.
+	AllowBlobPublicAccess *bool `json:"allowBlobPublicAccess,omitempty" yaml:"allowBlobPublicAccess,omitempty"`
+	// HTTPSTrafficOnly is whether the storage account must allow only HTTPS traffic (true) or must
+	// also allow HTTP traffic (false).
+	HTTPSTrafficOnly *bool `json:"httpsTrafficOnly,omitempty" yaml:"httpsTrafficOnly,omitempty"`
+	// NetworkDefaultAction is the action the storage account's network rules must take when no
+	// other network rule matches.
+	// +kubebuilder:validation:Enum=Allow;Deny
+	NetworkDefaultAction string `json:"networkDefaultAction,omitempty" yaml:"networkDefaultAction,omitempty"`
+	// HierarchicalNamespace is whether the storage account must have (true) or must not have
+	// (false) a hierarchical namespace (Azure Data Lake Storage Gen2).
+	HierarchicalNamespace *bool `json:"hierarchicalNamespace,omitempty" yaml:"hierarchicalNamespace,omitempty"`
+	// Containers is a list of names of blob containers that must be in the storage account.
+	// +kubebuilder:validation:MaxItems=20
+	Containers []string `json:"containers,omitempty" yaml:"containers,omitempty"`
+	// SubscriptionID is the ID of the subscription the storage account is in.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*StorageAccountRule)(nil)
+
+// Name returns the name of the storage account rule.
+func (r StorageAccountRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the storage account rule.
+func (r *StorageAccountRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// DNSZoneRule verifies that a public or private DNS zone exists. Optionally, it also verifies that
+// a principal can create records in the zone, and, for private DNS zones, that the zone is linked
+// to virtual networks.
+// +kubebuilder:validation:XValidation:message="virtualNetworks can only be provided for private DNS zones",rule="!has(self.virtualNetworks) || self.private"
+type DNSZoneRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// ResourceGroup is the resource group of the DNS zone.
+	ResourceGroup string `json:"resourceGroup" yaml:"resourceGroup"`
+	// Zone is the name of the DNS zone (e.g. "example.com").
+	Zone string `json:"zone" yaml:"zone"`
+	// Private is whether the DNS zone is a private DNS zone.
+	Private bool `json:"private,omitempty" yaml:"private,omitempty"`
+	// VirtualNetworks is a list of IDs of virtual networks (e.g.
+	// "/subscriptions/9b16dd0b-1bea-4c9a-a291-65e6f44c4745/resourceGroups/rg1/providers/Microsoft.Network/virtualNetworks/vnet1")
+	// the private DNS zone must be linked to.
+	// +kubebuilder:validation:MaxItems=20
+	VirtualNetworks []string `json:"virtualNetworks,omitempty" yaml:"virtualNetworks,omitempty"`
+	// PrincipalID is the ID of a principal that must be able to create records in the DNS zone. If
+	// not provided, the principal's permissions aren't checked.
+	PrincipalID string `json:"principalId,omitempty" yaml:"principalId,omitempty"`
+	// RecordTypes is a list of types of records the principal must be able to create. Defaults to
+	// A records.
+	// +kubebuilder:validation:MaxItems=10
+	// +kubebuilder:validation:items:Enum=A;AAAA;CNAME;MX;PTR;SRV;TXT
+	RecordTypes []string `json:"recordTypes,omitempty" yaml:"recordTypes,omitempty"`
+	// SubscriptionID is the ID of the subscription the DNS zone is in.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*DNSZoneRule)(nil)
+
+// Name returns the name of the DNS zone rule.
+func (r DNSZoneRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the DNS zone rule.
+func (r *DNSZoneRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// ResourceGroupRule verifies that resource groups exist, are in a location, have required tags,
+// and aren't being deleted. Alternatively, it verifies that resource groups don't exist, for
+// installs that create them.
+// +kubebuilder:validation:XValidation:message="location and tags can't be provided when absent is true",rule="!has(self.absent) || !self.absent || (!has(self.location) && !has(self.tags))"
+type ResourceGroupRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// ResourceGroups is a list of names of resource groups.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=20
+	ResourceGroups []string `json:"resourceGroups" yaml:"resourceGroups"`
+	// Location is the location (e.g. "eastus") the resource groups must be in.
+	Location string `json:"location,omitempty" yaml:"location,omitempty"`
+	// Tags is a list of tags the resource groups must have.
+	// +kubebuilder:validation:MaxItems=20
+	Tags []TagRequirement `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Absent is whether the resource groups must not exist.
+	Absent bool `json:"absent,omitempty" yaml:"absent,omitempty"`
+	// SubscriptionID is the ID of the subscription the resource groups are in.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*ResourceGroupRule)(nil)
+
+// Name returns the name of the resource group rule.
+func (r ResourceGroupRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the resource group rule.
+func (r *ResourceGroupRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// TagRequirement is a tag a resource must have. If none of a value, allowed values, or a pattern
+// is provided, the tag can have any value.
+// +kubebuilder:validation:XValidation:message="At most one of value, allowedValues, or pattern can be provided",rule="[has(self.value), has(self.allowedValues), has(self.pattern)].filter(x, x).size() <= 1"
+type TagRequirement struct {
+	// Key is the name of the tag. Tag names are compared case-insensitively.
+	Key string `json:"key" yaml:"key"`
+	// Value is the value the tag must have.
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+	// AllowedValues is a list of values the tag's value must be one of.
+	// +kubebuilder:validation:MaxItems=100
+	AllowedValues []string `json:"allowedValues,omitempty" yaml:"allowedValues,omitempty"`
+	// Pattern is a regular expression the tag's value must match (e.g. "^team-.+$"). Uses Go's
+	// regular expression syntax.
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+}
+
+// ManagedIdentityRule verifies that a user-assigned managed identity exists and that it has
+// federated identity credentials matching expected issuers, subjects, and audiences.
+type ManagedIdentityRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// ResourceGroup is the resource group of the managed identity.
+	ResourceGroup string `json:"resourceGroup" yaml:"resourceGroup"`
+	// Identity is the name of the user-assigned managed identity.
+	Identity string `json:"identity" yaml:"identity"`
+	// FederatedCredentials is a list of federated identity credentials the managed identity must
+	// have.
+	// +kubebuilder:validation:MaxItems=20
+	FederatedCredentials []FederatedCredential `json:"federatedCredentials,omitempty" yaml:"federatedCredentials,omitempty"`
+	// SubscriptionID is the ID of the subscription the managed identity is in.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*ManagedIdentityRule)(nil)
+
+// Name returns the name of the managed identity rule.
+func (r ManagedIdentityRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the managed identity rule.
+func (r *ManagedIdentityRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// FederatedCredential is a federated identity credential a managed identity must have. A federated
+// identity credential of the managed identity matches if its issuer and subject are the same and
+// its audiences include the audience.
+type FederatedCredential struct {
+	// Issuer is the URL of the issuer of the tokens the managed identity trusts (e.g. the OIDC
+	// issuer URL of a Kubernetes cluster). Compared exactly, including any trailing slash.
+	Issuer string `json:"issuer" yaml:"issuer"`
+	// Subject is the identifier of the external identity (e.g.
+	// "system:serviceaccount:namespace:serviceaccount").
+	Subject string `json:"subject" yaml:"subject"`
+	// Audience is the audience that must appear in the tokens. Defaults to
+	// "api://AzureADTokenExchange".
+	Audience string `json:"audience,omitempty" yaml:"audience,omitempty"`
+}
+
+// ApplicationCredentialRule verifies that the client secrets and certificates of a Microsoft Entra
+// ID application (app registration) or service principal don't expire within a window. Credentials
+// are read through Microsoft Graph.
+type ApplicationCredentialRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// ClientID is the application (client) ID of the application. If not specified, the client ID
+	// the plugin authenticates with is used, so that the rule checks the plugin's own credentials.
+	ClientID string `json:"clientId,omitempty" yaml:"clientId,omitempty"`
+	// ServicePrincipal is whether to check the credentials of the application's service principal
+	// (enterprise application) instead of those of its app registration. Use this for applications
+	// registered in another tenant.
+	ServicePrincipal bool `json:"servicePrincipal,omitempty" yaml:"servicePrincipal,omitempty"`
+	// FailWithinDays is the number of days before a credential expires within which validation
+	// fails. Defaults to 7.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=7
+	FailWithinDays *int `json:"failWithinDays,omitempty" yaml:"failWithinDays,omitempty"`
+	// WarnWithinDays is the number of days before a credential expires within which a warning is
+	// added to the validation result's details. Validation still passes. Defaults to 30.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=30
+	WarnWithinDays *int `json:"warnWithinDays,omitempty" yaml:"warnWithinDays,omitempty"`
+}
+
+var _ validationrule.Interface = (*ApplicationCredentialRule)(nil)
+
+// Name returns the name of the application credential rule.
+func (r ApplicationCredentialRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the application credential rule.
+func (r *ApplicationCredentialRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// AKSClusterRule verifies that an existing AKS cluster runs a Kubernetes version AKS still supports
+// in its region, that its provisioning state is healthy, and that it has required node pools and
+// features.
+type AKSClusterRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// ResourceGroup is the resource group of the AKS cluster.
+	ResourceGroup string `json:"resourceGroup" yaml:"resourceGroup"`
+	// Cluster is the name of the AKS cluster.
+	Cluster string `json:"cluster" yaml:"cluster"`
+	// NodePools is a list of node pools the AKS cluster must have.
+	// +kubebuilder:validation:MaxItems=20
+	NodePools []AKSNodePool `json:"nodePools,omitempty" yaml:"nodePools,omitempty"`
+	// OIDCIssuer is whether the AKS cluster must have the OIDC issuer enabled.
+	OIDCIssuer bool `json:"oidcIssuer,omitempty" yaml:"oidcIssuer,omitempty"`
+	// WorkloadIdentity is whether the AKS cluster must have workload identity enabled.
+	WorkloadIdentity bool `json:"workloadIdentity,omitempty" yaml:"workloadIdentity,omitempty"`
+	// SubscriptionID is the ID of the subscription the AKS cluster is in.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*AKSClusterRule)(nil)
+
+// Name returns the name of the AKS cluster rule.
+func (r AKSClusterRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the AKS cluster rule.
+func (r *AKSClusterRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// AKSNodePool is a node pool an AKS cluster must have. Only the properties specified are checked.
+type AKSNodePool struct {
+	// Name is the name of the node pool.
+	Name string `json:"name" yaml:"name"`
+	// VMSize is the VM size the node pool must use (e.g. "Standard_D4s_v5").
+	VMSize string `json:"vmSize,omitempty" yaml:"vmSize,omitempty"`
+	// MinCount is the minimum number of nodes the node pool must currently have.
+	// +kubebuilder:validation:Minimum=0
+	MinCount *int32 `json:"minCount,omitempty" yaml:"minCount,omitempty"`
+	// OSType is the OS type the node pool must use.
+	// +kubebuilder:validation:Enum=Linux;Windows
+	OSType string `json:"osType,omitempty" yaml:"osType,omitempty"`
+	// OSSKU is the OS SKU the node pool must use (e.g. "Ubuntu" or "AzureLinux").
+	OSSKU string `json:"osSKU,omitempty" yaml:"osSKU,omitempty"`
+}
+
+// SubscriptionRule verifies that a subscription is enabled, that it's optionally in an expected
+// tenant and management group, and that it isn't a spending-limited offer (e.g. Free Trial).
+type SubscriptionRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// TenantID is the ID of the Microsoft Entra ID tenant the subscription must be in.
+	TenantID string `json:"tenantId,omitempty" yaml:"tenantId,omitempty"`
+	// ManagementGroup is the ID (name) of a management group the subscription must be in, directly
+	// or through descendant management groups.
+	ManagementGroup string `json:"managementGroup,omitempty" yaml:"managementGroup,omitempty"`
+	// AllowSpendingLimit is whether the subscription may have a spending limit. Resources in
+	// subscriptions with spending limits are disabled once the spending limit is reached.
+	AllowSpendingLimit bool `json:"allowSpendingLimit,omitempty" yaml:"allowSpendingLimit,omitempty"`
+	// SubscriptionID is the ID of the subscription.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*SubscriptionRule)(nil)
+
+// Name returns the name of the subscription rule.
+func (r SubscriptionRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the subscription rule.
+func (r *SubscriptionRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// LockRule verifies that no management locks at a scope, at scopes above it, or on resources under
+// it block operations that will be performed under the scope. Locks apply regardless of role
+// assignments, so they can block operations that RBAC rules show are permitted.
+type LockRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// Scope is the scope operations will be performed under (e.g.
+	// "/subscriptions/9b16dd0b-1bea-4c9a-a291-65e6f44c4745/resourceGroups/rg1"). Locks at this
+	// scope, at all scopes above it, and on resources under it are checked.
+	Scope string `json:"scope" yaml:"scope"`
+	// Operations are the kinds of operations that will be performed under the scope. "Write"
+	// operations are blocked by ReadOnly locks and "Delete" operations are blocked by both
+	// ReadOnly and CanNotDelete locks.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:Enum=Write;Delete
+	Operations []string `json:"operations" yaml:"operations"`
+}
+
+var _ validationrule.Interface = (*LockRule)(nil)
+
+// Name returns the name of the lock rule.
+func (r LockRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the lock rule.
+func (r *LockRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// RoleDefinitionRule verifies that a custom role definition hasn't drifted from an expected
+// definition. Permissions and assignable scopes added to or missing from the role definition are
+// reported.
+// +kubebuilder:validation:XValidation:message="Exactly one of roleName or roleDefinitionId must be provided",rule="has(self.roleName) != has(self.roleDefinitionId)"
+type RoleDefinitionRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// Scope is a scope the role definition is available at, usually the first of its assignable
+	// scopes (e.g. "/subscriptions/9b16dd0b-1bea-4c9a-a291-65e6f44c4745").
+	Scope string `json:"scope" yaml:"scope"`
+	// RoleName is the name of the role definition (e.g. "Validator Plugin"). Use this for roles
+	// created with a different ID in each tenant.
+	RoleName string `json:"roleName,omitempty" yaml:"roleName,omitempty"`
+	// RoleDefinitionID is the ID of the role definition, which is a GUID. Its fully-qualified ID
+	// is formed with the scope.
+	RoleDefinitionID string `json:"roleDefinitionId,omitempty" yaml:"roleDefinitionId,omitempty"`
+	// Actions is the expected list of Actions of the role definition. Can contain wildcards.
+	// +kubebuilder:validation:MaxItems=1000
+	Actions []ActionStr `json:"actions,omitempty" yaml:"actions,omitempty"`
+	// NotActions is the expected list of NotActions of the role definition. Can contain
+	// wildcards.
+	// +kubebuilder:validation:MaxItems=1000
+	NotActions []ActionStr `json:"notActions,omitempty" yaml:"notActions,omitempty"`
+	// DataActions is the expected list of DataActions of the role definition. Can contain
+	// wildcards.
+	// +kubebuilder:validation:MaxItems=1000
+	DataActions []ActionStr `json:"dataActions,omitempty" yaml:"dataActions,omitempty"`
+	// NotDataActions is the expected list of NotDataActions of the role definition. Can contain
+	// wildcards.
+	// +kubebuilder:validation:MaxItems=1000
+	NotDataActions []ActionStr `json:"notDataActions,omitempty" yaml:"notDataActions,omitempty"`
+	// AssignableScopes is the expected list of assignable scopes of the role definition. If not
+	// specified, assignable scopes aren't checked.
+	// +kubebuilder:validation:MaxItems=100
+	AssignableScopes []string `json:"assignableScopes,omitempty" yaml:"assignableScopes,omitempty"`
+}
+
+var _ validationrule.Interface = (*RoleDefinitionRule)(nil)
+
+// Name returns the name of the role definition rule.
+func (r RoleDefinitionRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the role definition rule.
+func (r *RoleDefinitionRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// TagRule verifies that resources in a subscription, or in a resource group of it, have required
+// tags. Resources can be limited to some resource types.
+type TagRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// ResourceGroup is the resource group whose resources are checked. If not specified, all
+	// resources in the subscription are checked.
+	ResourceGroup string `json:"resourceGroup,omitempty" yaml:"resourceGroup,omitempty"`
+	// ResourceTypes is a list of resource types (e.g. "Microsoft.Compute/virtualMachines") to
+	// check resources of. If not specified, resources of all types are checked.
+	// +kubebuilder:validation:MaxItems=20
+	ResourceTypes []string `json:"resourceTypes,omitempty" yaml:"resourceTypes,omitempty"`
+	// Tags is a list of tags each resource must have.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=20
+	Tags []TagRequirement `json:"tags" yaml:"tags"`
+	// SubscriptionID is the ID of the subscription the resources are in.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*TagRule)(nil)
+
+// Name returns the name of the tag rule.
+func (r TagRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the tag rule.
+func (r *TagRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// ResourceGraphQueryRule runs a KQL query against Azure Resource Graph over a set of subscriptions
+// or a management group and verifies its result. The number of rows returned can be checked, and
+// every row can be required to match expectations for its fields.
+// +kubebuilder:validation:XValidation:message="Exactly one of subscriptionIDs or managementGroup must be provided",rule="has(self.subscriptionIDs) != has(self.managementGroup)"
+// +kubebuilder:validation:XValidation:message="At least one of rowCount or rowExpectations must be provided",rule="has(self.rowCount) || has(self.rowExpectations)"
+type ResourceGraphQueryRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// Query is the KQL query to run (e.g. "Resources | where type =~
+	// 'microsoft.network/publicipaddresses' and sku.name =~ 'Basic' | project id, name").
+	// +kubebuilder:validation:MinLength=1
+	Query string `json:"query" yaml:"query"`
+	// SubscriptionIDs is a list of IDs of the subscriptions to run the query over.
+	// +kubebuilder:validation:MaxItems=100
+	SubscriptionIDs []string `json:"subscriptionIDs,omitempty" yaml:"subscriptionIDs,omitempty"`
+	// ManagementGroup is the ID of the management group to run the query over.
+	ManagementGroup string `json:"managementGroup,omitempty" yaml:"managementGroup,omitempty"`
+	// RowCount is the number of rows the query must return.
+	RowCount *ResourceGraphRowCount `json:"rowCount,omitempty" yaml:"rowCount,omitempty"`
+	// RowExpectations is a list of expectations every row returned by the query must match.
+	// +kubebuilder:validation:MaxItems=20
+	RowExpectations []ResourceGraphRowExpectation `json:"rowExpectations,omitempty" yaml:"rowExpectations,omitempty"`
+}
+
+var _ validationrule.Interface = (*ResourceGraphQueryRule)(nil)
+
+// Name returns the name of the Resource Graph query rule.
+func (r ResourceGraphQueryRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the Resource Graph query rule.
+func (r *ResourceGraphQueryRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// ResourceGraphRowCount is the number of rows an Azure Resource Graph query must return. Either an
+// exact number, or a minimum, a maximum, or both can be provided.
+// +kubebuilder:validation:XValidation:message="equals can't be provided with min or max",rule="!has(self.equals) || (!has(self.min) && !has(self.max))"
+// +kubebuilder:validation:XValidation:message="At least one of equals, min, or max must be provided",rule="has(self.equals) || has(self.min) || has(self.max)"
+type ResourceGraphRowCount struct {
+	// Equals is the exact number of rows the query must return.
+	// +kubebuilder:validation:Minimum=0
+	Equals *int32 `json:"equals,omitempty" yaml:"equals,omitempty"`
+	// Min is the minimum number of rows the query must return.
+	// +kubebuilder:validation:Minimum=0
+	Min *int32 `json:"min,omitempty" yaml:"min,omitempty"`
+	// Max is the maximum number of rows the query must return. Use 0 to require that no resources
+	// match the query.
+	// +kubebuilder:validation:Minimum=0
+	Max *int32 `json:"max,omitempty" yaml:"max,omitempty"`
+}
+
+// ResourceGraphRowExpectation is an expectation for a field of each row returned by an Azure
+// Resource Graph query. If none of a value, allowed values, or a pattern is provided, the field
+// just has to be present and not null.
+// +kubebuilder:validation:XValidation:message="At most one of value, allowedValues, or pattern can be provided",rule="[has(self.value), has(self.allowedValues), has(self.pattern)].filter(x, x).size() <= 1"
+type ResourceGraphRowExpectation struct {
+	// Field is the name of the column to check. Fields of columns holding objects can be selected
+	// with dots (e.g. "sku.name"). Field names are compared case-sensitively.
+	// +kubebuilder:validation:MinLength=1
+	Field string `json:"field" yaml:"field"`
+	// Value is the value the field must have. Values that aren't strings are compared using their
+	// JSON representation (e.g. "true" or "1024").
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+	// AllowedValues is a list of values the field's value must be one of.
+	// +kubebuilder:validation:MaxItems=100
+	AllowedValues []string `json:"allowedValues,omitempty" yaml:"allowedValues,omitempty"`
+	// Pattern is a regular expression the field's value must match. Uses Go's regular expression
+	// syntax.
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+}
+
+// GroupMembershipRule verifies that a principal is a transitive member of Microsoft Entra ID
+// groups, and optionally that it isn't a transitive member of others. Principals are members of
+// groups they're members of directly or through nested groups.
+type GroupMembershipRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// PrincipalID is the object ID of the principal, which can be a user, a service principal, or
+	// a managed identity. For service principals and managed identities, this is the object ID of
+	// the service principal, not the client ID of its application.
+	// +kubebuilder:validation:MinLength=1
+	PrincipalID string `json:"principalId" yaml:"principalId"`
+	// MemberOf is a list of object IDs of groups the principal must be a transitive member of.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=50
+	MemberOf []string `json:"memberOf" yaml:"memberOf"`
+	// NotMemberOf is a list of object IDs of groups the principal must not be a transitive member
+	// of.
+	// +kubebuilder:validation:MaxItems=50
+	NotMemberOf []string `json:"notMemberOf,omitempty" yaml:"notMemberOf,omitempty"`
+}
+
+var _ validationrule.Interface = (*GroupMembershipRule)(nil)
+
+// Name returns the name of the group membership rule.
+func (r GroupMembershipRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the group membership rule.
+func (r *GroupMembershipRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// FeatureRegistrationRule verifies that preview features are registered in a subscription and that
+// the resource providers owning them were re-registered afterwards, which propagates feature
+// registrations to the resource providers.
+type FeatureRegistrationRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// Features is a list of features that must be registered, each given as the namespace of the
+	// resource provider owning it and the feature's name separated by a slash (e.g.
+	// "Microsoft.Compute/EncryptionAtHost").
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=20
+	// +kubebuilder:validation:items:Pattern=`^[^/]+/[^/]+$`
+	Features []string `json:"features" yaml:"features"`
+	// SubscriptionID is the ID of the subscription the features must be registered in.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*FeatureRegistrationRule)(nil)
+
+// Name returns the name of the feature registration rule.
+func (r FeatureRegistrationRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the feature registration rule.
+func (r *FeatureRegistrationRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// CapacityReservationRule verifies that a capacity reservation group exists and has capacity
+// reservations for VM sizes and zones with enough reserved instances still unallocated.
+type CapacityReservationRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// ResourceGroup is the resource group the capacity reservation group is in.
+	ResourceGroup string `json:"resourceGroup" yaml:"resourceGroup"`
+	// CapacityReservationGroup is the name of the capacity reservation group.
+	CapacityReservationGroup string `json:"capacityReservationGroup" yaml:"capacityReservationGroup"`
+	// Reservations is a list of capacity reservations the capacity reservation group must have.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=20
+	Reservations []CapacityReservationRequirement `json:"reservations" yaml:"reservations"`
+	// SubscriptionID is the ID of the subscription the capacity reservation group is in.
+	SubscriptionID string `json:"subscriptionID" yaml:"subscriptionID"`
+}
+
+var _ validationrule.Interface = (*CapacityReservationRule)(nil)
+
+// Name returns the name of the capacity reservation rule.
+func (r CapacityReservationRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the capacity reservation rule.
+func (r *CapacityReservationRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// CapacityReservationRequirement is a capacity reservation for a VM size that a capacity
+// reservation group must have, with an expected buffer (reserved instances minus allocated
+// instances).
+type CapacityReservationRequirement struct {
+	// VMSize is the VM size reserved (e.g. "Standard_D4s_v5").
+	VMSize string `json:"vmSize" yaml:"vmSize"`
+	// Zones is a list of availability zones (e.g. "1") there must be a capacity reservation for the
+	// VM size in, each with the buffer. If not specified, there must be a regional capacity
+	// reservation for the VM size.
+	// +kubebuilder:validation:MaxItems=3
+	Zones []string `json:"zones,omitempty" yaml:"zones,omitempty"`
+	// Buffer is the number of reserved instances that must still be unallocated (not used by VMs)
+	// for validation to succeed. For example, if 5 instances were reserved, 3 were allocated, and
+	// the buffer was set to 2, validation would succeed. However, if the buffer was set to 3 instead
+	// of 2, validation would fail.
+	// +kubebuilder:validation:Minimum=0
+	Buffer int32 `json:"buffer" yaml:"buffer"`
+}
+
+// BudgetRule verifies that a budget exists at a scope with notifications configured, and
+// optionally that the actual or forecasted spend for the budget's current period is below a
+// percentage of the budget's amount.
+type BudgetRule struct {
+	validationrule.ManuallyNamed `json:",inline" yaml:",omitempty"`
+
+	// RuleName is a unique identifier for the rule in the validator. Used to ensure conditions do
+	// not overwrite each other.
+	// +kubebuilder:validation:MaxLength=200
+	RuleName string `json:"name" yaml:"name"`
+	// Scope is the scope the budget is at (e.g. "/subscriptions/{subscriptionId}" or
+	// "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroup}").
+	Scope string `json:"scope" yaml:"scope"`
+	// BudgetName is the name of the budget.
+	BudgetName string `json:"budgetName" yaml:"budgetName"`
+	// Notifications is a list of notifications the budget must have enabled. If not specified, the
+	// budget must have at least one enabled notification.
+	// +kubebuilder:validation:MaxItems=10
+	Notifications []BudgetNotification `json:"notifications,omitempty" yaml:"notifications,omitempty"`
+	// MaxActualSpendPercent is the percentage of the budget's amount the actual spend for the
+	// budget's current period (e.g. month-to-date spend for monthly budgets) must be below.
+	// +kubebuilder:validation:Minimum=0
+	MaxActualSpendPercent *int32 `json:"maxActualSpendPercent,omitempty" yaml:"maxActualSpendPercent,omitempty"`
+	// MaxForecastedSpendPercent is the percentage of the budget's amount the forecasted spend for
+	// the budget's current period must be below.
+	// +kubebuilder:validation:Minimum=0
+	MaxForecastedSpendPercent *int32 `json:"maxForecastedSpendPercent,omitempty" yaml:"maxForecastedSpendPercent,omitempty"`
+}
+
+var _ validationrule.Interface = (*BudgetRule)(nil)
+
+// Name returns the name of the budget rule.
+func (r BudgetRule) Name() string {
+	return r.RuleName
+}
+
+// SetName sets the name of the budget rule.
+func (r *BudgetRule) SetName(name string) {
+	r.RuleName = name
+}
+
+// BudgetNotification is a notification a budget must have enabled.
+type BudgetNotification struct {
+	// Threshold is the percentage of the budget's amount the notification must be sent at.
+	// +kubebuilder:validation:Minimum=1
+	Threshold int32 `json:"threshold" yaml:"threshold"`
+	// ThresholdType is whether the notification is for actual or forecasted spend. Defaults to
+	// Actual.
+	// +kubebuilder:validation:Enum=Actual;Forecasted
+	// +kubebuilder:default=Actual
+	ThresholdType string `json:"thresholdType,omitempty" yaml:"thresholdType,omitempty"`
+}
+
 // AzureAuth defines authentication configuration for an AzureValidator.
 type AzureAuth struct {
 	// If true, the AzureValidator will use the Azure SDK's default credential chain to authenticate.
@@ -24,6 +24,75 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AKSClusterRule) DeepCopyInto(out *AKSClusterRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]AKSNodePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AKSClusterRule.
+func (in *AKSClusterRule) DeepCopy() *AKSClusterRule {
+	if in == nil {
+		return nil
+	}
+	out := new(AKSClusterRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AKSNodePool) DeepCopyInto(out *AKSNodePool) {
+	*out = *in
+	if in.MinCount != nil {
+		in, out := &in.MinCount, &out.MinCount
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AKSNodePool.
+func (in *AKSNodePool) DeepCopy() *AKSNodePool {
+	if in == nil {
+		return nil
+	}
+	out := new(AKSNodePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationCredentialRule) DeepCopyInto(out *ApplicationCredentialRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.FailWithinDays != nil {
+		in, out := &in.FailWithinDays, &out.FailWithinDays
+		*out = new(int)
+		**out = **in
+	}
+	if in.WarnWithinDays != nil {
+		in, out := &in.WarnWithinDays, &out.WarnWithinDays
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationCredentialRule.
+func (in *ApplicationCredentialRule) DeepCopy() *ApplicationCredentialRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationCredentialRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureAuth) DeepCopyInto(out *AzureAuth) {
 	*out = *in
@@ -127,6 +196,165 @@ func (in *AzureValidatorSpec) DeepCopyInto(out *AzureValidatorSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ResourceProviderRules != nil {
+		in, out := &in.ResourceProviderRules, &out.ResourceProviderRules
+		*out = make([]ResourceProviderRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VMSizeRules != nil {
+		in, out := &in.VMSizeRules, &out.VMSizeRules
+		*out = make([]VMSizeRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MarketplaceImageRules != nil {
+		in, out := &in.MarketplaceImageRules, &out.MarketplaceImageRules
+		*out = make([]MarketplaceImageRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GalleryImageRules != nil {
+		in, out := &in.GalleryImageRules, &out.GalleryImageRules
+		*out = make([]GalleryImageRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SubnetRules != nil {
+		in, out := &in.SubnetRules, &out.SubnetRules
+		*out = make([]SubnetRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NetworkSecurityGroupRules != nil {
+		in, out := &in.NetworkSecurityGroupRules, &out.NetworkSecurityGroupRules
+		*out = make([]NetworkSecurityGroupRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PolicyRules != nil {
+		in, out := &in.PolicyRules, &out.PolicyRules
+		*out = make([]PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.KeyVaultRules != nil {
+		in, out := &in.KeyVaultRules, &out.KeyVaultRules
+		*out = make([]KeyVaultRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StorageAccountRules != nil {
+		in, out := &in.StorageAccountRules, &out.StorageAccountRules
+		*out = make([]StorageAccountRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DNSZoneRules != nil {
+		in, out := &in.DNSZoneRules, &out.DNSZoneRules
+		*out = make([]DNSZoneRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceGroupRules != nil {
+		in, out := &in.ResourceGroupRules, &out.ResourceGroupRules
+		*out = make([]ResourceGroupRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ManagedIdentityRules != nil {
+		in, out := &in.ManagedIdentityRules, &out.ManagedIdentityRules
+		*out = make([]ManagedIdentityRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ApplicationCredentialRules != nil {
+		in, out := &in.ApplicationCredentialRules, &out.ApplicationCredentialRules
+		*out = make([]ApplicationCredentialRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AKSClusterRules != nil {
+		in, out := &in.AKSClusterRules, &out.AKSClusterRules
+		*out = make([]AKSClusterRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SubscriptionRules != nil {
+		in, out := &in.SubscriptionRules, &out.SubscriptionRules
+		*out = make([]SubscriptionRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.LockRules != nil {
+		in, out := &in.LockRules, &out.LockRules
+		*out = make([]LockRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RoleDefinitionRules != nil {
+		in, out := &in.RoleDefinitionRules, &out.RoleDefinitionRules
+		*out = make([]RoleDefinitionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TagRules != nil {
+		in, out := &in.TagRules, &out.TagRules
+		*out = make([]TagRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceGraphQueryRules != nil {
+		in, out := &in.ResourceGraphQueryRules, &out.ResourceGraphQueryRules
+		*out = make([]ResourceGraphQueryRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GroupMembershipRules != nil {
+		in, out := &in.GroupMembershipRules, &out.GroupMembershipRules
+		*out = make([]GroupMembershipRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FeatureRegistrationRules != nil {
+		in, out := &in.FeatureRegistrationRules, &out.FeatureRegistrationRules
+		*out = make([]FeatureRegistrationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CapacityReservationRules != nil {
+		in, out := &in.CapacityReservationRules, &out.CapacityReservationRules
+		*out = make([]CapacityReservationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BudgetRules != nil {
+		in, out := &in.BudgetRules, &out.BudgetRules
+		*out = make([]BudgetRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.Auth.DeepCopyInto(&out.Auth)
 }
 
@@ -156,159 +384,937 @@ func (in *AzureValidatorStatus) DeepCopy() *AzureValidatorStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CommunityGallery) DeepCopyInto(out *CommunityGallery) {
+func (in *BudgetNotification) DeepCopyInto(out *BudgetNotification) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommunityGallery.
-func (in *CommunityGallery) DeepCopy() *CommunityGallery {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BudgetNotification.
+func (in *BudgetNotification) DeepCopy() *BudgetNotification {
 	if in == nil {
 		return nil
 	}
-	out := new(CommunityGallery)
+	out := new(BudgetNotification)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CommunityGalleryImageRule) DeepCopyInto(out *CommunityGalleryImageRule) {
+func (in *BudgetRule) DeepCopyInto(out *BudgetRule) {
 	*out = *in
 	out.ManuallyNamed = in.ManuallyNamed
-	out.Gallery = in.Gallery
-	if in.Images != nil {
-		in, out := &in.Images, &out.Images
-		*out = make([]string, len(*in))
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]BudgetNotification, len(*in))
 		copy(*out, *in)
 	}
+	if in.MaxActualSpendPercent != nil {
+		in, out := &in.MaxActualSpendPercent, &out.MaxActualSpendPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxForecastedSpendPercent != nil {
+		in, out := &in.MaxForecastedSpendPercent, &out.MaxForecastedSpendPercent
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommunityGalleryImageRule.
-func (in *CommunityGalleryImageRule) DeepCopy() *CommunityGalleryImageRule {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BudgetRule.
+func (in *BudgetRule) DeepCopy() *BudgetRule {
 	if in == nil {
 		return nil
 	}
-	out := new(CommunityGalleryImageRule)
+	out := new(BudgetRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PermissionSet) DeepCopyInto(out *PermissionSet) {
+func (in *CapacityReservationRequirement) DeepCopyInto(out *CapacityReservationRequirement) {
 	*out = *in
-	if in.Actions != nil {
-		in, out := &in.Actions, &out.Actions
-		*out = make([]ActionStr, len(*in))
-		copy(*out, *in)
-	}
-	if in.DataActions != nil {
-		in, out := &in.DataActions, &out.DataActions
-		*out = make([]ActionStr, len(*in))
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PermissionSet.
-func (in *PermissionSet) DeepCopy() *PermissionSet {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityReservationRequirement.
+func (in *CapacityReservationRequirement) DeepCopy() *CapacityReservationRequirement {
 	if in == nil {
 		return nil
 	}
-	out := new(PermissionSet)
+	out := new(CapacityReservationRequirement)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuotaRule) DeepCopyInto(out *QuotaRule) {
+func (in *CapacityReservationRule) DeepCopyInto(out *CapacityReservationRule) {
 	*out = *in
 	out.ManuallyNamed = in.ManuallyNamed
-	if in.ResourceSets != nil {
-		in, out := &in.ResourceSets, &out.ResourceSets
-		*out = make([]ResourceSet, len(*in))
+	if in.Reservations != nil {
+		in, out := &in.Reservations, &out.Reservations
+		*out = make([]CapacityReservationRequirement, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaRule.
-func (in *QuotaRule) DeepCopy() *QuotaRule {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityReservationRule.
+func (in *CapacityReservationRule) DeepCopy() *CapacityReservationRule {
 	if in == nil {
 		return nil
 	}
-	out := new(QuotaRule)
+	out := new(CapacityReservationRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RBACRule) DeepCopyInto(out *RBACRule) {
+func (in *CommunityGallery) DeepCopyInto(out *CommunityGallery) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommunityGallery.
+func (in *CommunityGallery) DeepCopy() *CommunityGallery {
+	if in == nil {
+		return nil
+	}
+	out := new(CommunityGallery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommunityGalleryImage) DeepCopyInto(out *CommunityGalleryImage) {
+	*out = *in
+	if in.Regions != nil {
+		in, out := &in.Regions, &out.Regions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommunityGalleryImage.
+func (in *CommunityGalleryImage) DeepCopy() *CommunityGalleryImage {
+	if in == nil {
+		return nil
+	}
+	out := new(CommunityGalleryImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommunityGalleryImageRule) DeepCopyInto(out *CommunityGalleryImageRule) {
 	*out = *in
 	out.ManuallyNamed = in.ManuallyNamed
-	if in.Permissions != nil {
-		in, out := &in.Permissions, &out.Permissions
-		*out = make([]PermissionSet, len(*in))
+	out.Gallery = in.Gallery
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImageRequirements != nil {
+		in, out := &in.ImageRequirements, &out.ImageRequirements
+		*out = make([]CommunityGalleryImage, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACRule.
-func (in *RBACRule) DeepCopy() *RBACRule {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommunityGalleryImageRule.
+func (in *CommunityGalleryImageRule) DeepCopy() *CommunityGalleryImageRule {
 	if in == nil {
 		return nil
 	}
-	out := new(RBACRule)
+	out := new(CommunityGalleryImageRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Resource) DeepCopyInto(out *Resource) {
+func (in *DNSZoneRule) DeepCopyInto(out *DNSZoneRule) {
 	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.VirtualNetworks != nil {
+		in, out := &in.VirtualNetworks, &out.VirtualNetworks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RecordTypes != nil {
+		in, out := &in.RecordTypes, &out.RecordTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Resource.
-func (in *Resource) DeepCopy() *Resource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSZoneRule.
+func (in *DNSZoneRule) DeepCopy() *DNSZoneRule {
 	if in == nil {
 		return nil
 	}
-	out := new(Resource)
+	out := new(DNSZoneRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceSet) DeepCopyInto(out *ResourceSet) {
+func (in *FeatureRegistrationRule) DeepCopyInto(out *FeatureRegistrationRule) {
 	*out = *in
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = make([]Resource, len(*in))
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSet.
-func (in *ResourceSet) DeepCopy() *ResourceSet {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeatureRegistrationRule.
+func (in *FeatureRegistrationRule) DeepCopy() *FeatureRegistrationRule {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceSet)
+	out := new(FeatureRegistrationRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServicePrincipalCredentials) DeepCopyInto(out *ServicePrincipalCredentials) {
+func (in *FederatedCredential) DeepCopyInto(out *FederatedCredential) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServicePrincipalCredentials.
-func (in *ServicePrincipalCredentials) DeepCopy() *ServicePrincipalCredentials {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedCredential.
+func (in *FederatedCredential) DeepCopy() *FederatedCredential {
 	if in == nil {
 		return nil
 	}
-	out := new(ServicePrincipalCredentials)
+	out := new(FederatedCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Gallery) DeepCopyInto(out *Gallery) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Gallery.
+func (in *Gallery) DeepCopy() *Gallery {
+	if in == nil {
+		return nil
+	}
+	out := new(Gallery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GalleryImage) DeepCopyInto(out *GalleryImage) {
+	*out = *in
+	if in.Regions != nil {
+		in, out := &in.Regions, &out.Regions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GalleryImage.
+func (in *GalleryImage) DeepCopy() *GalleryImage {
+	if in == nil {
+		return nil
+	}
+	out := new(GalleryImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GalleryImageRule) DeepCopyInto(out *GalleryImageRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	out.Gallery = in.Gallery
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]GalleryImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GalleryImageRule.
+func (in *GalleryImageRule) DeepCopy() *GalleryImageRule {
+	if in == nil {
+		return nil
+	}
+	out := new(GalleryImageRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GroupMembershipRule) DeepCopyInto(out *GroupMembershipRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.MemberOf != nil {
+		in, out := &in.MemberOf, &out.MemberOf
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NotMemberOf != nil {
+		in, out := &in.NotMemberOf, &out.NotMemberOf
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupMembershipRule.
+func (in *GroupMembershipRule) DeepCopy() *GroupMembershipRule {
+	if in == nil {
+		return nil
+	}
+	out := new(GroupMembershipRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyVaultRule) DeepCopyInto(out *KeyVaultRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyVaultRule.
+func (in *KeyVaultRule) DeepCopy() *KeyVaultRule {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyVaultRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LockRule) DeepCopyInto(out *LockRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LockRule.
+func (in *LockRule) DeepCopy() *LockRule {
+	if in == nil {
+		return nil
+	}
+	out := new(LockRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedIdentityRule) DeepCopyInto(out *ManagedIdentityRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.FederatedCredentials != nil {
+		in, out := &in.FederatedCredentials, &out.FederatedCredentials
+		*out = make([]FederatedCredential, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedIdentityRule.
+func (in *ManagedIdentityRule) DeepCopy() *ManagedIdentityRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedIdentityRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MarketplaceImage) DeepCopyInto(out *MarketplaceImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MarketplaceImage.
+func (in *MarketplaceImage) DeepCopy() *MarketplaceImage {
+	if in == nil {
+		return nil
+	}
+	out := new(MarketplaceImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MarketplaceImageRule) DeepCopyInto(out *MarketplaceImageRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]MarketplaceImage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MarketplaceImageRule.
+func (in *MarketplaceImageRule) DeepCopy() *MarketplaceImageRule {
+	if in == nil {
+		return nil
+	}
+	out := new(MarketplaceImageRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkFlow) DeepCopyInto(out *NetworkFlow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkFlow.
+func (in *NetworkFlow) DeepCopy() *NetworkFlow {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkFlow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkSecurityGroupRule) DeepCopyInto(out *NetworkSecurityGroupRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.Flows != nil {
+		in, out := &in.Flows, &out.Flows
+		*out = make([]NetworkFlow, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkSecurityGroupRule.
+func (in *NetworkSecurityGroupRule) DeepCopy() *NetworkSecurityGroupRule {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkSecurityGroupRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PermissionSet) DeepCopyInto(out *PermissionSet) {
+	*out = *in
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]ActionStr, len(*in))
+		copy(*out, *in)
+	}
+	if in.DataActions != nil {
+		in, out := &in.DataActions, &out.DataActions
+		*out = make([]ActionStr, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PermissionSet.
+func (in *PermissionSet) DeepCopy() *PermissionSet {
+	if in == nil {
+		return nil
+	}
+	out := new(PermissionSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyResource) DeepCopyInto(out *PolicyResource) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyResource.
+func (in *PolicyResource) DeepCopy() *PolicyResource {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyRule) DeepCopyInto(out *PolicyRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]PolicyResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyRule.
+func (in *PolicyRule) DeepCopy() *PolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaRule) DeepCopyInto(out *QuotaRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.ResourceSets != nil {
+		in, out := &in.ResourceSets, &out.ResourceSets
+		*out = make([]ResourceSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaRule.
+func (in *QuotaRule) DeepCopy() *QuotaRule {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RBACRule) DeepCopyInto(out *RBACRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]PermissionSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACRule.
+func (in *RBACRule) DeepCopy() *RBACRule {
+	if in == nil {
+		return nil
+	}
+	out := new(RBACRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Resource) DeepCopyInto(out *Resource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Resource.
+func (in *Resource) DeepCopy() *Resource {
+	if in == nil {
+		return nil
+	}
+	out := new(Resource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceGraphQueryRule) DeepCopyInto(out *ResourceGraphQueryRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.SubscriptionIDs != nil {
+		in, out := &in.SubscriptionIDs, &out.SubscriptionIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RowCount != nil {
+		in, out := &in.RowCount, &out.RowCount
+		*out = new(ResourceGraphRowCount)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RowExpectations != nil {
+		in, out := &in.RowExpectations, &out.RowExpectations
+		*out = make([]ResourceGraphRowExpectation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceGraphQueryRule.
+func (in *ResourceGraphQueryRule) DeepCopy() *ResourceGraphQueryRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceGraphQueryRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceGraphRowCount) DeepCopyInto(out *ResourceGraphRowCount) {
+	*out = *in
+	if in.Equals != nil {
+		in, out := &in.Equals, &out.Equals
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceGraphRowCount.
+func (in *ResourceGraphRowCount) DeepCopy() *ResourceGraphRowCount {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceGraphRowCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceGraphRowExpectation) DeepCopyInto(out *ResourceGraphRowExpectation) {
+	*out = *in
+	if in.AllowedValues != nil {
+		in, out := &in.AllowedValues, &out.AllowedValues
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceGraphRowExpectation.
+func (in *ResourceGraphRowExpectation) DeepCopy() *ResourceGraphRowExpectation {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceGraphRowExpectation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceGroupRule) DeepCopyInto(out *ResourceGroupRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.ResourceGroups != nil {
+		in, out := &in.ResourceGroups, &out.ResourceGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]TagRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceGroupRule.
+func (in *ResourceGroupRule) DeepCopy() *ResourceGroupRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceGroupRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceProviderRule) DeepCopyInto(out *ResourceProviderRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceProviderRule.
+func (in *ResourceProviderRule) DeepCopy() *ResourceProviderRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceProviderRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSet) DeepCopyInto(out *ResourceSet) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]Resource, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSet.
+func (in *ResourceSet) DeepCopy() *ResourceSet {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleDefinitionRule) DeepCopyInto(out *RoleDefinitionRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]ActionStr, len(*in))
+		copy(*out, *in)
+	}
+	if in.NotActions != nil {
+		in, out := &in.NotActions, &out.NotActions
+		*out = make([]ActionStr, len(*in))
+		copy(*out, *in)
+	}
+	if in.DataActions != nil {
+		in, out := &in.DataActions, &out.DataActions
+		*out = make([]ActionStr, len(*in))
+		copy(*out, *in)
+	}
+	if in.NotDataActions != nil {
+		in, out := &in.NotDataActions, &out.NotDataActions
+		*out = make([]ActionStr, len(*in))
+		copy(*out, *in)
+	}
+	if in.AssignableScopes != nil {
+		in, out := &in.AssignableScopes, &out.AssignableScopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleDefinitionRule.
+func (in *RoleDefinitionRule) DeepCopy() *RoleDefinitionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleDefinitionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServicePrincipalCredentials) DeepCopyInto(out *ServicePrincipalCredentials) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServicePrincipalCredentials.
+func (in *ServicePrincipalCredentials) DeepCopy() *ServicePrincipalCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(ServicePrincipalCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageAccountRule) DeepCopyInto(out *StorageAccountRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.AllowBlobPublicAccess != nil {
+		in, out := &in.AllowBlobPublicAccess, &out.AllowBlobPublicAccess
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HTTPSTrafficOnly != nil {
+		in, out := &in.HTTPSTrafficOnly, &out.HTTPSTrafficOnly
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HierarchicalNamespace != nil {
+		in, out := &in.HierarchicalNamespace, &out.HierarchicalNamespace
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageAccountRule.
+func (in *StorageAccountRule) DeepCopy() *StorageAccountRule {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageAccountRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetRule) DeepCopyInto(out *SubnetRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.Delegations != nil {
+		in, out := &in.Delegations, &out.Delegations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceEndpoints != nil {
+		in, out := &in.ServiceEndpoints, &out.ServiceEndpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetRule.
+func (in *SubnetRule) DeepCopy() *SubnetRule {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubscriptionRule) DeepCopyInto(out *SubscriptionRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriptionRule.
+func (in *SubscriptionRule) DeepCopy() *SubscriptionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(SubscriptionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TagRequirement) DeepCopyInto(out *TagRequirement) {
+	*out = *in
+	if in.AllowedValues != nil {
+		in, out := &in.AllowedValues, &out.AllowedValues
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TagRequirement.
+func (in *TagRequirement) DeepCopy() *TagRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(TagRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TagRule) DeepCopyInto(out *TagRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.ResourceTypes != nil {
+		in, out := &in.ResourceTypes, &out.ResourceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]TagRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TagRule.
+func (in *TagRule) DeepCopy() *TagRule {
+	if in == nil {
+		return nil
+	}
+	out := new(TagRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VMSizeRule) DeepCopyInto(out *VMSizeRule) {
+	*out = *in
+	out.ManuallyNamed = in.ManuallyNamed
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VMSizes != nil {
+		in, out := &in.VMSizes, &out.VMSizes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VMSizeRule.
+func (in *VMSizeRule) DeepCopy() *VMSizeRule {
+	if in == nil {
+		return nil
+	}
+	out := new(VMSizeRule)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -0,0 +1,206 @@
+package azure
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v4"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	// aksProvisioningStateSucceeded is the provisioning state of AKS clusters and node pools whose
+	// last operation succeeded.
+	aksProvisioningStateSucceeded = "Succeeded"
+)
+
+var (
+	aksClusterRulePermissions = []string{
+		"Microsoft.ContainerService/managedClusters/read",
+		"Microsoft.ContainerService/locations/kubernetesVersions/read",
+	}
+)
+
+// aksClusterAPI contains methods that allow getting all the information we need for AKS clusters.
+type aksClusterAPI interface {
+	GetManagedCluster(resourceGroup, name, subscriptionID string) (*armcontainerservice.ManagedCluster, error)
+	GetKubernetesVersions(location, subscriptionID string) ([]*armcontainerservice.KubernetesVersion, error)
+}
+
+// AKSClusterRuleService reconciles AKS cluster rules.
+type AKSClusterRuleService struct {
+	api aksClusterAPI
+	log logr.Logger
+}
+
+// NewAKSClusterRuleService creates a new AKSClusterRuleService. Requires an Azure client facade
+// that supports getting AKS clusters and the Kubernetes versions AKS supports.
+func NewAKSClusterRuleService(api aksClusterAPI, log logr.Logger) *AKSClusterRuleService {
+	return &AKSClusterRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileAKSClusterRule reconciles an AKS cluster rule.
+func (s *AKSClusterRuleService) ReconcileAKSClusterRule(rule v1alpha1.AKSClusterRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "cluster", rule.Cluster, "resourceGroup", rule.ResourceGroup, "subscription", rule.SubscriptionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "AKS cluster healthy, on a supported Kubernetes version, and configured as required."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeAKSCluster
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	cluster, err := s.api.GetManagedCluster(rule.ResourceGroup, rule.Cluster, rule.SubscriptionID)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return validationResult, fmt.Errorf("AKS cluster %s not found in resource group %s using subscription %s", rule.Cluster, rule.ResourceGroup, rule.SubscriptionID)
+		}
+		return validationResult, fmt.Errorf("failed to get AKS cluster: %w", azerr.AsAugmented(err, aksClusterRulePermissions))
+	}
+
+	props := cluster.Properties
+	if props == nil {
+		props = &armcontainerservice.ManagedClusterProperties{}
+	}
+	processAKSClusterState(props, &latestCondition.Failures)
+	processAKSClusterFeatures(rule, props, &latestCondition.Failures)
+	for _, np := range rule.NodePools {
+		processAKSNodePool(np, props.AgentPoolProfiles, &latestCondition.Failures)
+	}
+
+	if cluster.Location == nil || props.CurrentKubernetesVersion == nil {
+		log.Error(nil, "AKS cluster location or Kubernetes version in API response was nil.")
+	} else {
+		versions, err := s.api.GetKubernetesVersions(*cluster.Location, rule.SubscriptionID)
+		if err != nil {
+			return validationResult, fmt.Errorf("failed to get Kubernetes versions: %w", azerr.AsAugmented(err, aksClusterRulePermissions))
+		}
+		supportPlan := armcontainerservice.KubernetesSupportPlanKubernetesOfficial
+		if props.SupportPlan != nil {
+			supportPlan = *props.SupportPlan
+		}
+		processKubernetesVersion(*props.CurrentKubernetesVersion, *cluster.Location, supportPlan, versions, &latestCondition.Failures)
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "AKS cluster unhealthy, on an unsupported Kubernetes version, or not configured as required. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// processAKSClusterState checks that the AKS cluster's last operation succeeded and that it's
+// running.
+func processAKSClusterState(props *armcontainerservice.ManagedClusterProperties, failures *[]string) {
+	if props.ProvisioningState != nil && *props.ProvisioningState != aksProvisioningStateSucceeded {
+		*failures = append(*failures, fmt.Sprintf("AKS cluster provisioning state is '%s', expected '%s'.", *props.ProvisioningState, aksProvisioningStateSucceeded))
+	}
+	if props.PowerState != nil && props.PowerState.Code != nil && *props.PowerState.Code != armcontainerservice.CodeRunning {
+		*failures = append(*failures, fmt.Sprintf("AKS cluster power state is '%s', expected '%s'.", *props.PowerState.Code, armcontainerservice.CodeRunning))
+	}
+}
+
+// processAKSClusterFeatures checks that the AKS cluster has the features the rule requires enabled.
+func processAKSClusterFeatures(rule v1alpha1.AKSClusterRule, props *armcontainerservice.ManagedClusterProperties, failures *[]string) {
+	if rule.OIDCIssuer && (props.OidcIssuerProfile == nil || !valueOrZero(props.OidcIssuerProfile.Enabled)) {
+		*failures = append(*failures, "AKS cluster OIDC issuer not enabled.")
+	}
+	if rule.WorkloadIdentity && (props.SecurityProfile == nil || props.SecurityProfile.WorkloadIdentity == nil ||
+		!valueOrZero(props.SecurityProfile.WorkloadIdentity.Enabled)) {
+		*failures = append(*failures, "AKS cluster workload identity not enabled.")
+	}
+}
+
+// processAKSNodePool checks that the AKS cluster has a node pool from the rule, and that the node
+// pool is healthy and has the properties the rule specifies.
+func processAKSNodePool(np v1alpha1.AKSNodePool, profiles []*armcontainerservice.ManagedClusterAgentPoolProfile, failures *[]string) {
+	i := slices.IndexFunc(profiles, func(p *armcontainerservice.ManagedClusterAgentPoolProfile) bool {
+		return p != nil && p.Name != nil && *p.Name == np.Name
+	})
+	if i == -1 {
+		*failures = append(*failures, fmt.Sprintf("Node pool '%s' not found.", np.Name))
+		return
+	}
+	p := profiles[i]
+
+	if p.ProvisioningState != nil && *p.ProvisioningState != aksProvisioningStateSucceeded {
+		*failures = append(*failures, fmt.Sprintf("Node pool '%s' provisioning state is '%s', expected '%s'.", np.Name, *p.ProvisioningState, aksProvisioningStateSucceeded))
+	}
+	if np.VMSize != "" && !strings.EqualFold(valueOrZero(p.VMSize), np.VMSize) {
+		*failures = append(*failures, fmt.Sprintf("Node pool '%s' VM size is '%s', expected '%s'.", np.Name, valueOrZero(p.VMSize), np.VMSize))
+	}
+	if np.MinCount != nil && valueOrZero(p.Count) < *np.MinCount {
+		*failures = append(*failures, fmt.Sprintf("Node pool '%s' has %d node(s), expected at least %d.", np.Name, valueOrZero(p.Count), *np.MinCount))
+	}
+	if np.OSType != "" && !strings.EqualFold(string(valueOrZero(p.OSType)), np.OSType) {
+		*failures = append(*failures, fmt.Sprintf("Node pool '%s' OS type is '%s', expected '%s'.", np.Name, valueOrZero(p.OSType), np.OSType))
+	}
+	if np.OSSKU != "" && !strings.EqualFold(string(valueOrZero(p.OSSKU)), np.OSSKU) {
+		*failures = append(*failures, fmt.Sprintf("Node pool '%s' OS SKU is '%s', expected '%s'.", np.Name, valueOrZero(p.OSSKU), np.OSSKU))
+	}
+}
+
+// processKubernetesVersion checks that AKS still supports the AKS cluster's Kubernetes version in
+// its location, with the cluster's support plan. AKS lists supported versions as major.minor
+// versions with their supported patch versions.
+func processKubernetesVersion(version, location string, supportPlan armcontainerservice.KubernetesSupportPlan, versions []*armcontainerservice.KubernetesVersion, failures *[]string) {
+	parts := strings.Split(version, ".")
+	minor := version
+	if len(parts) >= 2 {
+		minor = strings.Join(parts[:2], ".")
+	}
+
+	i := slices.IndexFunc(versions, func(v *armcontainerservice.KubernetesVersion) bool {
+		return v != nil && v.Version != nil && *v.Version == minor
+	})
+	if i == -1 {
+		*failures = append(*failures, fmt.Sprintf("AKS cluster Kubernetes version %s not supported in location '%s'; minor version %s no longer listed.", version, location, minor))
+		return
+	}
+	v := versions[i]
+
+	if _, ok := v.PatchVersions[version]; !ok {
+		*failures = append(*failures, fmt.Sprintf("AKS cluster Kubernetes version %s not supported in location '%s'; patch version no longer listed.", version, location))
+	}
+	if v.Capabilities != nil && len(v.Capabilities.SupportPlan) > 0 {
+		plans := []string{}
+		for _, p := range v.Capabilities.SupportPlan {
+			if p != nil {
+				plans = append(plans, string(*p))
+			}
+		}
+		if !slices.Contains(plans, string(supportPlan)) {
+			*failures = append(*failures, fmt.Sprintf("AKS cluster Kubernetes version %s only supported in location '%s' with support plans %v, but cluster uses support plan '%s'.", version, location, plans, supportPlan))
+		}
+	}
+}
+
+// valueOrZero returns the value a pointer points to, or the zero value of its type if it's nil.
+func valueOrZero[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,215 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v4"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type aksClusterAPIMock struct {
+	cluster    *armcontainerservice.ManagedCluster
+	clusterErr error
+	versions   []*armcontainerservice.KubernetesVersion
+}
+
+func (m aksClusterAPIMock) GetManagedCluster(_, _, _ string) (*armcontainerservice.ManagedCluster, error) {
+	return m.cluster, m.clusterErr
+}
+
+func (m aksClusterAPIMock) GetKubernetesVersions(_, _ string) ([]*armcontainerservice.KubernetesVersion, error) {
+	return m.versions, nil
+}
+
+func managedCluster(version, provisioningState string, oidcIssuer, workloadIdentity bool, pools ...*armcontainerservice.ManagedClusterAgentPoolProfile) *armcontainerservice.ManagedCluster {
+	return &armcontainerservice.ManagedCluster{
+		Location: util.Ptr("eastus"),
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			CurrentKubernetesVersion: util.Ptr(version),
+			ProvisioningState:        util.Ptr(provisioningState),
+			PowerState:               &armcontainerservice.PowerState{Code: util.Ptr(armcontainerservice.CodeRunning)},
+			OidcIssuerProfile:        &armcontainerservice.ManagedClusterOIDCIssuerProfile{Enabled: util.Ptr(oidcIssuer)},
+			SecurityProfile: &armcontainerservice.ManagedClusterSecurityProfile{
+				WorkloadIdentity: &armcontainerservice.ManagedClusterSecurityProfileWorkloadIdentity{Enabled: util.Ptr(workloadIdentity)},
+			},
+			AgentPoolProfiles: pools,
+		},
+	}
+}
+
+func agentPoolProfile(name, vmSize string, count int32, osType armcontainerservice.OSType, osSKU armcontainerservice.OSSKU) *armcontainerservice.ManagedClusterAgentPoolProfile {
+	return &armcontainerservice.ManagedClusterAgentPoolProfile{
+		Name:              util.Ptr(name),
+		VMSize:            util.Ptr(vmSize),
+		Count:             util.Ptr(count),
+		OSType:            util.Ptr(osType),
+		OSSKU:             util.Ptr(osSKU),
+		ProvisioningState: util.Ptr("Succeeded"),
+	}
+}
+
+func TestAKSClusterRuleService_ReconcileAKSClusterRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.AKSClusterRule
+		apiMock        aksClusterAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	rule := v1alpha1.AKSClusterRule{
+		RuleName:      "rule-1",
+		ResourceGroup: "rg",
+		Cluster:       "cluster",
+		NodePools: []v1alpha1.AKSNodePool{
+			{Name: "system", VMSize: "Standard_D4s_v5", MinCount: util.Ptr(int32(3)), OSType: "Linux", OSSKU: "AzureLinux"},
+			{Name: "win"},
+		},
+		OIDCIssuer:       true,
+		WorkloadIdentity: true,
+		SubscriptionID:   "sub",
+	}
+
+	versions := []*armcontainerservice.KubernetesVersion{
+		{
+			Version: util.Ptr("1.30"),
+			PatchVersions: map[string]*armcontainerservice.KubernetesPatchVersion{
+				"1.30.5": {},
+				"1.30.6": {},
+			},
+			Capabilities: &armcontainerservice.KubernetesVersionCapabilities{
+				SupportPlan: []*armcontainerservice.KubernetesSupportPlan{
+					util.Ptr(armcontainerservice.KubernetesSupportPlanKubernetesOfficial),
+				},
+			},
+		},
+		{
+			Version: util.Ptr("1.27"),
+			PatchVersions: map[string]*armcontainerservice.KubernetesPatchVersion{
+				"1.27.100": {},
+			},
+			Capabilities: &armcontainerservice.KubernetesVersionCapabilities{
+				SupportPlan: []*armcontainerservice.KubernetesSupportPlan{
+					util.Ptr(armcontainerservice.KubernetesSupportPlanAKSLongTermSupport),
+				},
+			},
+		},
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (AKS cluster healthy, on supported version, and configured as required)",
+			rule: rule,
+			apiMock: aksClusterAPIMock{
+				cluster: managedCluster("1.30.6", "Succeeded", true, true,
+					agentPoolProfile("system", "standard_d4s_v5", 3, armcontainerservice.OSTypeLinux, armcontainerservice.OSSKUAzureLinux),
+					agentPoolProfile("win", "Standard_D4s_v5", 1, armcontainerservice.OSTypeWindows, armcontainerservice.OSSKUWindows2022),
+				),
+				versions: versions,
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-aks-cluster",
+					ValidationRule: "validation-rule-1",
+					Message:        "AKS cluster healthy, on a supported Kubernetes version, and configured as required.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (AKS cluster failed, on unsupported patch version, without workload identity, and with mismatched node pools)",
+			rule: rule,
+			apiMock: aksClusterAPIMock{
+				cluster: managedCluster("1.30.1", "Failed", true, false,
+					agentPoolProfile("system", "Standard_D2s_v5", 2, armcontainerservice.OSTypeLinux, armcontainerservice.OSSKUUbuntu),
+				),
+				versions: versions,
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-aks-cluster",
+					ValidationRule: "validation-rule-1",
+					Message:        "AKS cluster unhealthy, on an unsupported Kubernetes version, or not configured as required. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"AKS cluster provisioning state is 'Failed', expected 'Succeeded'.",
+						"AKS cluster workload identity not enabled.",
+						"Node pool 'system' VM size is 'Standard_D2s_v5', expected 'Standard_D4s_v5'.",
+						"Node pool 'system' has 2 node(s), expected at least 3.",
+						"Node pool 'system' OS SKU is 'Ubuntu', expected 'AzureLinux'.",
+						"Node pool 'win' not found.",
+						"AKS cluster Kubernetes version 1.30.1 not supported in location 'eastus'; patch version no longer listed.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (AKS cluster on version only supported with long-term support)",
+			rule: v1alpha1.AKSClusterRule{
+				RuleName:       "rule-1",
+				ResourceGroup:  "rg",
+				Cluster:        "cluster",
+				SubscriptionID: "sub",
+			},
+			apiMock: aksClusterAPIMock{
+				cluster:  managedCluster("1.27.100", "Succeeded", false, false),
+				versions: versions,
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-aks-cluster",
+					ValidationRule: "validation-rule-1",
+					Message:        "AKS cluster unhealthy, on an unsupported Kubernetes version, or not configured as required. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"AKS cluster Kubernetes version 1.27.100 only supported in location 'eastus' with support plans [AKSLongTermSupport], but cluster uses support plan 'KubernetesOfficial'.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (error getting AKS cluster) - validation result remains passing, code returned to interprets error and changes result",
+			rule: rule,
+			apiMock: aksClusterAPIMock{
+				// Can be any error message, just has to have this as substring.
+				clusterErr: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("AKS cluster cluster not found in resource group rg using subscription sub"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-aks-cluster",
+					ValidationRule: "validation-rule-1",
+					Message:        "AKS cluster healthy, on a supported Kubernetes version, and configured as required.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewAKSClusterRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileAKSClusterRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
@@ -0,0 +1,218 @@
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	// defaultFailWithinDays and defaultWarnWithinDays are used when the rule doesn't specify them,
+	// which happens when rules don't come from the CRD (whose schema defaults them).
+	defaultFailWithinDays = 7
+	defaultWarnWithinDays = 30
+)
+
+var (
+	// applicationCredentialRulePermissions are Microsoft Graph application permissions, not Azure
+	// RBAC permissions.
+	applicationCredentialRulePermissions = []string{
+		"Application.Read.All",
+	}
+)
+
+// applicationAPI contains methods that allow getting all the information we need for Microsoft
+// Entra ID applications and service principals.
+type applicationAPI interface {
+	GetApplication(appID string) (*azutils.GraphApplication, error)
+	GetServicePrincipal(appID string) (*azutils.GraphApplication, error)
+}
+
+// applicationCredential is a client secret or certificate of an application or service principal.
+type applicationCredential struct {
+	kind        string
+	description string
+	hint        string
+	endDateTime time.Time
+}
+
+// ApplicationCredentialRuleService reconciles application credential rules.
+type ApplicationCredentialRuleService struct {
+	api applicationAPI
+	// authClientID is the client ID the plugin authenticates with. It's used when rules don't
+	// specify a client ID.
+	authClientID string
+	// authSecretHint is the first three characters of the client secret the plugin authenticates
+	// with, which Microsoft Graph returns as the hint of the client secret. It's used to find the
+	// client secret the plugin authenticates with. Empty when the plugin doesn't authenticate with
+	// a client secret.
+	authSecretHint string
+	log            logr.Logger
+}
+
+// NewApplicationCredentialRuleService creates a new ApplicationCredentialRuleService. Requires a
+// Microsoft Graph client facade that supports getting applications and service principals, and the
+// client ID and client secret hint the plugin authenticates with.
+func NewApplicationCredentialRuleService(api applicationAPI, authClientID, authSecretHint string, log logr.Logger) *ApplicationCredentialRuleService {
+	return &ApplicationCredentialRuleService{
+		api:            api,
+		authClientID:   authClientID,
+		authSecretHint: authSecretHint,
+		log:            log,
+	}
+}
+
+// ReconcileApplicationCredentialRule reconciles an application credential rule.
+func (s *ApplicationCredentialRuleService) ReconcileApplicationCredentialRule(rule v1alpha1.ApplicationCredentialRule) (*vapitypes.ValidationRuleResult, error) {
+
+	clientID := rule.ClientID
+	if clientID == "" {
+		clientID = s.authClientID
+	}
+
+	log := s.log.WithValues("rule", rule.Name(), "clientId", clientID, "servicePrincipal", rule.ServicePrincipal)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "Application credentials don't expire soon."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeApplicationCredential
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	if clientID == "" {
+		return validationResult, errors.New("rule doesn't specify a client ID and plugin isn't authenticating with a client ID")
+	}
+
+	objectType := "application"
+	get := s.api.GetApplication
+	if rule.ServicePrincipal {
+		objectType = "service principal"
+		get = s.api.GetServicePrincipal
+	}
+	app, err := get(clientID)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return validationResult, fmt.Errorf("%s with client ID %s not found", objectType, clientID)
+		}
+		return validationResult, fmt.Errorf("failed to get %s: %w", objectType, azerr.AsAugmented(err, applicationCredentialRulePermissions))
+	}
+
+	// Only client secrets of the application the plugin authenticates with can be the one it uses.
+	authSecretHint := ""
+	if clientID == s.authClientID {
+		authSecretHint = s.authSecretHint
+	}
+
+	now := time.Now()
+	for _, c := range applicationCredentials(app, log) {
+		processApplicationCredential(c, rule, authSecretHint, now, &latestCondition.Details, &latestCondition.Failures)
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "One or more application credentials expire soon. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// applicationCredentials returns the client secrets and certificates of an application or service
+// principal, skipping the ones missing required properties.
+func applicationCredentials(app *azutils.GraphApplication, log logr.Logger) []applicationCredential {
+	credentials := []applicationCredential{}
+	for _, c := range app.PasswordCredentials {
+		if c == nil || c.EndDateTime == nil {
+			log.Error(nil, "Password credential in API response was missing properties.")
+			continue
+		}
+		hint := ""
+		if c.Hint != nil {
+			hint = *c.Hint
+		}
+		credentials = append(credentials, applicationCredential{
+			kind:        "Client secret",
+			description: credentialDescription(c.DisplayName, c.KeyID),
+			hint:        hint,
+			endDateTime: *c.EndDateTime,
+		})
+	}
+	for _, c := range app.KeyCredentials {
+		if c == nil || c.EndDateTime == nil {
+			log.Error(nil, "Key credential in API response was missing properties.")
+			continue
+		}
+		credentials = append(credentials, applicationCredential{
+			kind:        "Certificate",
+			description: credentialDescription(c.DisplayName, c.KeyID),
+			endDateTime: *c.EndDateTime,
+		})
+	}
+	return credentials
+}
+
+// credentialDescription describes a credential by its display name and key ID, whichever are set.
+func credentialDescription(displayName, keyID *string) string {
+	switch {
+	case displayName != nil && *displayName != "" && keyID != nil:
+		return fmt.Sprintf("'%s' (key ID %s)", *displayName, *keyID)
+	case keyID != nil:
+		return fmt.Sprintf("with key ID %s", *keyID)
+	case displayName != nil:
+		return fmt.Sprintf("'%s'", *displayName)
+	default:
+		return "without name or key ID"
+	}
+}
+
+// processApplicationCredential checks when a credential expires. Credentials expiring within the
+// rule's fail window are failures and credentials expiring within its warn window are added to the
+// details. Credentials that already expired are added to the details because they're commonly left
+// behind after rotation, unless it's the client secret the plugin authenticates with.
+func processApplicationCredential(c applicationCredential, rule v1alpha1.ApplicationCredentialRule, authSecretHint string, now time.Time, details, failures *[]string) {
+	name := fmt.Sprintf("%s %s", c.kind, c.description)
+	if authSecretHint != "" && c.hint == authSecretHint {
+		name = fmt.Sprintf("%s, which the plugin authenticates with,", name)
+	}
+	expiry := c.endDateTime.UTC().Format(time.DateOnly)
+	days := int(c.endDateTime.Sub(now).Hours() / 24)
+
+	failWithinDays, warnWithinDays := defaultFailWithinDays, defaultWarnWithinDays
+	if rule.FailWithinDays != nil {
+		failWithinDays = *rule.FailWithinDays
+	}
+	if rule.WarnWithinDays != nil {
+		warnWithinDays = *rule.WarnWithinDays
+	}
+
+	switch {
+	case !c.endDateTime.After(now):
+		msg := fmt.Sprintf("%s expired on %s.", name, expiry)
+		if authSecretHint != "" && c.hint == authSecretHint {
+			*failures = append(*failures, msg)
+			return
+		}
+		*details = append(*details, msg)
+	case days < failWithinDays:
+		*failures = append(*failures, fmt.Sprintf("%s expires in %d day(s), on %s.", name, days, expiry))
+	case days < warnWithinDays:
+		*details = append(*details, fmt.Sprintf("Warning: %s expires in %d day(s), on %s.", name, days, expiry))
+	}
+}
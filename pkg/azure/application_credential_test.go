@@ -0,0 +1,178 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type applicationAPIMock struct {
+	application         *azutils.GraphApplication
+	applicationErr      error
+	servicePrincipal    *azutils.GraphApplication
+	servicePrincipalErr error
+}
+
+func (m applicationAPIMock) GetApplication(_ string) (*azutils.GraphApplication, error) {
+	return m.application, m.applicationErr
+}
+
+func (m applicationAPIMock) GetServicePrincipal(_ string) (*azutils.GraphApplication, error) {
+	return m.servicePrincipal, m.servicePrincipalErr
+}
+
+func TestApplicationCredentialRuleService_ReconcileApplicationCredentialRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.ApplicationCredentialRule
+		apiMock        applicationAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	// Add an hour so that the number of whole days until expiry doesn't depend on how long the test
+	// takes to run.
+	inDays := func(days int) *time.Time {
+		t := time.Now().Add(time.Duration(days)*24*time.Hour + time.Hour)
+		return &t
+	}
+	date := func(t *time.Time) string {
+		return t.UTC().Format(time.DateOnly)
+	}
+
+	expired, soon, later, far := inDays(-10), inDays(3), inDays(20), inDays(200)
+
+	// FailWithinDays and WarnWithinDays default to 7 and 30.
+	rule := v1alpha1.ApplicationCredentialRule{
+		RuleName: "rule-1",
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (credentials don't expire soon, with warnings for credentials expiring later and expired ones)",
+			rule: rule,
+			apiMock: applicationAPIMock{
+				application: &azutils.GraphApplication{
+					PasswordCredentials: []*azutils.GraphPasswordCredential{
+						{KeyID: util.Ptr("k1"), DisplayName: util.Ptr("validator"), Hint: util.Ptr("abc"), EndDateTime: far},
+						{KeyID: util.Ptr("k2"), DisplayName: util.Ptr("old"), Hint: util.Ptr("def"), EndDateTime: expired},
+					},
+					KeyCredentials: []*azutils.GraphKeyCredential{
+						{KeyID: util.Ptr("k3"), EndDateTime: later},
+					},
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-application-credential",
+					ValidationRule: "validation-rule-1",
+					Message:        "Application credentials don't expire soon.",
+					Details: []string{
+						"Client secret 'old' (key ID k2) expired on " + date(expired) + ".",
+						"Warning: Certificate with key ID k3 expires in 20 day(s), on " + date(later) + ".",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (client secret the plugin authenticates with expires soon)",
+			rule: rule,
+			apiMock: applicationAPIMock{
+				application: &azutils.GraphApplication{
+					PasswordCredentials: []*azutils.GraphPasswordCredential{
+						{KeyID: util.Ptr("k1"), DisplayName: util.Ptr("validator"), Hint: util.Ptr("abc"), EndDateTime: soon},
+					},
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-application-credential",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more application credentials expire soon. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Client secret 'validator' (key ID k1), which the plugin authenticates with, expires in 3 day(s), on " + date(soon) + ".",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (service principal certificate expires soon)",
+			rule: v1alpha1.ApplicationCredentialRule{
+				RuleName:         "rule-1",
+				ClientID:         "other_client_id",
+				ServicePrincipal: true,
+				FailWithinDays:   util.Ptr(7),
+				WarnWithinDays:   util.Ptr(30),
+			},
+			apiMock: applicationAPIMock{
+				servicePrincipal: &azutils.GraphApplication{
+					// Same hint as the plugin's client secret, but a different application.
+					PasswordCredentials: []*azutils.GraphPasswordCredential{
+						{KeyID: util.Ptr("k1"), Hint: util.Ptr("abc"), EndDateTime: far},
+					},
+					KeyCredentials: []*azutils.GraphKeyCredential{
+						{KeyID: util.Ptr("k2"), DisplayName: util.Ptr("CN=saml"), EndDateTime: soon},
+					},
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-application-credential",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more application credentials expire soon. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Certificate 'CN=saml' (key ID k2) expires in 3 day(s), on " + date(soon) + ".",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (error getting application) - validation result remains passing, code returned to interprets error and changes result",
+			rule: rule,
+			apiMock: applicationAPIMock{
+				// Can be any error message, just has to have this as substring.
+				applicationErr: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("application with client ID client_id not found"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-application-credential",
+					ValidationRule: "validation-rule-1",
+					Message:        "Application credentials don't expire soon.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewApplicationCredentialRuleService(tc.apiMock, "client_id", "abc", logr.Logger{})
+		result, err := svc.ReconcileApplicationCredentialRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
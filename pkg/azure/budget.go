@@ -0,0 +1,166 @@
+package azure
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+var (
+	budgetRulePermissions = []string{
+		"Microsoft.Consumption/budgets/read",
+	}
+)
+
+// budgetAPI contains methods that allow getting all the information we need for budgets.
+type budgetAPI interface {
+	GetBudget(scope, budgetName string) (*azutils.Budget, error)
+}
+
+// BudgetRuleService reconciles budget rules.
+type BudgetRuleService struct {
+	api budgetAPI
+	log logr.Logger
+}
+
+// NewBudgetRuleService creates a new BudgetRuleService. Requires an Azure client facade that
+// supports getting budgets.
+func NewBudgetRuleService(api budgetAPI, log logr.Logger) *BudgetRuleService {
+	return &BudgetRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileBudgetRule reconciles a budget rule.
+func (s *BudgetRuleService) ReconcileBudgetRule(rule v1alpha1.BudgetRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "scope", rule.Scope, "budget", rule.BudgetName)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "Budget has expected notifications and spend is within limits."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeBudget
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	budget, err := s.api.GetBudget(rule.Scope, rule.BudgetName)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return validationResult, fmt.Errorf("budget %s not found at scope %s", rule.BudgetName, rule.Scope)
+		}
+		return validationResult, fmt.Errorf("failed to get budget: %w", azerr.AsAugmented(err, budgetRulePermissions))
+	}
+	if budget.Properties == nil || budget.Properties.Amount == nil {
+		log.Error(nil, "Budget amount in API response was nil.")
+		return validationResult, fmt.Errorf("budget %s has no amount", rule.BudgetName)
+	}
+
+	processBudgetNotifications(rule, budget.Properties.Notifications, &latestCondition.Failures)
+	processBudgetSpend(rule.BudgetName, "actual", budget.Properties.CurrentSpend, *budget.Properties.Amount, rule.MaxActualSpendPercent, &latestCondition.Details, &latestCondition.Failures)
+	processBudgetSpend(rule.BudgetName, "forecasted", budget.Properties.ForecastSpend, *budget.Properties.Amount, rule.MaxForecastedSpendPercent, &latestCondition.Details, &latestCondition.Failures)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "Budget doesn't have expected notifications or spend exceeded limits. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// processBudgetNotifications checks that a budget has the enabled notifications a rule expects, or
+// at least one enabled notification when the rule doesn't expect specific ones. Adds a failure for
+// each expected notification that's missing.
+func processBudgetNotifications(rule v1alpha1.BudgetRule, notifications map[string]*azutils.BudgetNotification, failures *[]string) {
+	// Notifications are keyed by name, so they're sorted to make failures deterministic.
+	names := make([]string, 0, len(notifications))
+	for name := range notifications {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	enabled := []*azutils.BudgetNotification{}
+	for _, name := range names {
+		n := notifications[name]
+		if n != nil && n.Enabled != nil && *n.Enabled && n.Threshold != nil {
+			enabled = append(enabled, n)
+		}
+	}
+
+	if len(rule.Notifications) == 0 {
+		if len(enabled) == 0 {
+			*failures = append(*failures, fmt.Sprintf("Budget %s has no enabled notifications.", rule.BudgetName))
+		}
+		return
+	}
+
+	for _, expected := range rule.Notifications {
+		thresholdType := expected.ThresholdType
+		if thresholdType == "" {
+			thresholdType = "Actual"
+		}
+		found := false
+		for _, n := range enabled {
+			// Notifications without a threshold type are for actual spend.
+			nThresholdType := "Actual"
+			if n.ThresholdType != nil {
+				nThresholdType = *n.ThresholdType
+			}
+			if *n.Threshold == float64(expected.Threshold) && nThresholdType == thresholdType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			*failures = append(*failures, fmt.Sprintf("Budget %s has no enabled %s notification with threshold %d%%.", rule.BudgetName, thresholdType, expected.Threshold))
+		}
+	}
+}
+
+// processBudgetSpend checks actual or forecasted spend against a budget's amount. Adds details
+// about the spend, and a failure if it isn't below the maximum percentage of the budget's amount,
+// when one is provided.
+func processBudgetSpend(budgetName, kind string, spend *azutils.BudgetSpend, amount float64, maxPercent *int32, details, failures *[]string) {
+	if spend == nil || spend.Amount == nil {
+		if maxPercent != nil {
+			*details = append(*details, fmt.Sprintf("Budget %s has no %s spend, so it wasn't checked.", budgetName, kind))
+		}
+		return
+	}
+
+	unit := ""
+	if spend.Unit != nil {
+		unit = " " + *spend.Unit
+	}
+	if amount <= 0 {
+		*details = append(*details, fmt.Sprintf("Budget %s: amount: %.2f%s, %s spend: %.2f%s", budgetName, amount, unit, kind, *spend.Amount, unit))
+		// Spend can't be a percentage of a budget without an amount, so any spend exceeds it.
+		if maxPercent != nil && *spend.Amount > 0 {
+			*failures = append(*failures, fmt.Sprintf("%s spend for budget %s is %.2f%s, but its amount is %.2f%s, so spend can't be below maximum %d%% of it.", capitalize(kind), budgetName, *spend.Amount, unit, amount, unit, *maxPercent))
+		}
+		return
+	}
+	percent := *spend.Amount / amount * 100
+	*details = append(*details, fmt.Sprintf("Budget %s: amount: %.2f%s, %s spend: %.2f%s (%.1f%%)", budgetName, amount, unit, kind, *spend.Amount, unit, percent))
+
+	if maxPercent != nil && percent >= float64(*maxPercent) {
+		*failures = append(*failures, fmt.Sprintf("%s spend for budget %s is %.1f%% of its amount, not below maximum %d%%.", capitalize(kind), budgetName, percent, *maxPercent))
+	}
+}
@@ -0,0 +1,212 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type budgetAPIMock struct {
+	budget *azutils.Budget
+	err    error
+}
+
+func (m budgetAPIMock) GetBudget(_, _ string) (*azutils.Budget, error) {
+	return m.budget, m.err
+}
+
+func budgetNotification(enabled bool, threshold float64, thresholdType string) *azutils.BudgetNotification {
+	return &azutils.BudgetNotification{
+		Enabled:       util.Ptr(enabled),
+		Operator:      util.Ptr("GreaterThan"),
+		Threshold:     util.Ptr(threshold),
+		ThresholdType: util.Ptr(thresholdType),
+	}
+}
+
+func TestBudgetRuleService_ReconcileBudgetRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.BudgetRule
+		apiMock        budgetAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	budget := func(actual, forecast float64, notifications map[string]*azutils.BudgetNotification) *azutils.Budget {
+		return &azutils.Budget{
+			Name: util.Ptr("sandbox"),
+			Properties: &azutils.BudgetProperties{
+				Amount:        util.Ptr(1000.0),
+				TimeGrain:     util.Ptr("Monthly"),
+				CurrentSpend:  &azutils.BudgetSpend{Amount: util.Ptr(actual), Unit: util.Ptr("USD")},
+				ForecastSpend: &azutils.BudgetSpend{Amount: util.Ptr(forecast), Unit: util.Ptr("USD")},
+				Notifications: notifications,
+			},
+		}
+	}
+
+	notifications := map[string]*azutils.BudgetNotification{
+		"actual_GreaterThan_80_Percent":      budgetNotification(true, 80, "Actual"),
+		"forecasted_GreaterThan_100_Percent": budgetNotification(true, 100, "Forecasted"),
+		"actual_GreaterThan_50_Percent":      budgetNotification(false, 50, "Actual"),
+	}
+
+	zeroAmountBudget := budget(25, 0, notifications)
+	zeroAmountBudget.Properties.Amount = util.Ptr(0.0)
+
+	testCases := []testCase{
+		{
+			name: "Pass (budget has notifications and spend is below limits)",
+			rule: v1alpha1.BudgetRule{
+				RuleName:   "rule-1",
+				Scope:      "/subscriptions/sub",
+				BudgetName: "sandbox",
+				Notifications: []v1alpha1.BudgetNotification{
+					{Threshold: 80},
+					{Threshold: 100, ThresholdType: "Forecasted"},
+				},
+				MaxActualSpendPercent:     util.Ptr(int32(95)),
+				MaxForecastedSpendPercent: util.Ptr(int32(120)),
+			},
+			apiMock:       budgetAPIMock{budget: budget(420.5, 1100, notifications)},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-budget",
+					ValidationRule: "validation-rule-1",
+					Message:        "Budget has expected notifications and spend is within limits.",
+					Details: []string{
+						"Budget sandbox: amount: 1000.00 USD, actual spend: 420.50 USD (42.0%)",
+						"Budget sandbox: amount: 1000.00 USD, forecasted spend: 1100.00 USD (110.0%)",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (notification disabled and actual spend at the limit)",
+			rule: v1alpha1.BudgetRule{
+				RuleName:   "rule-1",
+				Scope:      "/subscriptions/sub",
+				BudgetName: "sandbox",
+				Notifications: []v1alpha1.BudgetNotification{
+					{Threshold: 50, ThresholdType: "Actual"},
+					{Threshold: 80, ThresholdType: "Forecasted"},
+				},
+				MaxActualSpendPercent: util.Ptr(int32(95)),
+			},
+			apiMock:       budgetAPIMock{budget: budget(950, 1200, notifications)},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-budget",
+					ValidationRule: "validation-rule-1",
+					Message:        "Budget doesn't have expected notifications or spend exceeded limits. See failures for details.",
+					Details: []string{
+						"Budget sandbox: amount: 1000.00 USD, actual spend: 950.00 USD (95.0%)",
+						"Budget sandbox: amount: 1000.00 USD, forecasted spend: 1200.00 USD (120.0%)",
+					},
+					Failures: []string{
+						"Budget sandbox has no enabled Actual notification with threshold 50%.",
+						"Budget sandbox has no enabled Forecasted notification with threshold 80%.",
+						"Actual spend for budget sandbox is 95.0% of its amount, not below maximum 95%.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (budget has no enabled notifications)",
+			rule: v1alpha1.BudgetRule{
+				RuleName:   "rule-1",
+				Scope:      "/subscriptions/sub",
+				BudgetName: "sandbox",
+			},
+			apiMock: budgetAPIMock{budget: budget(100, 200, map[string]*azutils.BudgetNotification{
+				"actual_GreaterThan_50_Percent": budgetNotification(false, 50, "Actual"),
+			})},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-budget",
+					ValidationRule: "validation-rule-1",
+					Message:        "Budget doesn't have expected notifications or spend exceeded limits. See failures for details.",
+					Details: []string{
+						"Budget sandbox: amount: 1000.00 USD, actual spend: 100.00 USD (10.0%)",
+						"Budget sandbox: amount: 1000.00 USD, forecasted spend: 200.00 USD (20.0%)",
+					},
+					Failures: []string{"Budget sandbox has no enabled notifications."},
+					Status:   corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (budget amount is zero and there's actual spend)",
+			rule: v1alpha1.BudgetRule{
+				RuleName:                  "rule-1",
+				Scope:                     "/subscriptions/sub",
+				BudgetName:                "sandbox",
+				MaxActualSpendPercent:     util.Ptr(int32(95)),
+				MaxForecastedSpendPercent: util.Ptr(int32(120)),
+			},
+			apiMock:       budgetAPIMock{budget: zeroAmountBudget},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-budget",
+					ValidationRule: "validation-rule-1",
+					Message:        "Budget doesn't have expected notifications or spend exceeded limits. See failures for details.",
+					Details: []string{
+						"Budget sandbox: amount: 0.00 USD, actual spend: 25.00 USD",
+						"Budget sandbox: amount: 0.00 USD, forecasted spend: 0.00 USD",
+					},
+					Failures: []string{"Actual spend for budget sandbox is 25.00 USD, but its amount is 0.00 USD, so spend can't be below maximum 95% of it."},
+					Status:   corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Error (budget not found)",
+			rule: v1alpha1.BudgetRule{
+				RuleName:   "rule-1",
+				Scope:      "/subscriptions/sub",
+				BudgetName: "sandbox",
+			},
+			apiMock: budgetAPIMock{
+				err: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("budget sandbox not found at scope /subscriptions/sub"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-budget",
+					ValidationRule: "validation-rule-1",
+					Message:        "Budget has expected notifications and spend is within limits.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		svc := NewBudgetRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileBudgetRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
@@ -0,0 +1,175 @@
+package azure
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+var (
+	capacityReservationRulePermissions = []string{
+		"Microsoft.Compute/capacityReservationGroups/read",
+		"Microsoft.Compute/capacityReservationGroups/capacityReservations/read",
+	}
+)
+
+// capacityReservationAPI contains methods that allow getting all the information we need for
+// capacity reservation groups.
+type capacityReservationAPI interface {
+	GetCapacityReservationGroup(resourceGroup, groupName, subscriptionID string) (*armcompute.CapacityReservationGroup, error)
+	GetCapacityReservations(resourceGroup, groupName, subscriptionID string) ([]*armcompute.CapacityReservation, error)
+}
+
+// CapacityReservationRuleService reconciles capacity reservation rules.
+type CapacityReservationRuleService struct {
+	api capacityReservationAPI
+	log logr.Logger
+}
+
+// NewCapacityReservationRuleService creates a new CapacityReservationRuleService. Requires an Azure
+// client facade that supports getting capacity reservation groups and their capacity reservations.
+func NewCapacityReservationRuleService(api capacityReservationAPI, log logr.Logger) *CapacityReservationRuleService {
+	return &CapacityReservationRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// reservedCapacity is the number of instances reserved and allocated for a VM size in a zone,
+// summed over the capacity reservations for them.
+type reservedCapacity struct {
+	reserved  int32
+	allocated int32
+	found     bool
+}
+
+// ReconcileCapacityReservationRule reconciles a capacity reservation rule.
+func (s *CapacityReservationRuleService) ReconcileCapacityReservationRule(rule v1alpha1.CapacityReservationRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "resourceGroup", rule.ResourceGroup, "capacityReservationGroup", rule.CapacityReservationGroup)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "All capacity reservations have enough unallocated capacity. For each reservation, allocated instances plus buffer falls within reserved instances."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeCapacityReservation
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	group, err := s.api.GetCapacityReservationGroup(rule.ResourceGroup, rule.CapacityReservationGroup, rule.SubscriptionID)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return validationResult, fmt.Errorf("capacity reservation group %s not found in resource group %s using subscription %s", rule.CapacityReservationGroup, rule.ResourceGroup, rule.SubscriptionID)
+		}
+		return validationResult, fmt.Errorf("failed to get capacity reservation group: %w", azerr.AsAugmented(err, capacityReservationRulePermissions))
+	}
+	reservations, err := s.api.GetCapacityReservations(rule.ResourceGroup, rule.CapacityReservationGroup, rule.SubscriptionID)
+	if err != nil {
+		return validationResult, fmt.Errorf("failed to get capacity reservations: %w", azerr.AsAugmented(err, capacityReservationRulePermissions))
+	}
+
+	// Utilization is only in the capacity reservation group's instance view, where it's keyed by
+	// capacity reservation name.
+	utilization := map[string]*armcompute.CapacityReservationUtilization{}
+	if group.Properties != nil && group.Properties.InstanceView != nil {
+		for _, iv := range group.Properties.InstanceView.CapacityReservations {
+			if iv == nil || iv.Name == nil {
+				continue
+			}
+			utilization[strings.ToLower(*iv.Name)] = iv.UtilizationInfo
+		}
+	}
+
+	for _, req := range rule.Reservations {
+		zones := req.Zones
+		if len(zones) == 0 {
+			zones = []string{""}
+		}
+		for _, zone := range zones {
+			c := sumReservedCapacity(reservations, utilization, req.VMSize, zone, log)
+			processReservedCapacity(c, req, zone, &latestCondition.Details, &latestCondition.Failures)
+		}
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "Allocated instances for one or more capacity reservations exceeded the reserved instances minus specified buffer."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// sumReservedCapacity sums the reserved and allocated instances of the capacity reservations for a
+// VM size in a zone, or of the regional capacity reservations for it when the zone is empty.
+func sumReservedCapacity(reservations []*armcompute.CapacityReservation, utilization map[string]*armcompute.CapacityReservationUtilization, vmSize, zone string, log logr.Logger) reservedCapacity {
+	c := reservedCapacity{}
+	for _, r := range reservations {
+		if r == nil || r.Name == nil || r.SKU == nil || r.SKU.Name == nil {
+			log.Error(nil, "Capacity reservation name or SKU in API response was nil.")
+			continue
+		}
+		if !strings.EqualFold(*r.SKU.Name, vmSize) {
+			continue
+		}
+		rZones := []string{}
+		for _, z := range r.Zones {
+			if z != nil {
+				rZones = append(rZones, *z)
+			}
+		}
+		if (zone == "" && len(rZones) > 0) || (zone != "" && !slices.Contains(rZones, zone)) {
+			continue
+		}
+		c.found = true
+
+		// The current capacity is what was actually reserved, which can be less than the SKU's
+		// capacity while a capacity reservation is being updated.
+		u := utilization[strings.ToLower(*r.Name)]
+		switch {
+		case u != nil && u.CurrentCapacity != nil:
+			c.reserved += *u.CurrentCapacity
+		case r.SKU.Capacity != nil:
+			c.reserved += int32(*r.SKU.Capacity)
+		}
+		if u != nil {
+			c.allocated += int32(len(u.VirtualMachinesAllocated))
+		}
+	}
+	return c
+}
+
+// processReservedCapacity checks the reserved capacity for a VM size in a zone against a capacity
+// reservation requirement. If there isn't enough unallocated capacity, it adds a failure.
+func processReservedCapacity(c reservedCapacity, req v1alpha1.CapacityReservationRequirement, zone string, details, failures *[]string) {
+	target := fmt.Sprintf("VM size %s in zone %s", req.VMSize, zone)
+	if zone == "" {
+		target = fmt.Sprintf("VM size %s (regional)", req.VMSize)
+	}
+
+	if !c.found {
+		*failures = append(*failures, fmt.Sprintf("No capacity reservation for %s.", target))
+		return
+	}
+
+	*details = append(*details, fmt.Sprintf("%s: reserved: %d, buffer: %d, allocated: %d", target, c.reserved, req.Buffer, c.allocated))
+	if remainder := c.reserved - c.allocated; remainder < req.Buffer {
+		*failures = append(*failures, fmt.Sprintf("Unallocated reserved capacity %d, less than buffer %d, for %s", remainder, req.Buffer, target))
+	}
+}
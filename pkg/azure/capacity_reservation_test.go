@@ -0,0 +1,181 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type capacityReservationAPIMock struct {
+	group        *armcompute.CapacityReservationGroup
+	reservations []*armcompute.CapacityReservation
+	err          error
+}
+
+func (m capacityReservationAPIMock) GetCapacityReservationGroup(_, _, _ string) (*armcompute.CapacityReservationGroup, error) {
+	return m.group, m.err
+}
+
+func (m capacityReservationAPIMock) GetCapacityReservations(_, _, _ string) ([]*armcompute.CapacityReservation, error) {
+	return m.reservations, m.err
+}
+
+func capacityReservation(name, vmSize string, capacity int64, zones ...string) *armcompute.CapacityReservation {
+	r := &armcompute.CapacityReservation{
+		Name: util.Ptr(name),
+		SKU:  &armcompute.SKU{Name: util.Ptr(vmSize), Capacity: util.Ptr(capacity)},
+	}
+	for _, z := range zones {
+		r.Zones = append(r.Zones, util.Ptr(z))
+	}
+	return r
+}
+
+func capacityReservationUtilization(name string, currentCapacity int32, allocated int) *armcompute.CapacityReservationInstanceViewWithName {
+	vms := []*armcompute.SubResourceReadOnly{}
+	for range allocated {
+		vms = append(vms, &armcompute.SubResourceReadOnly{})
+	}
+	return &armcompute.CapacityReservationInstanceViewWithName{
+		Name: util.Ptr(name),
+		UtilizationInfo: &armcompute.CapacityReservationUtilization{
+			CurrentCapacity:          util.Ptr(currentCapacity),
+			VirtualMachinesAllocated: vms,
+		},
+	}
+}
+
+func TestCapacityReservationRuleService_ReconcileCapacityReservationRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.CapacityReservationRule
+		apiMock        capacityReservationAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	apiMock := capacityReservationAPIMock{
+		group: &armcompute.CapacityReservationGroup{
+			Name: util.Ptr("crg"),
+			Properties: &armcompute.CapacityReservationGroupProperties{
+				InstanceView: &armcompute.CapacityReservationGroupInstanceView{
+					CapacityReservations: []*armcompute.CapacityReservationInstanceViewWithName{
+						capacityReservationUtilization("d4-zone-1", 5, 3),
+						capacityReservationUtilization("d4-zone-2", 4, 4),
+						capacityReservationUtilization("d8-regional", 2, 0),
+					},
+				},
+			},
+		},
+		reservations: []*armcompute.CapacityReservation{
+			capacityReservation("d4-zone-1", "Standard_D4s_v5", 5, "1"),
+			// Reserved capacity is lower than the SKU's capacity while the reservation is updated.
+			capacityReservation("d4-zone-2", "Standard_D4s_v5", 6, "2"),
+			capacityReservation("d8-regional", "Standard_D8s_v5", 2),
+		},
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (unallocated capacity within buffer)",
+			rule: v1alpha1.CapacityReservationRule{
+				RuleName:                 "rule-1",
+				ResourceGroup:            "rg",
+				CapacityReservationGroup: "crg",
+				Reservations: []v1alpha1.CapacityReservationRequirement{
+					{VMSize: "standard_d4s_v5", Zones: []string{"1"}, Buffer: 2},
+					{VMSize: "Standard_D8s_v5", Buffer: 2},
+				},
+				SubscriptionID: "sub",
+			},
+			apiMock:       apiMock,
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-capacity-reservation",
+					ValidationRule: "validation-rule-1",
+					Message:        "All capacity reservations have enough unallocated capacity. For each reservation, allocated instances plus buffer falls within reserved instances.",
+					Details: []string{
+						"VM size standard_d4s_v5 in zone 1: reserved: 5, buffer: 2, allocated: 3",
+						"VM size Standard_D8s_v5 (regional): reserved: 2, buffer: 2, allocated: 0",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (unallocated capacity less than buffer and missing reservations)",
+			rule: v1alpha1.CapacityReservationRule{
+				RuleName:                 "rule-1",
+				ResourceGroup:            "rg",
+				CapacityReservationGroup: "crg",
+				Reservations: []v1alpha1.CapacityReservationRequirement{
+					{VMSize: "Standard_D4s_v5", Zones: []string{"1", "2", "3"}, Buffer: 1},
+					{VMSize: "Standard_D4s_v5", Buffer: 1},
+				},
+				SubscriptionID: "sub",
+			},
+			apiMock:       apiMock,
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-capacity-reservation",
+					ValidationRule: "validation-rule-1",
+					Message:        "Allocated instances for one or more capacity reservations exceeded the reserved instances minus specified buffer.",
+					Details: []string{
+						"VM size Standard_D4s_v5 in zone 1: reserved: 5, buffer: 1, allocated: 3",
+						"VM size Standard_D4s_v5 in zone 2: reserved: 4, buffer: 1, allocated: 4",
+					},
+					Failures: []string{
+						"Unallocated reserved capacity 0, less than buffer 1, for VM size Standard_D4s_v5 in zone 2",
+						"No capacity reservation for VM size Standard_D4s_v5 in zone 3.",
+						"No capacity reservation for VM size Standard_D4s_v5 (regional).",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Error (capacity reservation group not found)",
+			rule: v1alpha1.CapacityReservationRule{
+				RuleName:                 "rule-1",
+				ResourceGroup:            "rg",
+				CapacityReservationGroup: "crg",
+				Reservations:             []v1alpha1.CapacityReservationRequirement{{VMSize: "Standard_D4s_v5", Buffer: 1}},
+				SubscriptionID:           "sub",
+			},
+			apiMock: capacityReservationAPIMock{
+				err: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("capacity reservation group crg not found in resource group rg using subscription sub"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-capacity-reservation",
+					ValidationRule: "validation-rule-1",
+					Message:        "All capacity reservations have enough unallocated capacity. For each reservation, allocated instances plus buffer falls within reserved instances.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		svc := NewCapacityReservationRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileCapacityReservationRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
@@ -3,7 +3,10 @@ package azure
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
 	"github.com/go-logr/logr"
@@ -11,16 +14,42 @@ import (
 
 	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
 	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
 	vapi "github.com/validator-labs/validator/api/v1alpha1"
 	vapiconstants "github.com/validator-labs/validator/pkg/constants"
 	vapitypes "github.com/validator-labs/validator/pkg/types"
 	"github.com/validator-labs/validator/pkg/util"
 )
 
+const (
+	// securityTypeFeature is the name of the gallery image feature that describes the security
+	// type of the image.
+	securityTypeFeature = "SecurityType"
+	// standardSecurityType is the security type of images without a security type feature.
+	standardSecurityType = "Standard"
+)
+
+// supportedSecurityTypes maps the security type features of images that support more than one
+// security type to the security types VMs created from them can use.
+var supportedSecurityTypes = map[string][]string{
+	"TrustedLaunchSupported":                  {standardSecurityType, "TrustedLaunch"},
+	"ConfidentialVmSupported":                 {standardSecurityType, "ConfidentialVM"},
+	"TrustedLaunchAndConfidentialVmSupported": {standardSecurityType, "TrustedLaunch", "ConfidentialVM"},
+}
+
+var (
+	communityGalleryImageRulePermissions = []string{
+		"Microsoft.Compute/locations/communityGalleries/images/read",
+		"Microsoft.Compute/locations/communityGalleries/images/versions/read",
+	}
+)
+
 // communityGalleryImageAPI contains methods that allow getting all the information we need for
 // community galleries and images within them.
 type communityGalleryImageAPI interface {
 	GetImagesForGallery(location, name, subscriptionID string) ([]*armcompute.CommunityGalleryImage, error)
+	GetImageVersion(location, name, image, version, subscriptionID string) (*armcompute.CommunityGalleryImageVersion, error)
+	GetImageVersions(location, name, image, subscriptionID string) ([]*armcompute.CommunityGalleryImageVersion, error)
 }
 
 // CommunityGalleryImageRuleService reconciles community gallery image rules.
@@ -41,7 +70,7 @@ func NewCommunityGalleryImageRuleService(api communityGalleryImageAPI, log logr.
 // ReconcileCommunityGalleryImageRule reconciles a community gallery image rule.
 func (s *CommunityGalleryImageRuleService) ReconcileCommunityGalleryImageRule(rule v1alpha1.CommunityGalleryImageRule) (*vapitypes.ValidationRuleResult, error) {
 
-	log := s.log.WithValues("rule", rule.Name(), "images", rule.Images, "gallery", rule.Gallery.Name, "location", rule.Gallery.Location, "subscription", rule.SubscriptionID)
+	log := s.log.WithValues("rule", rule.Name(), "images", rule.Images, "imageRequirements", len(rule.ImageRequirements), "gallery", rule.Gallery.Name, "location", rule.Gallery.Location, "subscription", rule.SubscriptionID)
 
 	// Build the default ValidationResult for this rule.
 	state := vapi.ValidationSucceeded
@@ -57,34 +86,215 @@ func (s *CommunityGalleryImageRuleService) ReconcileCommunityGalleryImageRule(ru
 
 	imagesInGallery, err := s.api.GetImagesForGallery(rule.Gallery.Location, rule.Gallery.Name, rule.SubscriptionID)
 	if err != nil {
-		if strings.Contains(err.Error(), "RESPONSE 404") {
+		if azerr.IsNotFound(err) {
 			return validationResult, fmt.Errorf("community gallery %s not found in location %s using subscription %s", rule.Gallery.Name, rule.Gallery.Location, rule.SubscriptionID)
 		}
-		return validationResult, fmt.Errorf("failed to get all images in community gallery: %w", err)
+		return validationResult, fmt.Errorf("failed to get all images in community gallery: %w", azerr.AsAugmented(err, communityGalleryImageRulePermissions))
 	}
-	images := map[string]bool{}
+	images := map[string]*armcompute.CommunityGalleryImage{}
 	for _, image := range imagesInGallery {
 		if image.Name == nil {
 			log.Error(nil, "Image name in API response was nil.")
 			continue
 		}
-		images[*image.Name] = true
+		images[*image.Name] = image
 	}
 
-	// Find out which of the images in the rule are not present in the gallery.
-	for _, ruleImageName := range rule.Images {
-		if _, ok := images[ruleImageName]; !ok {
-			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Image '%s' not present in community gallery.", ruleImageName))
-		} else {
-			latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Found image; Name: '%s'", ruleImageName))
+	// Find out which of the images in the rule are not present in the gallery, and for those that
+	// are, which of their required versions are unusable.
+	for _, ruleImage := range ruleImages(rule) {
+		image, ok := images[ruleImage.Name]
+		if !ok {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Image '%s' not present in community gallery.", ruleImage.Name))
+			continue
+		}
+		latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Found image; Name: '%s'", ruleImage.Name))
+		processImageProperties(ruleImage, image, &latestCondition.Failures)
+		if ruleImage.Version == "" {
+			continue
+		}
+		if err := s.processImageVersion(rule, ruleImage, &latestCondition.Failures, &latestCondition.Details, log); err != nil {
+			// Code this is returning to will take care of changing the validation result to a
+			// failed validation, using the error returned.
+			return validationResult, err
 		}
 	}
 
 	if len(latestCondition.Failures) > 0 {
 		state = vapi.ValidationFailed
-		latestCondition.Message = "Community gallery lacks one or more required images. See failures for details."
+		latestCondition.Message = "Community gallery lacks one or more required images or image versions, or images have unexpected properties. See failures for details."
 		latestCondition.Status = corev1.ConditionFalse
 	}
 
 	return validationResult, nil
 }
+
+// ruleImages returns the images a rule requires. Images only listed by name have no requirements
+// beyond being present. Images listed by name that also have requirements are only returned once.
+func ruleImages(rule v1alpha1.CommunityGalleryImageRule) []v1alpha1.CommunityGalleryImage {
+	images := []v1alpha1.CommunityGalleryImage{}
+	for _, name := range rule.Images {
+		if !slices.ContainsFunc(rule.ImageRequirements, func(i v1alpha1.CommunityGalleryImage) bool { return i.Name == name }) {
+			images = append(images, v1alpha1.CommunityGalleryImage{Name: name})
+		}
+	}
+	return append(images, rule.ImageRequirements...)
+}
+
+// processImageProperties checks that the properties of an image in the gallery match the
+// properties expected by the rule. Properties not specified in the rule are not checked.
+func processImageProperties(ruleImage v1alpha1.CommunityGalleryImage, image *armcompute.CommunityGalleryImage, failures *[]string) {
+	var osType, osState, hyperVGeneration, architecture string
+	securityType := standardSecurityType
+	if image.Properties != nil {
+		if image.Properties.OSType != nil {
+			osType = string(*image.Properties.OSType)
+		}
+		if image.Properties.OSState != nil {
+			osState = string(*image.Properties.OSState)
+		}
+		if image.Properties.HyperVGeneration != nil {
+			hyperVGeneration = string(*image.Properties.HyperVGeneration)
+		}
+		if image.Properties.Architecture != nil {
+			architecture = string(*image.Properties.Architecture)
+		}
+		for _, feature := range image.Properties.Features {
+			if feature != nil && feature.Name != nil && feature.Value != nil && strings.EqualFold(*feature.Name, securityTypeFeature) {
+				securityType = *feature.Value
+			}
+		}
+	}
+
+	expected := []struct {
+		property string
+		want     string
+		got      string
+	}{
+		{"OS type", ruleImage.OSType, osType},
+		{"OS state", ruleImage.OSState, osState},
+		{"Hyper-V generation", ruleImage.HyperVGeneration, hyperVGeneration},
+		{"architecture", ruleImage.Architecture, architecture},
+	}
+	for _, e := range expected {
+		if e.want != "" && !strings.EqualFold(e.want, e.got) {
+			*failures = append(*failures, fmt.Sprintf("Image '%s' has %s '%s', expected '%s'.", ruleImage.Name, e.property, e.got, e.want))
+		}
+	}
+	if ruleImage.SecurityType != "" && !supportsSecurityType(securityType, ruleImage.SecurityType) {
+		*failures = append(*failures, fmt.Sprintf("Image '%s' has security type '%s', which does not support '%s'.", ruleImage.Name, securityType, ruleImage.SecurityType))
+	}
+}
+
+// supportsSecurityType returns whether an image with the given security type feature value can be
+// used to create VMs with the wanted security type.
+func supportsSecurityType(securityType, want string) bool {
+	if strings.EqualFold(securityType, want) {
+		return true
+	}
+	for feature, supported := range supportedSecurityTypes {
+		if strings.EqualFold(feature, securityType) {
+			return slices.ContainsFunc(supported, func(s string) bool { return strings.EqualFold(s, want) })
+		}
+	}
+	return false
+}
+
+// processImageVersion checks the required version of an image from the rule in the location of the
+// gallery and in each of the image's regions. Community gallery image versions are only returned by
+// Azure for the locations they are published to, so each location is checked separately.
+func (s *CommunityGalleryImageRuleService) processImageVersion(rule v1alpha1.CommunityGalleryImageRule, image v1alpha1.CommunityGalleryImage, failures, details *[]string, log logr.Logger) error {
+	locations := []string{rule.Gallery.Location}
+	for _, region := range image.Regions {
+		if normalizeRegion(region) != normalizeRegion(rule.Gallery.Location) {
+			locations = append(locations, region)
+		}
+	}
+
+	for _, location := range locations {
+		var version *armcompute.CommunityGalleryImageVersion
+		if image.Version == latestImageVersion {
+			versions, err := s.api.GetImageVersions(location, rule.Gallery.Name, image.Name, rule.SubscriptionID)
+			if err != nil {
+				if azerr.IsNotFound(err) {
+					*failures = append(*failures, fmt.Sprintf("Image '%s' not published to location '%s'.", image.Name, location))
+					continue
+				}
+				return fmt.Errorf("failed to get versions of image in community gallery: %w", azerr.AsAugmented(err, communityGalleryImageRulePermissions))
+			}
+			version = latestCommunityGalleryImageVersion(versions, log)
+			if version == nil {
+				*failures = append(*failures, fmt.Sprintf("No latest version of image '%s' present in location '%s'.", image.Name, location))
+				continue
+			}
+		} else {
+			var err error
+			version, err = s.api.GetImageVersion(location, rule.Gallery.Name, image.Name, image.Version, rule.SubscriptionID)
+			if err != nil {
+				if azerr.IsNotFound(err) {
+					*failures = append(*failures, fmt.Sprintf("Version '%s' of image '%s' not present in location '%s'.", image.Version, image.Name, location))
+					continue
+				}
+				return fmt.Errorf("failed to get version of image in community gallery: %w", azerr.AsAugmented(err, communityGalleryImageRulePermissions))
+			}
+			if version.Properties != nil && version.Properties.ExcludeFromLatest != nil && *version.Properties.ExcludeFromLatest {
+				*failures = append(*failures, fmt.Sprintf("Version '%s' of image '%s' excluded from latest in location '%s'.", image.Version, image.Name, location))
+				continue
+			}
+		}
+
+		versionName := image.Version
+		if version.Name != nil {
+			versionName = *version.Name
+		}
+		if version.Properties != nil && version.Properties.EndOfLifeDate != nil && version.Properties.EndOfLifeDate.Before(time.Now()) {
+			*failures = append(*failures, fmt.Sprintf("Version '%s' of image '%s' past its end of life date %s in location '%s'.", versionName, image.Name, version.Properties.EndOfLifeDate.Format(time.RFC3339), location))
+			continue
+		}
+		*details = append(*details, fmt.Sprintf("Found image version; Name: '%s', Version: '%s', Location: '%s'", image.Name, versionName, location))
+	}
+
+	return nil
+}
+
+// latestCommunityGalleryImageVersion returns the version Azure uses when "latest" is requested for
+// a community gallery image, which is the highest version not excluded from latest. Returns nil if
+// there is no such version.
+func latestCommunityGalleryImageVersion(versions []*armcompute.CommunityGalleryImageVersion, log logr.Logger) *armcompute.CommunityGalleryImageVersion {
+	var latest *armcompute.CommunityGalleryImageVersion
+	for _, v := range versions {
+		if v == nil || v.Name == nil {
+			log.Error(nil, "Image version name in API response was nil.")
+			continue
+		}
+		if v.Properties != nil && v.Properties.ExcludeFromLatest != nil && *v.Properties.ExcludeFromLatest {
+			continue
+		}
+		if latest == nil || compareImageVersions(*v.Name, *latest.Name) > 0 {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// compareImageVersions compares two gallery image versions, which are in the form
+// MajorVersion.MinorVersion.Patch. Returns a negative number if a is lower than b, a positive
+// number if a is higher than b, and 0 if they are equal. Parts that aren't integers are compared
+// as 0.
+func compareImageVersions(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aPart, bPart int
+		if i < len(aParts) {
+			aPart, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bPart, _ = strconv.Atoi(bParts[i])
+		}
+		if aPart != bPart {
+			return aPart - bPart
+		}
+	}
+	return 0
+}
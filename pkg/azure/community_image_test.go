@@ -3,6 +3,7 @@ package azure
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
 	"github.com/go-logr/logr"
@@ -17,12 +18,59 @@ import (
 type apiMock struct {
 	data []*armcompute.CommunityGalleryImage
 	err  error
+	// keyed by location
+	versions    map[string][]*armcompute.CommunityGalleryImageVersion
+	versionsErr error
 }
 
 func (m apiMock) GetImagesForGallery(_, _, _ string) ([]*armcompute.CommunityGalleryImage, error) {
 	return m.data, m.err
 }
 
+func (m apiMock) GetImageVersion(location, _, _, version, _ string) (*armcompute.CommunityGalleryImageVersion, error) {
+	if m.versionsErr != nil {
+		return nil, m.versionsErr
+	}
+	for _, v := range m.versions[location] {
+		if *v.Name == version {
+			return v, nil
+		}
+	}
+	// Can be any error message, just has to have this as substring.
+	return nil, errors.New("RESPONSE 404")
+}
+
+func (m apiMock) GetImageVersions(location, _, _, _ string) ([]*armcompute.CommunityGalleryImageVersion, error) {
+	return m.versions[location], m.versionsErr
+}
+
+func communityGalleryImage(name string, osType armcompute.OperatingSystemTypes, osState armcompute.OperatingSystemStateTypes, hyperVGeneration armcompute.HyperVGeneration, architecture armcompute.Architecture, securityType string) *armcompute.CommunityGalleryImage {
+	features := []*armcompute.GalleryImageFeature{}
+	if securityType != "" {
+		features = append(features, &armcompute.GalleryImageFeature{Name: util.Ptr("SecurityType"), Value: util.Ptr(securityType)})
+	}
+	return &armcompute.CommunityGalleryImage{
+		Name: util.Ptr(name),
+		Properties: &armcompute.CommunityGalleryImageProperties{
+			OSType:           util.Ptr(osType),
+			OSState:          util.Ptr(osState),
+			HyperVGeneration: util.Ptr(hyperVGeneration),
+			Architecture:     util.Ptr(architecture),
+			Features:         features,
+		},
+	}
+}
+
+func communityGalleryImageVersion(name string, endOfLifeDate time.Time, excludeFromLatest bool) *armcompute.CommunityGalleryImageVersion {
+	return &armcompute.CommunityGalleryImageVersion{
+		Name: util.Ptr(name),
+		Properties: &armcompute.CommunityGalleryImageVersionProperties{
+			EndOfLifeDate:     util.Ptr(endOfLifeDate),
+			ExcludeFromLatest: util.Ptr(excludeFromLatest),
+		},
+	}
+}
+
 func TestCommunityGalleryImageRuleService_ReconcileCommunityGalleryImageRule(t *testing.T) {
 
 	type testCase struct {
@@ -33,6 +81,9 @@ func TestCommunityGalleryImageRuleService_ReconcileCommunityGalleryImageRule(t *
 		expectedResult vapitypes.ValidationRuleResult
 	}
 
+	endOfLifePast := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	endOfLifeFuture := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+
 	testCases := []testCase{
 		{
 			name: "Pass (required images present in community gallery - 1 image)",
@@ -126,7 +177,7 @@ func TestCommunityGalleryImageRuleService_ReconcileCommunityGalleryImageRule(t *
 				Condition: &vapi.ValidationCondition{
 					ValidationType: "azure-community-gallery-image",
 					ValidationRule: "validation-rule-1",
-					Message:        "Community gallery lacks one or more required images. See failures for details.",
+					Message:        "Community gallery lacks one or more required images or image versions, or images have unexpected properties. See failures for details.",
 					Details: []string{
 						"Found image; Name: 'image2'",
 					},
@@ -161,7 +212,7 @@ func TestCommunityGalleryImageRuleService_ReconcileCommunityGalleryImageRule(t *
 				Condition: &vapi.ValidationCondition{
 					ValidationType: "azure-community-gallery-image",
 					ValidationRule: "validation-rule-1",
-					Message:        "Community gallery lacks one or more required images. See failures for details.",
+					Message:        "Community gallery lacks one or more required images or image versions, or images have unexpected properties. See failures for details.",
 					Details:        []string{},
 					Failures: []string{
 						"Image 'image2' not present in community gallery.",
@@ -199,6 +250,273 @@ func TestCommunityGalleryImageRuleService_ReconcileCommunityGalleryImageRule(t *
 				State: util.Ptr(vapi.ValidationSucceeded),
 			},
 		},
+		{
+			name: "Pass (pinned and latest image versions usable in gallery location and regions)",
+			rule: v1alpha1.CommunityGalleryImageRule{
+				RuleName: "rule-1",
+				Gallery: v1alpha1.CommunityGallery{
+					Location: "westus",
+					Name:     "gallery1",
+				},
+				ImageRequirements: []v1alpha1.CommunityGalleryImage{
+					{Name: "image1", Version: "1.0.0", Regions: []string{"West US", "eastus"}},
+					{Name: "image2", Version: "latest"},
+				},
+				SubscriptionID: "sub",
+			},
+			apiMock: apiMock{
+				data: []*armcompute.CommunityGalleryImage{
+					{Name: util.Ptr("image1")},
+					{Name: util.Ptr("image2")},
+				},
+				versions: map[string][]*armcompute.CommunityGalleryImageVersion{
+					"westus": {
+						communityGalleryImageVersion("1.0.0", endOfLifeFuture, false),
+						communityGalleryImageVersion("1.10.0", endOfLifeFuture, false),
+						communityGalleryImageVersion("1.9.0", endOfLifePast, false),
+						communityGalleryImageVersion("2.0.0", endOfLifeFuture, true),
+					},
+					"eastus": {
+						communityGalleryImageVersion("1.0.0", endOfLifeFuture, false),
+					},
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-community-gallery-image",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required images present in community gallery.",
+					Details: []string{
+						"Found image; Name: 'image1'",
+						"Found image version; Name: 'image1', Version: '1.0.0', Location: 'westus'",
+						"Found image version; Name: 'image1', Version: '1.0.0', Location: 'eastus'",
+						"Found image; Name: 'image2'",
+						"Found image version; Name: 'image2', Version: '1.10.0', Location: 'westus'",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (image version missing in region, past end of life, excluded from latest, and no latest version)",
+			rule: v1alpha1.CommunityGalleryImageRule{
+				RuleName: "rule-1",
+				Gallery: v1alpha1.CommunityGallery{
+					Location: "westus",
+					Name:     "gallery1",
+				},
+				ImageRequirements: []v1alpha1.CommunityGalleryImage{
+					{Name: "image1", Version: "1.0.0", Regions: []string{"eastus"}},
+					{Name: "image2", Version: "2.0.0"},
+					{Name: "image3", Version: "latest", Regions: []string{"centralus"}},
+				},
+				SubscriptionID: "sub",
+			},
+			apiMock: apiMock{
+				data: []*armcompute.CommunityGalleryImage{
+					{Name: util.Ptr("image1")},
+					{Name: util.Ptr("image2")},
+					{Name: util.Ptr("image3")},
+				},
+				versions: map[string][]*armcompute.CommunityGalleryImageVersion{
+					"westus": {
+						communityGalleryImageVersion("1.0.0", endOfLifePast, false),
+						communityGalleryImageVersion("2.0.0", endOfLifeFuture, true),
+					},
+					"centralus": {
+						communityGalleryImageVersion("2.0.0", endOfLifeFuture, true),
+					},
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-community-gallery-image",
+					ValidationRule: "validation-rule-1",
+					Message:        "Community gallery lacks one or more required images or image versions, or images have unexpected properties. See failures for details.",
+					Details: []string{
+						"Found image; Name: 'image1'",
+						"Found image; Name: 'image2'",
+						"Found image; Name: 'image3'",
+					},
+					Failures: []string{
+						"Version '1.0.0' of image 'image1' past its end of life date 2000-01-01T00:00:00Z in location 'westus'.",
+						"Version '1.0.0' of image 'image1' not present in location 'eastus'.",
+						"Version '2.0.0' of image 'image2' excluded from latest in location 'westus'.",
+						"Version '1.0.0' of image 'image3' past its end of life date 2000-01-01T00:00:00Z in location 'westus'.",
+						"No latest version of image 'image3' present in location 'centralus'.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Pass (image properties match)",
+			rule: v1alpha1.CommunityGalleryImageRule{
+				RuleName: "rule-1",
+				Gallery: v1alpha1.CommunityGallery{
+					Location: "westus",
+					Name:     "gallery1",
+				},
+				ImageRequirements: []v1alpha1.CommunityGalleryImage{
+					{
+						Name:             "image1",
+						OSType:           "Linux",
+						OSState:          "Generalized",
+						HyperVGeneration: "V2",
+						Architecture:     "x64",
+						SecurityType:     "TrustedLaunch",
+					},
+					{
+						Name:         "image2",
+						SecurityType: "Standard",
+					},
+					{
+						Name:         "image3",
+						SecurityType: "ConfidentialVM",
+					},
+				},
+				SubscriptionID: "sub",
+			},
+			apiMock: apiMock{
+				data: []*armcompute.CommunityGalleryImage{
+					communityGalleryImage("image1", armcompute.OperatingSystemTypesLinux, armcompute.OperatingSystemStateTypesGeneralized, armcompute.HyperVGenerationV2, armcompute.ArchitectureX64, "TrustedLaunchSupported"),
+					communityGalleryImage("image2", armcompute.OperatingSystemTypesLinux, armcompute.OperatingSystemStateTypesGeneralized, armcompute.HyperVGenerationV1, armcompute.ArchitectureX64, ""),
+					communityGalleryImage("image3", armcompute.OperatingSystemTypesLinux, armcompute.OperatingSystemStateTypesGeneralized, armcompute.HyperVGenerationV2, armcompute.ArchitectureX64, "TrustedLaunchAndConfidentialVmSupported"),
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-community-gallery-image",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required images present in community gallery.",
+					Details: []string{
+						"Found image; Name: 'image1'",
+						"Found image; Name: 'image2'",
+						"Found image; Name: 'image3'",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (image properties do not match)",
+			rule: v1alpha1.CommunityGalleryImageRule{
+				RuleName: "rule-1",
+				Gallery: v1alpha1.CommunityGallery{
+					Location: "westus",
+					Name:     "gallery1",
+				},
+				ImageRequirements: []v1alpha1.CommunityGalleryImage{
+					{
+						Name:             "image1",
+						OSType:           "Windows",
+						OSState:          "Specialized",
+						HyperVGeneration: "V2",
+						Architecture:     "Arm64",
+						SecurityType:     "TrustedLaunch",
+					},
+				},
+				SubscriptionID: "sub",
+			},
+			apiMock: apiMock{
+				data: []*armcompute.CommunityGalleryImage{
+					communityGalleryImage("image1", armcompute.OperatingSystemTypesLinux, armcompute.OperatingSystemStateTypesGeneralized, armcompute.HyperVGenerationV1, armcompute.ArchitectureX64, ""),
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-community-gallery-image",
+					ValidationRule: "validation-rule-1",
+					Message:        "Community gallery lacks one or more required images or image versions, or images have unexpected properties. See failures for details.",
+					Details: []string{
+						"Found image; Name: 'image1'",
+					},
+					Failures: []string{
+						"Image 'image1' has OS type 'Linux', expected 'Windows'.",
+						"Image 'image1' has OS state 'Generalized', expected 'Specialized'.",
+						"Image 'image1' has Hyper-V generation 'V1', expected 'V2'.",
+						"Image 'image1' has architecture 'x64', expected 'Arm64'.",
+						"Image 'image1' has security type 'Standard', which does not support 'TrustedLaunch'.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (latest version of image not published to regions, image with requirements also listed by name)",
+			rule: v1alpha1.CommunityGalleryImageRule{
+				RuleName: "rule-1",
+				Gallery: v1alpha1.CommunityGallery{
+					Location: "westus",
+					Name:     "gallery1",
+				},
+				Images:            []string{"image1", "image2"},
+				ImageRequirements: []v1alpha1.CommunityGalleryImage{{Name: "image1", Version: "latest", Regions: []string{"eastus"}}},
+				SubscriptionID:    "sub",
+			},
+			apiMock: apiMock{
+				data: []*armcompute.CommunityGalleryImage{
+					{Name: util.Ptr("image1")},
+					{Name: util.Ptr("image2")},
+				},
+				versionsErr: errors.New("RESPONSE 404"),
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-community-gallery-image",
+					ValidationRule: "validation-rule-1",
+					Message:        "Community gallery lacks one or more required images or image versions, or images have unexpected properties. See failures for details.",
+					Details:        []string{"Found image; Name: 'image2'", "Found image; Name: 'image1'"},
+					Failures: []string{
+						"Image 'image1' not published to location 'westus'.",
+						"Image 'image1' not published to location 'eastus'.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (error getting image versions) - validation result remains passing, code returned to interprets error and changes result",
+			rule: v1alpha1.CommunityGalleryImageRule{
+				RuleName: "rule-1",
+				Gallery: v1alpha1.CommunityGallery{
+					Location: "westus",
+					Name:     "gallery1",
+				},
+				ImageRequirements: []v1alpha1.CommunityGalleryImage{{Name: "image1", Version: "latest"}},
+				SubscriptionID:    "sub",
+			},
+			apiMock: apiMock{
+				data: []*armcompute.CommunityGalleryImage{
+					{Name: util.Ptr("image1")},
+				},
+				versionsErr: errors.New("list versions failed"),
+			},
+			expectedError: errors.New("failed to get versions of image in community gallery: list versions failed"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-community-gallery-image",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required images present in community gallery.",
+					Details:        []string{"Found image; Name: 'image1'"},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,172 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/dns/armdns"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	// defaultDNSRecordType is the type of records the principal must be able to create when the
+	// rule doesn't specify any record types.
+	defaultDNSRecordType = "A"
+)
+
+var (
+	dnsZoneRulePermissions = []string{
+		"Microsoft.Network/dnsZones/read",
+		"Microsoft.Network/privateDnsZones/read",
+		"Microsoft.Network/privateDnsZones/virtualNetworkLinks/read",
+	}
+)
+
+// dnsZoneAPI contains methods that allow getting all the information we need for public and private
+// DNS zones and the virtual network links of private DNS zones.
+type dnsZoneAPI interface {
+	GetZone(resourceGroup, name, subscriptionID string) (*armdns.Zone, error)
+	GetPrivateZone(resourceGroup, name, subscriptionID string) (*armprivatedns.PrivateZone, error)
+	GetVirtualNetworkLinks(resourceGroup, zoneName, subscriptionID string) ([]*armprivatedns.VirtualNetworkLink, error)
+}
+
+// DNSZoneRuleService reconciles DNS zone rules.
+type DNSZoneRuleService struct {
+	api     dnsZoneAPI
+	rbacSvc *RBACRuleService
+	log     logr.Logger
+}
+
+// NewDNSZoneRuleService creates a new DNSZoneRuleService. Requires an Azure client facade that
+// supports getting public and private DNS zones and virtual network links, and an RBAC rule service
+// used to check whether principals can create records.
+func NewDNSZoneRuleService(api dnsZoneAPI, rbacSvc *RBACRuleService, log logr.Logger) *DNSZoneRuleService {
+	return &DNSZoneRuleService{
+		api:     api,
+		rbacSvc: rbacSvc,
+		log:     log,
+	}
+}
+
+// ReconcileDNSZoneRule reconciles a DNS zone rule.
+func (s *DNSZoneRuleService) ReconcileDNSZoneRule(rule v1alpha1.DNSZoneRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "zone", rule.Zone, "private", rule.Private, "resourceGroup", rule.ResourceGroup, "subscription", rule.SubscriptionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "DNS zone present and usable."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeDNSZone
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	var zoneID *string
+	var resourceType string
+	if rule.Private {
+		zone, err := s.api.GetPrivateZone(rule.ResourceGroup, rule.Zone, rule.SubscriptionID)
+		if err != nil {
+			if azerr.IsNotFound(err) {
+				return validationResult, fmt.Errorf("private DNS zone %s not found in resource group %s using subscription %s", rule.Zone, rule.ResourceGroup, rule.SubscriptionID)
+			}
+			return validationResult, fmt.Errorf("failed to get private DNS zone: %w", azerr.AsAugmented(err, dnsZoneRulePermissions))
+		}
+		zoneID = zone.ID
+		resourceType = "Microsoft.Network/privateDnsZones"
+		latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Found private DNS zone; Name: '%s'", rule.Zone))
+
+		if len(rule.VirtualNetworks) > 0 {
+			links, err := s.api.GetVirtualNetworkLinks(rule.ResourceGroup, rule.Zone, rule.SubscriptionID)
+			if err != nil {
+				return validationResult, fmt.Errorf("failed to get virtual network links: %w", azerr.AsAugmented(err, dnsZoneRulePermissions))
+			}
+			processVirtualNetworkLinks(rule.VirtualNetworks, links, &latestCondition.Failures, &latestCondition.Details, log)
+		}
+	} else {
+		zone, err := s.api.GetZone(rule.ResourceGroup, rule.Zone, rule.SubscriptionID)
+		if err != nil {
+			if azerr.IsNotFound(err) {
+				return validationResult, fmt.Errorf("DNS zone %s not found in resource group %s using subscription %s", rule.Zone, rule.ResourceGroup, rule.SubscriptionID)
+			}
+			return validationResult, fmt.Errorf("failed to get DNS zone: %w", azerr.AsAugmented(err, dnsZoneRulePermissions))
+		}
+		zoneID = zone.ID
+		resourceType = "Microsoft.Network/dnsZones"
+		latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Found DNS zone; Name: '%s'", rule.Zone))
+	}
+
+	if rule.PrincipalID != "" {
+		if zoneID == nil {
+			return validationResult, fmt.Errorf("DNS zone %s ID nil", rule.Zone)
+		}
+		recordTypes := rule.RecordTypes
+		if len(recordTypes) == 0 {
+			recordTypes = []string{defaultDNSRecordType}
+		}
+		set := v1alpha1.PermissionSet{Scope: *zoneID}
+		for _, t := range recordTypes {
+			set.Actions = append(set.Actions, v1alpha1.ActionStr(fmt.Sprintf("%s/%s/write", resourceType, t)))
+		}
+		setFailures := []string{}
+		if err := s.rbacSvc.processPermissionSet(set, rule.PrincipalID, &setFailures); err != nil {
+			// Code this is returning to will take care of changing the validation result to a
+			// failed validation, using the error returned.
+			return validationResult, err
+		}
+		for _, f := range setFailures {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Principal can't create records in DNS zone: %s", f))
+		}
+		if len(setFailures) == 0 {
+			latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Principal %s can create records in DNS zone; Record types: %s", rule.PrincipalID, strings.Join(recordTypes, ", ")))
+		}
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "DNS zone not linked to required virtual networks or principal can't create records in it. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// processVirtualNetworkLinks checks that a private DNS zone has a virtual network link to each
+// required virtual network and that the links have finished linking.
+func processVirtualNetworkLinks(virtualNetworks []string, links []*armprivatedns.VirtualNetworkLink, failures, details *[]string, log logr.Logger) {
+	linksByVNet := map[string]*armprivatedns.VirtualNetworkLink{}
+	for _, link := range links {
+		if link == nil || link.Properties == nil || link.Properties.VirtualNetwork == nil || link.Properties.VirtualNetwork.ID == nil {
+			log.Error(nil, "Virtual network link in API response was missing properties.")
+			continue
+		}
+		linksByVNet[strings.ToLower(strings.TrimSuffix(*link.Properties.VirtualNetwork.ID, "/"))] = link
+	}
+
+	for _, vnet := range virtualNetworks {
+		link, ok := linksByVNet[strings.ToLower(strings.TrimSuffix(vnet, "/"))]
+		if !ok {
+			*failures = append(*failures, fmt.Sprintf("Private DNS zone not linked to virtual network '%s'.", vnet))
+			continue
+		}
+		linkState := link.Properties.VirtualNetworkLinkState
+		if linkState != nil && *linkState != armprivatedns.VirtualNetworkLinkStateCompleted {
+			*failures = append(*failures, fmt.Sprintf("Link from private DNS zone to virtual network '%s' not completed; State: '%s'", vnet, *linkState))
+			continue
+		}
+		*details = append(*details, fmt.Sprintf("Found link to virtual network; Virtual network: '%s'", vnet))
+	}
+}
@@ -0,0 +1,236 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/dns/armdns"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type dnsZoneAPIMock struct {
+	zone           *armdns.Zone
+	zoneErr        error
+	privateZone    *armprivatedns.PrivateZone
+	privateZoneErr error
+	links          []*armprivatedns.VirtualNetworkLink
+}
+
+func (m dnsZoneAPIMock) GetZone(_, _, _ string) (*armdns.Zone, error) {
+	return m.zone, m.zoneErr
+}
+
+func (m dnsZoneAPIMock) GetPrivateZone(_, _, _ string) (*armprivatedns.PrivateZone, error) {
+	return m.privateZone, m.privateZoneErr
+}
+
+func (m dnsZoneAPIMock) GetVirtualNetworkLinks(_, _, _ string) ([]*armprivatedns.VirtualNetworkLink, error) {
+	return m.links, nil
+}
+
+func virtualNetworkLink(vnetID string, state armprivatedns.VirtualNetworkLinkState) *armprivatedns.VirtualNetworkLink {
+	return &armprivatedns.VirtualNetworkLink{
+		Properties: &armprivatedns.VirtualNetworkLinkProperties{
+			VirtualNetwork:          &armprivatedns.SubResource{ID: util.Ptr(vnetID)},
+			VirtualNetworkLinkState: util.Ptr(state),
+		},
+	}
+}
+
+func TestDNSZoneRuleService_ReconcileDNSZoneRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.DNSZoneRule
+		apiMock        dnsZoneAPIMock
+		rdAPIMock      roleDefinitionAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	vnet1 := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet1"
+	vnet2 := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet2"
+	vnet3 := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet3"
+
+	privateZone := &armprivatedns.PrivateZone{
+		ID: util.Ptr("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/privateDnsZones/example.internal"),
+	}
+
+	rdAPIMock := func(actions ...string) roleDefinitionAPIMock {
+		ptrs := []*string{}
+		for _, a := range actions {
+			ptrs = append(ptrs, util.Ptr(a))
+		}
+		return roleDefinitionAPIMock{
+			data: map[string]*armauthorization.RoleDefinition{
+				"role_id": {
+					Properties: &armauthorization.RoleDefinitionProperties{
+						Permissions: []*armauthorization.Permission{
+							{
+								Actions:        ptrs,
+								DataActions:    []*string{},
+								NotActions:     []*string{},
+								NotDataActions: []*string{},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (private DNS zone linked to virtual networks and principal can create records)",
+			rule: v1alpha1.DNSZoneRule{
+				RuleName:        "rule-1",
+				ResourceGroup:   "rg",
+				Zone:            "example.internal",
+				Private:         true,
+				VirtualNetworks: []string{vnet1, vnet2},
+				PrincipalID:     "p_id",
+				RecordTypes:     []string{"A", "CNAME"},
+				SubscriptionID:  "sub",
+			},
+			apiMock: dnsZoneAPIMock{
+				privateZone: privateZone,
+				links: []*armprivatedns.VirtualNetworkLink{
+					virtualNetworkLink(vnet1, armprivatedns.VirtualNetworkLinkStateCompleted),
+					virtualNetworkLink(vnet2, armprivatedns.VirtualNetworkLinkStateCompleted),
+				},
+			},
+			rdAPIMock:     rdAPIMock("Microsoft.Network/privateDnsZones/*"),
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-dns-zone",
+					ValidationRule: "validation-rule-1",
+					Message:        "DNS zone present and usable.",
+					Details: []string{
+						"Found private DNS zone; Name: 'example.internal'",
+						"Found link to virtual network; Virtual network: '" + vnet1 + "'",
+						"Found link to virtual network; Virtual network: '" + vnet2 + "'",
+						"Principal p_id can create records in DNS zone; Record types: A, CNAME",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (private DNS zone not linked to virtual networks and principal can't create records)",
+			rule: v1alpha1.DNSZoneRule{
+				RuleName:        "rule-1",
+				ResourceGroup:   "rg",
+				Zone:            "example.internal",
+				Private:         true,
+				VirtualNetworks: []string{vnet1, vnet2, vnet3},
+				PrincipalID:     "p_id",
+				SubscriptionID:  "sub",
+			},
+			apiMock: dnsZoneAPIMock{
+				privateZone: privateZone,
+				links: []*armprivatedns.VirtualNetworkLink{
+					virtualNetworkLink(vnet1, armprivatedns.VirtualNetworkLinkStateCompleted),
+					virtualNetworkLink(vnet2, armprivatedns.VirtualNetworkLinkStateInProgress),
+				},
+			},
+			rdAPIMock:     rdAPIMock("Microsoft.Network/privateDnsZones/read"),
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-dns-zone",
+					ValidationRule: "validation-rule-1",
+					Message:        "DNS zone not linked to required virtual networks or principal can't create records in it. See failures for details.",
+					Details: []string{
+						"Found private DNS zone; Name: 'example.internal'",
+						"Found link to virtual network; Virtual network: '" + vnet1 + "'",
+					},
+					Failures: []string{
+						"Link from private DNS zone to virtual network '" + vnet2 + "' not completed; State: 'InProgress'",
+						"Private DNS zone not linked to virtual network '" + vnet3 + "'.",
+						"Principal can't create records in DNS zone: Action Microsoft.Network/privateDnsZones/A/write unpermitted because no role assignment permits it.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Pass (public DNS zone present)",
+			rule: v1alpha1.DNSZoneRule{
+				RuleName:       "rule-1",
+				ResourceGroup:  "rg",
+				Zone:           "example.com",
+				SubscriptionID: "sub",
+			},
+			apiMock: dnsZoneAPIMock{
+				zone: &armdns.Zone{
+					ID: util.Ptr("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/dnsZones/example.com"),
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-dns-zone",
+					ValidationRule: "validation-rule-1",
+					Message:        "DNS zone present and usable.",
+					Details:        []string{"Found DNS zone; Name: 'example.com'"},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (error getting DNS zone) - validation result remains passing, code returned to interprets error and changes result",
+			rule: v1alpha1.DNSZoneRule{
+				RuleName:       "rule-1",
+				ResourceGroup:  "rg",
+				Zone:           "example.com",
+				SubscriptionID: "sub",
+			},
+			apiMock: dnsZoneAPIMock{
+				// Can be any error message, just has to have this as substring.
+				zoneErr: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("DNS zone example.com not found in resource group rg using subscription sub"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-dns-zone",
+					ValidationRule: "validation-rule-1",
+					Message:        "DNS zone present and usable.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		raAPIMock := roleAssignmentAPIMock{
+			data: []*armauthorization.RoleAssignment{
+				{
+					Properties: &armauthorization.RoleAssignmentProperties{
+						RoleDefinitionID: util.Ptr("role_id"),
+					},
+				},
+			},
+		}
+		rbacSvc := NewRBACRuleService(denyAssignmentAPIMock{}, raAPIMock, tc.rdAPIMock)
+		svc := NewDNSZoneRuleService(tc.apiMock, rbacSvc, logr.Logger{})
+		result, err := svc.ReconcileDNSZoneRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
@@ -0,0 +1,205 @@
+package azure
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	// activityLogRetention is how long the activity log retains events. Re-registrations of
+	// resource providers older than this can't be found.
+	activityLogRetention = 90 * 24 * time.Hour
+)
+
+var (
+	featureRegistrationRulePermissions = []string{
+		"Microsoft.Features/features/read",
+		"Microsoft.Features/featureProviders/subscriptionFeatureRegistrations/read",
+		"Microsoft.Resources/subscriptions/providers/read",
+		"Microsoft.Insights/eventtypes/values/read",
+	}
+)
+
+// featureAPI contains methods that allow getting all the information we need for preview feature
+// registrations.
+type featureAPI interface {
+	GetFeature(providerNamespace, featureName, subscriptionID string) (*azutils.Feature, error)
+	GetFeatureRegistration(providerNamespace, featureName, subscriptionID string) (*azutils.FeatureRegistration, error)
+	GetProviderEvents(providerNamespace, subscriptionID string, since time.Time) ([]*azutils.ActivityLogEvent, error)
+}
+
+// providerAPI contains methods that allow getting a resource provider.
+type providerAPI interface {
+	GetProvider(namespace, subscriptionID string) (*armresources.Provider, error)
+}
+
+// FeatureRegistrationRuleService reconciles feature registration rules.
+type FeatureRegistrationRuleService struct {
+	featureAPI  featureAPI
+	providerAPI providerAPI
+	log         logr.Logger
+}
+
+// NewFeatureRegistrationRuleService creates a new FeatureRegistrationRuleService. Requires an Azure
+// client facade that supports getting features, their registrations, and the activity log events of
+// resource providers, and one that supports getting resource providers.
+func NewFeatureRegistrationRuleService(featureAPI featureAPI, providerAPI providerAPI, log logr.Logger) *FeatureRegistrationRuleService {
+	return &FeatureRegistrationRuleService{
+		featureAPI:  featureAPI,
+		providerAPI: providerAPI,
+		log:         log,
+	}
+}
+
+// ReconcileFeatureRegistrationRule reconciles a feature registration rule.
+func (s *FeatureRegistrationRuleService) ReconcileFeatureRegistrationRule(rule v1alpha1.FeatureRegistrationRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "subscription", rule.SubscriptionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "All features are registered."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeFeatureRegistration
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	// Resource providers are checked once each, after all of their features. Each resource provider
+	// must have been re-registered after the latest registration of its features.
+	namespaces := []string{}
+	latestRegistrations := map[string]time.Time{}
+	latestFeatures := map[string]string{}
+
+	for _, f := range rule.Features {
+		namespace, featureName, ok := strings.Cut(f, "/")
+		if !ok {
+			return validationResult, fmt.Errorf("invalid feature %s; must be a resource provider namespace and feature name separated by a slash", f)
+		}
+		if !slices.Contains(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+
+		feature, err := s.featureAPI.GetFeature(namespace, featureName, rule.SubscriptionID)
+		if err != nil {
+			if azerr.IsNotFound(err) {
+				return validationResult, fmt.Errorf("feature %s not found using subscription %s", f, rule.SubscriptionID)
+			}
+			return validationResult, fmt.Errorf("failed to get feature: %w", azerr.AsAugmented(err, featureRegistrationRulePermissions))
+		}
+		if feature.Properties == nil || feature.Properties.State == nil {
+			log.Error(nil, "Feature state in API response was nil.", "feature", f)
+			continue
+		}
+		if *feature.Properties.State != "Registered" {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Feature %s is in state %s, expected Registered.", f, *feature.Properties.State))
+			continue
+		}
+
+		registration, err := s.featureAPI.GetFeatureRegistration(namespace, featureName, rule.SubscriptionID)
+		if err != nil && !azerr.IsNotFound(err) {
+			return validationResult, fmt.Errorf("failed to get feature registration: %w", azerr.AsAugmented(err, featureRegistrationRulePermissions))
+		}
+		registeredAt, ok := registrationDate(registration)
+		if err != nil || !ok {
+			latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Feature %s is registered, but when it was registered is unknown, so re-registration of resource provider %s wasn't checked for it.", f, namespace))
+			continue
+		}
+		latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Feature %s was registered at %s.", f, registeredAt.Format(time.RFC3339)))
+		if registeredAt.After(latestRegistrations[namespace]) {
+			latestRegistrations[namespace] = registeredAt
+			latestFeatures[namespace] = f
+		}
+	}
+
+	for _, namespace := range namespaces {
+		if err := s.processProvider(namespace, rule.SubscriptionID, latestRegistrations[namespace], latestFeatures[namespace], &latestCondition.Details, &latestCondition.Failures); err != nil {
+			return validationResult, err
+		}
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "One or more features aren't registered. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// processProvider checks that a resource provider is registered and, when the latest registration
+// of its features is known, that it was re-registered after it.
+func (s *FeatureRegistrationRuleService) processProvider(namespace, subscriptionID string, latestRegistration time.Time, latestFeature string, details, failures *[]string) error {
+	provider, err := s.providerAPI.GetProvider(namespace, subscriptionID)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return fmt.Errorf("resource provider %s not found using subscription %s", namespace, subscriptionID)
+		}
+		return fmt.Errorf("failed to get resource provider: %w", azerr.AsAugmented(err, featureRegistrationRulePermissions))
+	}
+	if provider.RegistrationState == nil || *provider.RegistrationState != "Registered" {
+		providerState := "unknown"
+		if provider.RegistrationState != nil {
+			providerState = *provider.RegistrationState
+		}
+		*failures = append(*failures, fmt.Sprintf("Resource provider %s is in state %s, expected Registered.", namespace, providerState))
+		return nil
+	}
+	if latestFeature == "" {
+		return nil
+	}
+
+	if time.Since(latestRegistration) > activityLogRetention {
+		*details = append(*details, fmt.Sprintf("Feature %s was registered more than 90 days ago, so re-registration of resource provider %s can't be checked in the activity log.", latestFeature, namespace))
+		return nil
+	}
+
+	events, err := s.featureAPI.GetProviderEvents(namespace, subscriptionID, latestRegistration)
+	if err != nil {
+		return fmt.Errorf("failed to get activity log events: %w", azerr.AsAugmented(err, featureRegistrationRulePermissions))
+	}
+	for _, e := range events {
+		if e == nil || e.OperationName == nil || e.OperationName.Value == nil || e.Status == nil || e.Status.Value == nil || e.EventTimestamp == nil {
+			continue
+		}
+		if strings.EqualFold(*e.OperationName.Value, namespace+"/register/action") && *e.Status.Value == "Succeeded" && e.EventTimestamp.After(latestRegistration) {
+			*details = append(*details, fmt.Sprintf("Resource provider %s was re-registered at %s.", namespace, e.EventTimestamp.UTC().Format(time.RFC3339)))
+			return nil
+		}
+	}
+	*failures = append(*failures, fmt.Sprintf("Resource provider %s wasn't re-registered after feature %s was registered. Re-register the resource provider to propagate the feature registration.", namespace, latestFeature))
+	return nil
+}
+
+// registrationDate parses the registration date of a feature registration. Returns false if the
+// registration or its registration date is missing or can't be parsed.
+func registrationDate(registration *azutils.FeatureRegistration) (time.Time, bool) {
+	if registration == nil || registration.Properties == nil || registration.Properties.RegistrationDate == nil {
+		return time.Time{}, false
+	}
+	// Dates without time zones are in UTC.
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.9999999"} {
+		if t, err := time.Parse(layout, *registration.Properties.RegistrationDate); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,277 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type featureAPIMock struct {
+	// Features and registrations are keyed by "namespace/name". Registrations missing from the map
+	// aren't found.
+	features      map[string]*azutils.Feature
+	registrations map[string]*azutils.FeatureRegistration
+	// events are keyed by resource provider namespace.
+	events map[string][]*azutils.ActivityLogEvent
+	err    error
+}
+
+func (m featureAPIMock) GetFeature(providerNamespace, featureName, _ string) (*azutils.Feature, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.features[providerNamespace+"/"+featureName], nil
+}
+
+func (m featureAPIMock) GetFeatureRegistration(providerNamespace, featureName, _ string) (*azutils.FeatureRegistration, error) {
+	r, ok := m.registrations[providerNamespace+"/"+featureName]
+	if !ok {
+		return nil, errors.New("RESPONSE 404")
+	}
+	return r, nil
+}
+
+func (m featureAPIMock) GetProviderEvents(providerNamespace, _ string, _ time.Time) ([]*azutils.ActivityLogEvent, error) {
+	return m.events[providerNamespace], nil
+}
+
+type providerAPIMock struct {
+	// states are keyed by resource provider namespace.
+	states map[string]string
+}
+
+func (m providerAPIMock) GetProvider(namespace, _ string) (*armresources.Provider, error) {
+	return &armresources.Provider{
+		Namespace:         util.Ptr(namespace),
+		RegistrationState: util.Ptr(m.states[namespace]),
+	}, nil
+}
+
+func feature(state string) *azutils.Feature {
+	return &azutils.Feature{Properties: &azutils.FeatureProperties{State: util.Ptr(state)}}
+}
+
+func featureRegistration(registeredAt string) *azutils.FeatureRegistration {
+	return &azutils.FeatureRegistration{
+		Properties: &azutils.FeatureRegistrationProperties{
+			State:            util.Ptr("Registered"),
+			RegistrationDate: util.Ptr(registeredAt),
+		},
+	}
+}
+
+func activityLogEvent(operation, status string, timestamp time.Time) *azutils.ActivityLogEvent {
+	return &azutils.ActivityLogEvent{
+		OperationName:  &azutils.LocalizableString{Value: util.Ptr(operation)},
+		Status:         &azutils.LocalizableString{Value: util.Ptr(status)},
+		EventTimestamp: util.Ptr(timestamp),
+	}
+}
+
+func TestFeatureRegistrationRuleService_ReconcileFeatureRegistrationRule(t *testing.T) {
+
+	type testCase struct {
+		name            string
+		rule            v1alpha1.FeatureRegistrationRule
+		featureAPIMock  featureAPIMock
+		providerAPIMock providerAPIMock
+		expectedError   error
+		expectedResult  vapitypes.ValidationRuleResult
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	registeredAt := now.Add(-48 * time.Hour)
+	reregisteredAt := now.Add(-24 * time.Hour)
+	longAgo := now.Add(-100 * 24 * time.Hour)
+
+	registeredProviders := providerAPIMock{
+		states: map[string]string{
+			"Microsoft.Compute":          "Registered",
+			"Microsoft.ContainerService": "Registered",
+		},
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (feature registered and resource provider re-registered afterwards)",
+			rule: v1alpha1.FeatureRegistrationRule{
+				RuleName:       "rule-1",
+				Features:       []string{"Microsoft.Compute/EncryptionAtHost"},
+				SubscriptionID: "sub",
+			},
+			featureAPIMock: featureAPIMock{
+				features: map[string]*azutils.Feature{"Microsoft.Compute/EncryptionAtHost": feature("Registered")},
+				registrations: map[string]*azutils.FeatureRegistration{
+					// Registration dates without time zones are in UTC.
+					"Microsoft.Compute/EncryptionAtHost": featureRegistration(registeredAt.Format("2006-01-02T15:04:05.0000000")),
+				},
+				events: map[string][]*azutils.ActivityLogEvent{
+					"Microsoft.Compute": {
+						activityLogEvent("Microsoft.Compute/register/action", "Started", reregisteredAt),
+						activityLogEvent("Microsoft.Compute/register/action", "Succeeded", reregisteredAt),
+					},
+				},
+			},
+			providerAPIMock: registeredProviders,
+			expectedError:   nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-feature-registration",
+					ValidationRule: "validation-rule-1",
+					Message:        "All features are registered.",
+					Details: []string{
+						"Feature Microsoft.Compute/EncryptionAtHost was registered at " + registeredAt.Format(time.RFC3339) + ".",
+						"Resource provider Microsoft.Compute was re-registered at " + reregisteredAt.Format(time.RFC3339) + ".",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Pass (re-registration can't be checked)",
+			rule: v1alpha1.FeatureRegistrationRule{
+				RuleName:       "rule-1",
+				Features:       []string{"Microsoft.Compute/EncryptionAtHost", "Microsoft.ContainerService/EnableWorkloadIdentityPreview"},
+				SubscriptionID: "sub",
+			},
+			featureAPIMock: featureAPIMock{
+				features: map[string]*azutils.Feature{
+					"Microsoft.Compute/EncryptionAtHost":                       feature("Registered"),
+					"Microsoft.ContainerService/EnableWorkloadIdentityPreview": feature("Registered"),
+				},
+				registrations: map[string]*azutils.FeatureRegistration{
+					"Microsoft.Compute/EncryptionAtHost": featureRegistration(longAgo.Format(time.RFC3339)),
+				},
+			},
+			providerAPIMock: registeredProviders,
+			expectedError:   nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-feature-registration",
+					ValidationRule: "validation-rule-1",
+					Message:        "All features are registered.",
+					Details: []string{
+						"Feature Microsoft.Compute/EncryptionAtHost was registered at " + longAgo.Format(time.RFC3339) + ".",
+						"Feature Microsoft.ContainerService/EnableWorkloadIdentityPreview is registered, but when it was registered is unknown, so re-registration of resource provider Microsoft.ContainerService wasn't checked for it.",
+						"Feature Microsoft.Compute/EncryptionAtHost was registered more than 90 days ago, so re-registration of resource provider Microsoft.Compute can't be checked in the activity log.",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (feature not registered and resource provider not re-registered)",
+			rule: v1alpha1.FeatureRegistrationRule{
+				RuleName:       "rule-1",
+				Features:       []string{"Microsoft.Compute/EncryptionAtHost", "Microsoft.ContainerService/EnableWorkloadIdentityPreview"},
+				SubscriptionID: "sub",
+			},
+			featureAPIMock: featureAPIMock{
+				features: map[string]*azutils.Feature{
+					"Microsoft.Compute/EncryptionAtHost":                       feature("Registered"),
+					"Microsoft.ContainerService/EnableWorkloadIdentityPreview": feature("NotRegistered"),
+				},
+				registrations: map[string]*azutils.FeatureRegistration{
+					"Microsoft.Compute/EncryptionAtHost": featureRegistration(reregisteredAt.Format(time.RFC3339)),
+				},
+				events: map[string][]*azutils.ActivityLogEvent{
+					"Microsoft.Compute": {
+						activityLogEvent("Microsoft.Compute/register/action", "Succeeded", registeredAt),
+						activityLogEvent("Microsoft.Compute/virtualMachines/write", "Succeeded", now),
+					},
+				},
+			},
+			providerAPIMock: registeredProviders,
+			expectedError:   nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-feature-registration",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more features aren't registered. See failures for details.",
+					Details: []string{
+						"Feature Microsoft.Compute/EncryptionAtHost was registered at " + reregisteredAt.Format(time.RFC3339) + ".",
+					},
+					Failures: []string{
+						"Feature Microsoft.ContainerService/EnableWorkloadIdentityPreview is in state NotRegistered, expected Registered.",
+						"Resource provider Microsoft.Compute wasn't re-registered after feature Microsoft.Compute/EncryptionAtHost was registered. Re-register the resource provider to propagate the feature registration.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (resource provider not registered)",
+			rule: v1alpha1.FeatureRegistrationRule{
+				RuleName:       "rule-1",
+				Features:       []string{"Microsoft.Compute/EncryptionAtHost"},
+				SubscriptionID: "sub",
+			},
+			featureAPIMock: featureAPIMock{
+				features: map[string]*azutils.Feature{"Microsoft.Compute/EncryptionAtHost": feature("Registered")},
+			},
+			providerAPIMock: providerAPIMock{
+				states: map[string]string{"Microsoft.Compute": "Registering"},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-feature-registration",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more features aren't registered. See failures for details.",
+					Details: []string{
+						"Feature Microsoft.Compute/EncryptionAtHost is registered, but when it was registered is unknown, so re-registration of resource provider Microsoft.Compute wasn't checked for it.",
+					},
+					Failures: []string{
+						"Resource provider Microsoft.Compute is in state Registering, expected Registered.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Error (feature not found)",
+			rule: v1alpha1.FeatureRegistrationRule{
+				RuleName:       "rule-1",
+				Features:       []string{"Microsoft.Compute/NoSuchFeature"},
+				SubscriptionID: "sub",
+			},
+			featureAPIMock: featureAPIMock{
+				err: errors.New("RESPONSE 404"),
+			},
+			providerAPIMock: registeredProviders,
+			expectedError:   errors.New("feature Microsoft.Compute/NoSuchFeature not found using subscription sub"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-feature-registration",
+					ValidationRule: "validation-rule-1",
+					Message:        "All features are registered.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		svc := NewFeatureRegistrationRuleService(tc.featureAPIMock, tc.providerAPIMock, logr.Logger{})
+		result, err := svc.ReconcileFeatureRegistrationRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
@@ -0,0 +1,175 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+var (
+	galleryImageRulePermissions = []string{
+		"Microsoft.Compute/galleries/images/read",
+		"Microsoft.Compute/galleries/images/versions/read",
+	}
+)
+
+// galleryImageAPI contains methods that allow getting all the information we need for galleries
+// and the image definitions and image versions within them.
+type galleryImageAPI interface {
+	GetImageDefinitions(resourceGroup, gallery, subscriptionID string) ([]*armcompute.GalleryImage, error)
+	GetImageVersion(resourceGroup, gallery, image, version, subscriptionID string) (*armcompute.GalleryImageVersion, error)
+}
+
+// GalleryImageRuleService reconciles gallery image rules.
+type GalleryImageRuleService struct {
+	api galleryImageAPI
+	log logr.Logger
+}
+
+// NewGalleryImageRuleService creates a new GalleryImageRuleService. Requires an Azure client
+// facade that supports getting all image definitions for a gallery and getting image versions.
+func NewGalleryImageRuleService(api galleryImageAPI, log logr.Logger) *GalleryImageRuleService {
+	return &GalleryImageRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileGalleryImageRule reconciles a gallery image rule.
+func (s *GalleryImageRuleService) ReconcileGalleryImageRule(rule v1alpha1.GalleryImageRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "gallery", rule.Gallery.Name, "resourceGroup", rule.Gallery.ResourceGroup, "subscription", rule.SubscriptionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "All required images present in gallery."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeGalleryImages
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	imagesInGallery, err := s.api.GetImageDefinitions(rule.Gallery.ResourceGroup, rule.Gallery.Name, rule.SubscriptionID)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return validationResult, fmt.Errorf("gallery %s not found in resource group %s using subscription %s", rule.Gallery.Name, rule.Gallery.ResourceGroup, rule.SubscriptionID)
+		}
+		return validationResult, fmt.Errorf("failed to get all images in gallery: %w", azerr.AsAugmented(err, galleryImageRulePermissions))
+	}
+	images := map[string]bool{}
+	for _, image := range imagesInGallery {
+		if image == nil || image.Name == nil {
+			log.Error(nil, "Image name in API response was nil.")
+			continue
+		}
+		images[*image.Name] = true
+	}
+
+	for _, ruleImage := range rule.Images {
+		if _, ok := images[ruleImage.Name]; !ok {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Image '%s' not present in gallery.", ruleImage.Name))
+			continue
+		}
+		if ruleImage.Version == "" {
+			latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Found image; Name: '%s'", ruleImage.Name))
+			continue
+		}
+		if err := s.processImageVersion(rule, ruleImage, &latestCondition.Failures, &latestCondition.Details); err != nil {
+			// Code this is returning to will take care of changing the validation result to a
+			// failed validation, using the error returned.
+			return validationResult, err
+		}
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "Gallery lacks one or more required images or image versions. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// processImageVersion checks that the image version of an image from the rule exists, was
+// provisioned successfully, and was replicated to each of the image's regions.
+func (s *GalleryImageRuleService) processImageVersion(rule v1alpha1.GalleryImageRule, image v1alpha1.GalleryImage, failures, details *[]string) error {
+	version, err := s.api.GetImageVersion(rule.Gallery.ResourceGroup, rule.Gallery.Name, image.Name, image.Version, rule.SubscriptionID)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			*failures = append(*failures, fmt.Sprintf("Version '%s' of image '%s' not present in gallery.", image.Version, image.Name))
+			return nil
+		}
+		return fmt.Errorf("failed to get image version: %w", azerr.AsAugmented(err, galleryImageRulePermissions))
+	}
+	if version.Properties == nil {
+		return fmt.Errorf("image version properties nil")
+	}
+
+	numFailures := len(*failures)
+
+	provisioningState := ""
+	if version.Properties.ProvisioningState != nil {
+		provisioningState = string(*version.Properties.ProvisioningState)
+	}
+	if provisioningState != string(armcompute.GalleryProvisioningStateSucceeded) {
+		*failures = append(*failures, fmt.Sprintf("Version '%s' of image '%s' has provisioning state '%s', expected '%s'.", image.Version, image.Name, provisioningState, armcompute.GalleryProvisioningStateSucceeded))
+	}
+
+	// Target regions use display names (e.g. "West US") while users usually specify regions by
+	// name (e.g. "westus"), so regions are compared in normalized form.
+	targetRegions := map[string]bool{}
+	if version.Properties.PublishingProfile != nil {
+		for _, region := range version.Properties.PublishingProfile.TargetRegions {
+			if region != nil && region.Name != nil {
+				targetRegions[normalizeRegion(*region.Name)] = true
+			}
+		}
+	}
+	replicationStates := map[string]string{}
+	if version.Properties.ReplicationStatus != nil {
+		for _, status := range version.Properties.ReplicationStatus.Summary {
+			if status != nil && status.Region != nil && status.State != nil {
+				replicationStates[normalizeRegion(*status.Region)] = string(*status.State)
+			}
+		}
+	}
+	for _, region := range image.Regions {
+		if !targetRegions[normalizeRegion(region)] {
+			*failures = append(*failures, fmt.Sprintf("Version '%s' of image '%s' not replicated to region '%s'.", image.Version, image.Name, region))
+			continue
+		}
+		replicationState, ok := replicationStates[normalizeRegion(region)]
+		if !ok {
+			replicationState = string(armcompute.ReplicationStateUnknown)
+		}
+		if replicationState != string(armcompute.ReplicationStateCompleted) {
+			*failures = append(*failures, fmt.Sprintf("Replication of version '%s' of image '%s' to region '%s' not completed; Replication state: '%s'", image.Version, image.Name, region, replicationState))
+		}
+	}
+
+	if len(*failures) == numFailures {
+		*details = append(*details, fmt.Sprintf("Found image version; Name: '%s', Version: '%s'", image.Name, image.Version))
+	}
+
+	return nil
+}
+
+// normalizeRegion converts an Azure region name or display name (e.g. "West US") to the form
+// Azure uses for region names (e.g. "westus").
+func normalizeRegion(region string) string {
+	return strings.ToLower(strings.ReplaceAll(region, " ", ""))
+}
@@ -0,0 +1,242 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type galleryImageAPIMock struct {
+	images    []*armcompute.GalleryImage
+	imagesErr error
+	// keyed by image name
+	versions    map[string]*armcompute.GalleryImageVersion
+	versionsErr error
+}
+
+func (m galleryImageAPIMock) GetImageDefinitions(_, _, _ string) ([]*armcompute.GalleryImage, error) {
+	return m.images, m.imagesErr
+}
+
+func (m galleryImageAPIMock) GetImageVersion(_, _, image, _, _ string) (*armcompute.GalleryImageVersion, error) {
+	return m.versions[image], m.versionsErr
+}
+
+func galleryImageVersion(provisioningState armcompute.GalleryProvisioningState, targetRegions []string, replicationStates map[string]armcompute.ReplicationState) *armcompute.GalleryImageVersion {
+	regions := []*armcompute.TargetRegion{}
+	for _, r := range targetRegions {
+		regions = append(regions, &armcompute.TargetRegion{Name: util.Ptr(r)})
+	}
+	summary := []*armcompute.RegionalReplicationStatus{}
+	for r, s := range replicationStates {
+		summary = append(summary, &armcompute.RegionalReplicationStatus{Region: util.Ptr(r), State: util.Ptr(s)})
+	}
+	return &armcompute.GalleryImageVersion{
+		Properties: &armcompute.GalleryImageVersionProperties{
+			ProvisioningState: util.Ptr(provisioningState),
+			PublishingProfile: &armcompute.GalleryImageVersionPublishingProfile{
+				TargetRegions: regions,
+			},
+			ReplicationStatus: &armcompute.ReplicationStatus{
+				Summary: summary,
+			},
+		},
+	}
+}
+
+func TestGalleryImageRuleService_ReconcileGalleryImageRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.GalleryImageRule
+		apiMock        galleryImageAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	gallery := v1alpha1.Gallery{
+		ResourceGroup: "rg1",
+		Name:          "gallery1",
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (image definition present and image version replicated to all regions)",
+			rule: v1alpha1.GalleryImageRule{
+				RuleName: "rule-1",
+				Gallery:  gallery,
+				Images: []v1alpha1.GalleryImage{
+					{
+						Name: "image1",
+					},
+					{
+						Name:    "image2",
+						Version: "1.0.0",
+						Regions: []string{"westus", "eastus"},
+					},
+				},
+				SubscriptionID: "sub",
+			},
+			apiMock: galleryImageAPIMock{
+				images: []*armcompute.GalleryImage{
+					{Name: util.Ptr("image1")},
+					{Name: util.Ptr("image2")},
+				},
+				versions: map[string]*armcompute.GalleryImageVersion{
+					"image2": galleryImageVersion(
+						armcompute.GalleryProvisioningStateSucceeded,
+						[]string{"West US", "East US"},
+						map[string]armcompute.ReplicationState{
+							"West US": armcompute.ReplicationStateCompleted,
+							"East US": armcompute.ReplicationStateCompleted,
+						},
+					),
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-gallery-image",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required images present in gallery.",
+					Details: []string{
+						"Found image; Name: 'image1'",
+						"Found image version; Name: 'image2', Version: '1.0.0'",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (image definition missing, image version not provisioned, not replicated, and still replicating)",
+			rule: v1alpha1.GalleryImageRule{
+				RuleName: "rule-1",
+				Gallery:  gallery,
+				Images: []v1alpha1.GalleryImage{
+					{
+						Name: "image1",
+					},
+					{
+						Name:    "image2",
+						Version: "1.0.0",
+						Regions: []string{"westus", "eastus", "centralus"},
+					},
+				},
+				SubscriptionID: "sub",
+			},
+			apiMock: galleryImageAPIMock{
+				images: []*armcompute.GalleryImage{
+					{},
+					{Name: util.Ptr("image2")},
+				},
+				versions: map[string]*armcompute.GalleryImageVersion{
+					"image2": galleryImageVersion(
+						armcompute.GalleryProvisioningStateUpdating,
+						[]string{"West US", "East US"},
+						map[string]armcompute.ReplicationState{
+							"West US": armcompute.ReplicationStateCompleted,
+							"East US": armcompute.ReplicationStateReplicating,
+						},
+					),
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-gallery-image",
+					ValidationRule: "validation-rule-1",
+					Message:        "Gallery lacks one or more required images or image versions. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Image 'image1' not present in gallery.",
+						"Version '1.0.0' of image 'image2' has provisioning state 'Updating', expected 'Succeeded'.",
+						"Replication of version '1.0.0' of image 'image2' to region 'eastus' not completed; Replication state: 'Replicating'",
+						"Version '1.0.0' of image 'image2' not replicated to region 'centralus'.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (image version not present in gallery)",
+			rule: v1alpha1.GalleryImageRule{
+				RuleName: "rule-1",
+				Gallery:  gallery,
+				Images: []v1alpha1.GalleryImage{
+					{
+						Name:    "image1",
+						Version: "1.0.0",
+					},
+				},
+				SubscriptionID: "sub",
+			},
+			apiMock: galleryImageAPIMock{
+				images: []*armcompute.GalleryImage{
+					{Name: util.Ptr("image1")},
+				},
+				// Can be any error message, just has to have this as substring.
+				versionsErr: errors.New("RESPONSE 404"),
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-gallery-image",
+					ValidationRule: "validation-rule-1",
+					Message:        "Gallery lacks one or more required images or image versions. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Version '1.0.0' of image 'image1' not present in gallery.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (gallery does not exist or is not accessible using subscription) - validation result remains passing, code returned to interprets error and changes result",
+			rule: v1alpha1.GalleryImageRule{
+				RuleName: "rule-1",
+				Gallery:  gallery,
+				Images: []v1alpha1.GalleryImage{
+					{
+						Name: "image1",
+					},
+				},
+				SubscriptionID: "sub",
+			},
+			apiMock: galleryImageAPIMock{
+				// Can be any error message, just has to have this as substring.
+				imagesErr: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("gallery gallery1 not found in resource group rg1 using subscription sub"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-gallery-image",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required images present in gallery.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewGalleryImageRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileGalleryImageRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
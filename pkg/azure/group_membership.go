@@ -0,0 +1,110 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+var (
+	// groupMembershipRulePermissions are Microsoft Graph application permissions, not Azure RBAC
+	// permissions.
+	groupMembershipRulePermissions = []string{
+		"GroupMember.Read.All",
+	}
+)
+
+// groupAPI contains methods that allow getting all the information we need for the Microsoft Entra
+// ID groups principals are members of.
+type groupAPI interface {
+	GetTransitiveGroups(principalID string) ([]*azutils.GraphGroup, error)
+}
+
+// GroupMembershipRuleService reconciles group membership rules.
+type GroupMembershipRuleService struct {
+	api groupAPI
+	log logr.Logger
+}
+
+// NewGroupMembershipRuleService creates a new GroupMembershipRuleService. Requires a Microsoft
+// Graph client facade that supports getting the groups a principal is a transitive member of.
+func NewGroupMembershipRuleService(api groupAPI, log logr.Logger) *GroupMembershipRuleService {
+	return &GroupMembershipRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileGroupMembershipRule reconciles a group membership rule.
+func (s *GroupMembershipRuleService) ReconcileGroupMembershipRule(rule v1alpha1.GroupMembershipRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "principalId", rule.PrincipalID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "Principal has expected group memberships."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeGroupMembership
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	groups, err := s.api.GetTransitiveGroups(rule.PrincipalID)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return validationResult, fmt.Errorf("principal %s not found", rule.PrincipalID)
+		}
+		return validationResult, fmt.Errorf("failed to get groups of principal: %w", azerr.AsAugmented(err, groupMembershipRulePermissions))
+	}
+
+	// Object IDs are GUIDs, so they're compared case-insensitively. The display names are only
+	// used to make failures easier to read.
+	displayNames := map[string]string{}
+	for _, g := range groups {
+		if g == nil || g.ID == nil {
+			log.Error(nil, "Group ID in API response was nil.")
+			continue
+		}
+		name := ""
+		if g.DisplayName != nil {
+			name = *g.DisplayName
+		}
+		displayNames[strings.ToLower(*g.ID)] = name
+	}
+
+	for _, groupID := range rule.MemberOf {
+		name, ok := displayNames[strings.ToLower(groupID)]
+		if !ok {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Principal %s isn't a member of group %s.", rule.PrincipalID, groupID))
+			continue
+		}
+		latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Principal %s is a member of group %s (%s).", rule.PrincipalID, groupID, name))
+	}
+	for _, groupID := range rule.NotMemberOf {
+		if name, ok := displayNames[strings.ToLower(groupID)]; ok {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Principal %s is a member of group %s (%s), but it must not be.", rule.PrincipalID, groupID, name))
+		}
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "Principal doesn't have expected group memberships. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
@@ -0,0 +1,125 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type groupAPIMock struct {
+	groups []*azutils.GraphGroup
+	err    error
+}
+
+func (m groupAPIMock) GetTransitiveGroups(_ string) ([]*azutils.GraphGroup, error) {
+	return m.groups, m.err
+}
+
+func TestGroupMembershipRuleService_ReconcileGroupMembershipRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.GroupMembershipRule
+		apiMock        groupAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	principalID := "d3c1c7e2-4c1a-4f4e-9a43-6f5c0d7e8a91"
+	deployersID := "5b7e2f1a-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+	operatorsID := "7c8d9e0f-1a2b-4c3d-9e4f-5a6b7c8d9e0f"
+	adminsID := "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"
+
+	apiMock := groupAPIMock{
+		groups: []*azutils.GraphGroup{
+			{ID: util.Ptr(deployersID), DisplayName: util.Ptr("Deployers")},
+			{ID: util.Ptr(adminsID), DisplayName: util.Ptr("Admins")},
+		},
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (principal is a member of required groups and not of forbidden groups)",
+			rule: v1alpha1.GroupMembershipRule{
+				RuleName:    "rule-1",
+				PrincipalID: principalID,
+				MemberOf:    []string{"5B7E2F1A-3C4D-4E5F-8A9B-0C1D2E3F4A5B"},
+				NotMemberOf: []string{operatorsID},
+			},
+			apiMock:       apiMock,
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-group-membership",
+					ValidationRule: "validation-rule-1",
+					Message:        "Principal has expected group memberships.",
+					Details:        []string{"Principal " + principalID + " is a member of group 5B7E2F1A-3C4D-4E5F-8A9B-0C1D2E3F4A5B (Deployers)."},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (principal isn't a member of a required group and is a member of a forbidden group)",
+			rule: v1alpha1.GroupMembershipRule{
+				RuleName:    "rule-1",
+				PrincipalID: principalID,
+				MemberOf:    []string{deployersID, operatorsID},
+				NotMemberOf: []string{adminsID},
+			},
+			apiMock:       apiMock,
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-group-membership",
+					ValidationRule: "validation-rule-1",
+					Message:        "Principal doesn't have expected group memberships. See failures for details.",
+					Details:        []string{"Principal " + principalID + " is a member of group " + deployersID + " (Deployers)."},
+					Failures: []string{
+						"Principal " + principalID + " isn't a member of group " + operatorsID + ".",
+						"Principal " + principalID + " is a member of group " + adminsID + " (Admins), but it must not be.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Error (principal not found)",
+			rule: v1alpha1.GroupMembershipRule{
+				RuleName:    "rule-1",
+				PrincipalID: principalID,
+				MemberOf:    []string{deployersID},
+			},
+			apiMock: groupAPIMock{
+				err: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("principal " + principalID + " not found"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-group-membership",
+					ValidationRule: "validation-rule-1",
+					Message:        "Principal has expected group memberships.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		svc := NewGroupMembershipRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileGroupMembershipRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
@@ -0,0 +1,288 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	keyVaultItemSecret      = "secret"
+	keyVaultItemKey         = "key"
+	keyVaultItemCertificate = "certificate"
+)
+
+var (
+	keyVaultRulePermissions = []string{
+		"Microsoft.KeyVault/vaults/read",
+		"Microsoft.KeyVault/vaults/secrets/readMetadata/action",
+		"Microsoft.KeyVault/vaults/keys/read",
+	}
+
+	// keyVaultItemDataActions are the data actions a principal needs to read each kind of key vault
+	// item when the vault uses Azure RBAC.
+	keyVaultItemDataActions = map[string]v1alpha1.ActionStr{
+		keyVaultItemSecret:      "Microsoft.KeyVault/vaults/secrets/getSecret/action",
+		keyVaultItemKey:         "Microsoft.KeyVault/vaults/keys/read",
+		keyVaultItemCertificate: "Microsoft.KeyVault/vaults/certificates/read",
+	}
+
+	// keyVaultItemScopes are the resource ID segments of each kind of key vault item, used to build
+	// the scopes role assignments are checked at when the vault uses Azure RBAC.
+	keyVaultItemScopes = map[string]string{
+		keyVaultItemSecret:      "secrets",
+		keyVaultItemKey:         "keys",
+		keyVaultItemCertificate: "certificates",
+	}
+)
+
+// keyVaultAPI contains methods that allow getting all the information we need for key vaults and
+// the secrets and keys within them.
+type keyVaultAPI interface {
+	GetVault(resourceGroup, name, subscriptionID string) (*armkeyvault.Vault, error)
+	GetSecretProperties(vaultURL string) ([]*azsecrets.SecretProperties, error)
+	GetKeyProperties(vaultURL string) ([]*azkeys.KeyProperties, error)
+}
+
+// keyVaultItem is a secret, key, or certificate in a key vault.
+type keyVaultItem struct {
+	enabled bool
+	expires *time.Time
+}
+
+// KeyVaultRuleService reconciles key vault rules.
+type KeyVaultRuleService struct {
+	api     keyVaultAPI
+	rbacSvc *RBACRuleService
+	log     logr.Logger
+}
+
+// NewKeyVaultRuleService creates a new KeyVaultRuleService. Requires an Azure client facade that
+// supports getting key vaults and the properties of their secrets and keys, and an RBAC rule
+// service used to check access to vaults that use Azure RBAC.
+func NewKeyVaultRuleService(api keyVaultAPI, rbacSvc *RBACRuleService, log logr.Logger) *KeyVaultRuleService {
+	return &KeyVaultRuleService{
+		api:     api,
+		rbacSvc: rbacSvc,
+		log:     log,
+	}
+}
+
+// ReconcileKeyVaultRule reconciles a key vault rule.
+func (s *KeyVaultRuleService) ReconcileKeyVaultRule(rule v1alpha1.KeyVaultRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "vault", rule.Vault, "resourceGroup", rule.ResourceGroup, "subscription", rule.SubscriptionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "Required secrets, keys, and certificates present in key vault and readable."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeKeyVault
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	vault, err := s.api.GetVault(rule.ResourceGroup, rule.Vault, rule.SubscriptionID)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return validationResult, fmt.Errorf("key vault %s not found in resource group %s using subscription %s", rule.Vault, rule.ResourceGroup, rule.SubscriptionID)
+		}
+		return validationResult, fmt.Errorf("failed to get key vault: %w", azerr.AsAugmented(err, keyVaultRulePermissions))
+	}
+	if vault.Properties == nil || vault.Properties.VaultURI == nil {
+		return validationResult, fmt.Errorf("key vault %s properties missing vault URI", rule.Vault)
+	}
+	vaultURL := *vault.Properties.VaultURI
+
+	// Certificates are checked using the secrets Key Vault manages for them, which have the same
+	// names and attributes as the certificates, so secrets are also listed when only certificates
+	// are required.
+	secrets := map[string]keyVaultItem{}
+	certificates := map[string]keyVaultItem{}
+	if len(rule.Secrets) > 0 || len(rule.Certificates) > 0 {
+		props, err := s.api.GetSecretProperties(vaultURL)
+		if err != nil {
+			return validationResult, fmt.Errorf("failed to list secrets in key vault: %w", azerr.AsAugmented(err, keyVaultRulePermissions))
+		}
+		for _, p := range props {
+			if p == nil || p.ID == nil {
+				log.Error(nil, "Secret ID in API response was nil.")
+				continue
+			}
+			item := keyVaultItem{}
+			if p.Attributes != nil {
+				item.enabled = p.Attributes.Enabled != nil && *p.Attributes.Enabled
+				item.expires = p.Attributes.Expires
+			}
+			name := strings.ToLower(p.ID.Name())
+			if p.Managed != nil && *p.Managed {
+				certificates[name] = item
+			} else {
+				secrets[name] = item
+			}
+		}
+	}
+	keys := map[string]keyVaultItem{}
+	if len(rule.Keys) > 0 {
+		props, err := s.api.GetKeyProperties(vaultURL)
+		if err != nil {
+			return validationResult, fmt.Errorf("failed to list keys in key vault: %w", azerr.AsAugmented(err, keyVaultRulePermissions))
+		}
+		for _, p := range props {
+			if p == nil || p.KID == nil {
+				log.Error(nil, "Key ID in API response was nil.")
+				continue
+			}
+			item := keyVaultItem{}
+			if p.Attributes != nil {
+				item.enabled = p.Attributes.Enabled != nil && *p.Attributes.Enabled
+				item.expires = p.Attributes.Expires
+			}
+			keys[strings.ToLower(p.KID.Name())] = item
+		}
+	}
+
+	processKeyVaultItems(keyVaultItemSecret, rule.Secrets, secrets, &latestCondition.Failures)
+	processKeyVaultItems(keyVaultItemKey, rule.Keys, keys, &latestCondition.Failures)
+	processKeyVaultItems(keyVaultItemCertificate, rule.Certificates, certificates, &latestCondition.Failures)
+
+	if rule.PrincipalID != "" {
+		if vault.Properties.EnableRbacAuthorization != nil && *vault.Properties.EnableRbacAuthorization {
+			if err := s.processRBACAccess(rule, vault, &latestCondition.Failures); err != nil {
+				// Code this is returning to will take care of changing the validation result to a
+				// failed validation, using the error returned.
+				return validationResult, err
+			}
+		} else {
+			processAccessPolicies(rule, vault.Properties.AccessPolicies, &latestCondition.Failures)
+		}
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "Key vault lacks required secrets, keys, or certificates, or principal can't read them. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// processKeyVaultItems checks that each required item of a kind is present in the key vault, is
+// enabled, and isn't expired.
+func processKeyVaultItems(kind string, required []string, present map[string]keyVaultItem, failures *[]string) {
+	now := time.Now()
+	for _, name := range required {
+		item, ok := present[strings.ToLower(name)]
+		if !ok {
+			*failures = append(*failures, fmt.Sprintf("%s '%s' not present in key vault.", capitalize(kind), name))
+			continue
+		}
+		if !item.enabled {
+			*failures = append(*failures, fmt.Sprintf("%s '%s' is disabled.", capitalize(kind), name))
+		}
+		if item.expires != nil && item.expires.Before(now) {
+			*failures = append(*failures, fmt.Sprintf("%s '%s' expired on %s.", capitalize(kind), name, item.expires.Format(time.RFC3339)))
+		}
+	}
+}
+
+// processRBACAccess checks that the principal from the rule has role assignments that permit it to
+// read each required item, for key vaults that use Azure RBAC. Role assignments are checked at the
+// scope of each item so that role assignments scoped to individual items are taken into account.
+func (s *KeyVaultRuleService) processRBACAccess(rule v1alpha1.KeyVaultRule, vault *armkeyvault.Vault, failures *[]string) error {
+	if vault.ID == nil {
+		return fmt.Errorf("key vault %s ID nil", rule.Vault)
+	}
+	itemsByKind := []struct {
+		kind  string
+		names []string
+	}{
+		{keyVaultItemSecret, rule.Secrets},
+		{keyVaultItemKey, rule.Keys},
+		{keyVaultItemCertificate, rule.Certificates},
+	}
+	for _, items := range itemsByKind {
+		for _, name := range items.names {
+			set := v1alpha1.PermissionSet{
+				DataActions: []v1alpha1.ActionStr{keyVaultItemDataActions[items.kind]},
+				Scope:       fmt.Sprintf("%s/%s/%s", *vault.ID, keyVaultItemScopes[items.kind], name),
+			}
+			setFailures := []string{}
+			if err := s.rbacSvc.processPermissionSet(set, rule.PrincipalID, &setFailures); err != nil {
+				return err
+			}
+			for _, f := range setFailures {
+				*failures = append(*failures, fmt.Sprintf("Principal can't read %s '%s': %s", items.kind, name, f))
+			}
+		}
+	}
+	return nil
+}
+
+// processAccessPolicies checks that the access policies of a key vault permit the principal from
+// the rule to get each kind of required item, for key vaults that don't use Azure RBAC.
+func processAccessPolicies(rule v1alpha1.KeyVaultRule, policies []*armkeyvault.AccessPolicyEntry, failures *[]string) {
+	permitted := map[string]bool{}
+	for _, p := range policies {
+		if p == nil || p.ObjectID == nil || p.Permissions == nil || !strings.EqualFold(*p.ObjectID, rule.PrincipalID) {
+			continue
+		}
+		if permitsGet(p.Permissions.Secrets, armkeyvault.SecretPermissionsGet, armkeyvault.SecretPermissionsAll) {
+			permitted[keyVaultItemSecret] = true
+		}
+		if permitsGet(p.Permissions.Keys, armkeyvault.KeyPermissionsGet, armkeyvault.KeyPermissionsAll) {
+			permitted[keyVaultItemKey] = true
+		}
+		if permitsGet(p.Permissions.Certificates, armkeyvault.CertificatePermissionsGet, armkeyvault.CertificatePermissionsAll) {
+			permitted[keyVaultItemCertificate] = true
+		}
+	}
+
+	required := map[string]int{
+		keyVaultItemSecret:      len(rule.Secrets),
+		keyVaultItemKey:         len(rule.Keys),
+		keyVaultItemCertificate: len(rule.Certificates),
+	}
+	for _, kind := range []string{keyVaultItemSecret, keyVaultItemKey, keyVaultItemCertificate} {
+		if required[kind] > 0 && !permitted[kind] {
+			*failures = append(*failures, fmt.Sprintf("No access policy permits principal '%s' to get %ss.", rule.PrincipalID, kind))
+		}
+	}
+}
+
+// permitsGet returns whether access policy permissions include the get or all permission. Key
+// Vault keeps the case permissions were written with (e.g. "Get" from the portal), so they're
+// compared case-insensitively.
+func permitsGet[T ~string](perms []*T, get, all T) bool {
+	for _, perm := range perms {
+		if perm != nil && (strings.EqualFold(string(*perm), string(get)) || strings.EqualFold(string(*perm), string(all))) {
+			return true
+		}
+	}
+	return false
+}
+
+// capitalize returns s with its first letter in upper case.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,282 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type keyVaultAPIMock struct {
+	vault    *armkeyvault.Vault
+	vaultErr error
+	secrets  []*azsecrets.SecretProperties
+	keys     []*azkeys.KeyProperties
+}
+
+func (m keyVaultAPIMock) GetVault(_, _, _ string) (*armkeyvault.Vault, error) {
+	return m.vault, m.vaultErr
+}
+
+func (m keyVaultAPIMock) GetSecretProperties(_ string) ([]*azsecrets.SecretProperties, error) {
+	return m.secrets, nil
+}
+
+func (m keyVaultAPIMock) GetKeyProperties(_ string) ([]*azkeys.KeyProperties, error) {
+	return m.keys, nil
+}
+
+func keyVault(rbac bool, policies ...*armkeyvault.AccessPolicyEntry) *armkeyvault.Vault {
+	return &armkeyvault.Vault{
+		ID: util.Ptr("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/vault"),
+		Properties: &armkeyvault.VaultProperties{
+			VaultURI:                util.Ptr("https://vault.vault.azure.net/"),
+			EnableRbacAuthorization: util.Ptr(rbac),
+			AccessPolicies:          policies,
+		},
+	}
+}
+
+func keyVaultSecret(name string, enabled, managed bool, expires *time.Time) *azsecrets.SecretProperties {
+	id := azsecrets.ID("https://vault.vault.azure.net/secrets/" + name + "/version")
+	return &azsecrets.SecretProperties{
+		ID:         &id,
+		Managed:    util.Ptr(managed),
+		Attributes: &azsecrets.SecretAttributes{Enabled: util.Ptr(enabled), Expires: expires},
+	}
+}
+
+func keyVaultKey(name string, enabled bool, expires *time.Time) *azkeys.KeyProperties {
+	id := azkeys.ID("https://vault.vault.azure.net/keys/" + name + "/version")
+	return &azkeys.KeyProperties{
+		KID:        &id,
+		Attributes: &azkeys.KeyAttributes{Enabled: util.Ptr(enabled), Expires: expires},
+	}
+}
+
+func TestKeyVaultRuleService_ReconcileKeyVaultRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.KeyVaultRule
+		apiMock        keyVaultAPIMock
+		raAPIMock      roleAssignmentAPIMock
+		rdAPIMock      roleDefinitionAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	past := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	future := time.Now().AddDate(1, 0, 0)
+
+	rule := v1alpha1.KeyVaultRule{
+		RuleName:       "rule-1",
+		ResourceGroup:  "rg",
+		Vault:          "vault",
+		Secrets:        []string{"secret-1"},
+		Keys:           []string{"key-1"},
+		Certificates:   []string{"cert-1"},
+		PrincipalID:    "p_id",
+		SubscriptionID: "sub",
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (items present and access policy permits principal to get them)",
+			rule: rule,
+			apiMock: keyVaultAPIMock{
+				vault: keyVault(false, &armkeyvault.AccessPolicyEntry{
+					ObjectID: util.Ptr("P_ID"),
+					Permissions: &armkeyvault.Permissions{
+						Secrets:      []*armkeyvault.SecretPermissions{util.Ptr(armkeyvault.SecretPermissionsGet)},
+						Keys:         []*armkeyvault.KeyPermissions{util.Ptr(armkeyvault.KeyPermissionsAll)},
+						Certificates: []*armkeyvault.CertificatePermissions{util.Ptr(armkeyvault.CertificatePermissionsGet)},
+					},
+				}),
+				secrets: []*azsecrets.SecretProperties{
+					keyVaultSecret("secret-1", true, false, &future),
+					keyVaultSecret("cert-1", true, true, nil),
+				},
+				keys: []*azkeys.KeyProperties{keyVaultKey("key-1", true, nil)},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-key-vault",
+					ValidationRule: "validation-rule-1",
+					Message:        "Required secrets, keys, and certificates present in key vault and readable.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Pass (access policy permissions in mixed case permit principal to get items)",
+			rule: rule,
+			apiMock: keyVaultAPIMock{
+				vault: keyVault(false, &armkeyvault.AccessPolicyEntry{
+					ObjectID: util.Ptr("p_id"),
+					Permissions: &armkeyvault.Permissions{
+						Secrets:      []*armkeyvault.SecretPermissions{util.Ptr(armkeyvault.SecretPermissions("List")), util.Ptr(armkeyvault.SecretPermissions("Get"))},
+						Keys:         []*armkeyvault.KeyPermissions{util.Ptr(armkeyvault.KeyPermissions("All"))},
+						Certificates: []*armkeyvault.CertificatePermissions{util.Ptr(armkeyvault.CertificatePermissions("GET"))},
+					},
+				}),
+				secrets: []*azsecrets.SecretProperties{
+					keyVaultSecret("secret-1", true, false, &future),
+					keyVaultSecret("cert-1", true, true, nil),
+				},
+				keys: []*azkeys.KeyProperties{keyVaultKey("key-1", true, nil)},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-key-vault",
+					ValidationRule: "validation-rule-1",
+					Message:        "Required secrets, keys, and certificates present in key vault and readable.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (items missing, disabled, or expired and access policies don't permit principal to get them)",
+			rule: rule,
+			apiMock: keyVaultAPIMock{
+				vault: keyVault(false, &armkeyvault.AccessPolicyEntry{
+					ObjectID: util.Ptr("p_id"),
+					Permissions: &armkeyvault.Permissions{
+						Secrets: []*armkeyvault.SecretPermissions{util.Ptr(armkeyvault.SecretPermissionsList)},
+						Keys:    []*armkeyvault.KeyPermissions{util.Ptr(armkeyvault.KeyPermissionsGet)},
+					},
+				}),
+				secrets: []*azsecrets.SecretProperties{
+					keyVaultSecret("secret-1", true, false, &past),
+					// Not managed, so not a certificate.
+					keyVaultSecret("cert-1", true, false, nil),
+				},
+				keys: []*azkeys.KeyProperties{keyVaultKey("key-1", false, nil)},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-key-vault",
+					ValidationRule: "validation-rule-1",
+					Message:        "Key vault lacks required secrets, keys, or certificates, or principal can't read them. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Secret 'secret-1' expired on 2020-01-01T00:00:00Z.",
+						"Key 'key-1' is disabled.",
+						"Certificate 'cert-1' not present in key vault.",
+						"No access policy permits principal 'p_id' to get secrets.",
+						"No access policy permits principal 'p_id' to get certificates.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (vault uses Azure RBAC and no role assignment permits principal to read secret)",
+			rule: v1alpha1.KeyVaultRule{
+				RuleName:       "rule-1",
+				ResourceGroup:  "rg",
+				Vault:          "vault",
+				Secrets:        []string{"secret-1"},
+				PrincipalID:    "p_id",
+				SubscriptionID: "sub",
+			},
+			apiMock: keyVaultAPIMock{
+				// Access policies are ignored when the vault uses Azure RBAC.
+				vault: keyVault(true, &armkeyvault.AccessPolicyEntry{
+					ObjectID: util.Ptr("p_id"),
+					Permissions: &armkeyvault.Permissions{
+						Secrets: []*armkeyvault.SecretPermissions{util.Ptr(armkeyvault.SecretPermissionsGet)},
+					},
+				}),
+				secrets: []*azsecrets.SecretProperties{keyVaultSecret("secret-1", true, false, nil)},
+			},
+			raAPIMock: roleAssignmentAPIMock{
+				data: []*armauthorization.RoleAssignment{
+					{
+						Properties: &armauthorization.RoleAssignmentProperties{
+							RoleDefinitionID: util.Ptr("role_id"),
+						},
+					},
+				},
+			},
+			rdAPIMock: roleDefinitionAPIMock{
+				data: map[string]*armauthorization.RoleDefinition{
+					"role_id": {
+						Properties: &armauthorization.RoleDefinitionProperties{
+							Permissions: []*armauthorization.Permission{
+								{
+									Actions:        []*string{},
+									DataActions:    []*string{util.Ptr("Microsoft.KeyVault/vaults/secrets/readMetadata/action")},
+									NotActions:     []*string{},
+									NotDataActions: []*string{},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-key-vault",
+					ValidationRule: "validation-rule-1",
+					Message:        "Key vault lacks required secrets, keys, or certificates, or principal can't read them. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Principal can't read secret 'secret-1': DataAction Microsoft.KeyVault/vaults/secrets/getSecret/action unpermitted because no role assignment permits it.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (error getting key vault) - validation result remains passing, code returned to interprets error and changes result",
+			rule: rule,
+			apiMock: keyVaultAPIMock{
+				// Can be any error message, just has to have this as substring.
+				vaultErr: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("key vault vault not found in resource group rg using subscription sub"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-key-vault",
+					ValidationRule: "validation-rule-1",
+					Message:        "Required secrets, keys, and certificates present in key vault and readable.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		rbacSvc := NewRBACRuleService(denyAssignmentAPIMock{}, tc.raAPIMock, tc.rdAPIMock)
+		svc := NewKeyVaultRuleService(tc.apiMock, rbacSvc, logr.Logger{})
+		result, err := svc.ReconcileKeyVaultRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
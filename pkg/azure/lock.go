@@ -0,0 +1,113 @@
+package azure
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+var (
+	lockRulePermissions = []string{
+		"Microsoft.Authorization/locks/read",
+	}
+
+	// lockBlockedOperations are the kinds of operations each management lock level blocks.
+	lockBlockedOperations = map[string][]string{
+		"CanNotDelete": {"Delete"},
+		"ReadOnly":     {"Write", "Delete"},
+	}
+)
+
+// lockAPI contains methods that allow getting all the information we need for management locks.
+type lockAPI interface {
+	GetLocksForScope(scope string) ([]*azutils.ManagementLock, error)
+}
+
+// LockRuleService reconciles lock rules.
+type LockRuleService struct {
+	api lockAPI
+	log logr.Logger
+}
+
+// NewLockRuleService creates a new LockRuleService. Requires an Azure client facade that supports
+// getting management locks.
+func NewLockRuleService(api lockAPI, log logr.Logger) *LockRuleService {
+	return &LockRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileLockRule reconciles a lock rule.
+func (s *LockRuleService) ReconcileLockRule(rule v1alpha1.LockRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "scope", rule.Scope)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "No management locks block operations."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeLock
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	locks, err := s.api.GetLocksForScope(rule.Scope)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return validationResult, fmt.Errorf("scope %s not found", rule.Scope)
+		}
+		return validationResult, fmt.Errorf("failed to get management locks for scope: %w", azerr.AsAugmented(err, lockRulePermissions))
+	}
+
+	for _, lock := range locks {
+		if lock == nil || lock.ID == nil || lock.Properties == nil || lock.Properties.Level == nil {
+			log.Error(nil, "Management lock in API response was missing properties.")
+			continue
+		}
+		processLock(lock, rule.Operations, &latestCondition.Failures)
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "One or more management locks block operations. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// processLock checks whether a management lock blocks any of the operations from the rule.
+func processLock(lock *azutils.ManagementLock, operations []string, failures *[]string) {
+	level := *lock.Properties.Level
+	blocked := []string{}
+	for _, op := range operations {
+		if slices.Contains(lockBlockedOperations[level], op) {
+			blocked = append(blocked, op)
+		}
+	}
+	if len(blocked) == 0 {
+		return
+	}
+
+	failure := fmt.Sprintf("Management lock %s with level %s blocks %s operations.", *lock.ID, level, strings.Join(blocked, " and "))
+	if lock.Properties.Notes != nil && *lock.Properties.Notes != "" {
+		failure += fmt.Sprintf(" Notes: '%s'.", *lock.Properties.Notes)
+	}
+	*failures = append(*failures, failure)
+}
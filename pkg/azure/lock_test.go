@@ -0,0 +1,156 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type lockAPIMock struct {
+	locks []*azutils.ManagementLock
+	err   error
+}
+
+func (m lockAPIMock) GetLocksForScope(_ string) ([]*azutils.ManagementLock, error) {
+	return m.locks, m.err
+}
+
+func managementLock(id, level, notes string) *azutils.ManagementLock {
+	return &azutils.ManagementLock{
+		ID: util.Ptr(id),
+		Properties: &azutils.ManagementLockProperties{
+			Level: util.Ptr(level),
+			Notes: util.Ptr(notes),
+		},
+	}
+}
+
+func TestLockRuleService_ReconcileLockRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.LockRule
+		apiMock        lockAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	subLock := "/subscriptions/sub/providers/Microsoft.Authorization/locks/no-delete"
+	rgLock := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Authorization/locks/freeze"
+
+	locks := []*azutils.ManagementLock{
+		managementLock(subLock, "CanNotDelete", ""),
+		managementLock(rgLock, "ReadOnly", "Change freeze"),
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (no management locks)",
+			rule: v1alpha1.LockRule{
+				RuleName:   "rule-1",
+				Scope:      "/subscriptions/sub/resourceGroups/rg",
+				Operations: []string{"Write", "Delete"},
+			},
+			apiMock:       lockAPIMock{},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-lock",
+					ValidationRule: "validation-rule-1",
+					Message:        "No management locks block operations.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Pass (CanNotDelete lock doesn't block writes)",
+			rule: v1alpha1.LockRule{
+				RuleName:   "rule-1",
+				Scope:      "/subscriptions/sub/resourceGroups/rg",
+				Operations: []string{"Write"},
+			},
+			apiMock: lockAPIMock{
+				locks: locks[:1],
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-lock",
+					ValidationRule: "validation-rule-1",
+					Message:        "No management locks block operations.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (CanNotDelete and ReadOnly locks block operations)",
+			rule: v1alpha1.LockRule{
+				RuleName:   "rule-1",
+				Scope:      "/subscriptions/sub/resourceGroups/rg",
+				Operations: []string{"Write", "Delete"},
+			},
+			apiMock: lockAPIMock{
+				locks: locks,
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-lock",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more management locks block operations. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Management lock " + subLock + " with level CanNotDelete blocks Delete operations.",
+						"Management lock " + rgLock + " with level ReadOnly blocks Write and Delete operations. Notes: 'Change freeze'.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (error getting management locks) - validation result remains passing, code returned to interprets error and changes result",
+			rule: v1alpha1.LockRule{
+				RuleName:   "rule-1",
+				Scope:      "/subscriptions/sub/resourceGroups/rg",
+				Operations: []string{"Write"},
+			},
+			apiMock: lockAPIMock{
+				// Can be any error message, just has to have this as substring.
+				err: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("scope /subscriptions/sub/resourceGroups/rg not found"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-lock",
+					ValidationRule: "validation-rule-1",
+					Message:        "No management locks block operations.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewLockRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileLockRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
@@ -0,0 +1,143 @@
+package azure
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	// defaultFederatedCredentialAudience is the audience Microsoft Entra ID recommends for federated
+	// identity credentials, used when the rule doesn't specify one.
+	defaultFederatedCredentialAudience = "api://AzureADTokenExchange"
+)
+
+var (
+	managedIdentityRulePermissions = []string{
+		"Microsoft.ManagedIdentity/userAssignedIdentities/read",
+		"Microsoft.ManagedIdentity/userAssignedIdentities/federatedIdentityCredentials/read",
+	}
+)
+
+// managedIdentityAPI contains methods that allow getting all the information we need for
+// user-assigned managed identities and their federated identity credentials.
+type managedIdentityAPI interface {
+	GetUserAssignedIdentity(resourceGroup, name, subscriptionID string) (*armmsi.Identity, error)
+	GetFederatedIdentityCredentials(resourceGroup, identityName, subscriptionID string) ([]*armmsi.FederatedIdentityCredential, error)
+}
+
+// ManagedIdentityRuleService reconciles managed identity rules.
+type ManagedIdentityRuleService struct {
+	api managedIdentityAPI
+	log logr.Logger
+}
+
+// NewManagedIdentityRuleService creates a new ManagedIdentityRuleService. Requires an Azure client
+// facade that supports getting user-assigned managed identities and their federated identity
+// credentials.
+func NewManagedIdentityRuleService(api managedIdentityAPI, log logr.Logger) *ManagedIdentityRuleService {
+	return &ManagedIdentityRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileManagedIdentityRule reconciles a managed identity rule.
+func (s *ManagedIdentityRuleService) ReconcileManagedIdentityRule(rule v1alpha1.ManagedIdentityRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "identity", rule.Identity, "resourceGroup", rule.ResourceGroup, "subscription", rule.SubscriptionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "Managed identity present with required federated identity credentials."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeManagedIdentity
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	if _, err := s.api.GetUserAssignedIdentity(rule.ResourceGroup, rule.Identity, rule.SubscriptionID); err != nil {
+		if azerr.IsNotFound(err) {
+			return validationResult, fmt.Errorf("managed identity %s not found in resource group %s using subscription %s", rule.Identity, rule.ResourceGroup, rule.SubscriptionID)
+		}
+		return validationResult, fmt.Errorf("failed to get managed identity: %w", azerr.AsAugmented(err, managedIdentityRulePermissions))
+	}
+
+	if len(rule.FederatedCredentials) > 0 {
+		credentials, err := s.api.GetFederatedIdentityCredentials(rule.ResourceGroup, rule.Identity, rule.SubscriptionID)
+		if err != nil {
+			return validationResult, fmt.Errorf("failed to get federated identity credentials: %w", azerr.AsAugmented(err, managedIdentityRulePermissions))
+		}
+		valid := []*armmsi.FederatedIdentityCredential{}
+		for _, c := range credentials {
+			if c == nil || c.Properties == nil || c.Properties.Issuer == nil || c.Properties.Subject == nil {
+				log.Error(nil, "Federated identity credential in API response was missing properties.")
+				continue
+			}
+			valid = append(valid, c)
+		}
+		for _, fc := range rule.FederatedCredentials {
+			processFederatedCredential(fc, valid, &latestCondition.Failures)
+		}
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "Managed identity lacks required federated identity credentials. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// processFederatedCredential checks that a federated identity credential from the rule matches one
+// of the managed identity's federated identity credentials. When none match, the failure describes
+// the credentials that have the same issuer, because a mistyped subject is the most common cause.
+func processFederatedCredential(fc v1alpha1.FederatedCredential, credentials []*armmsi.FederatedIdentityCredential, failures *[]string) {
+	audience := fc.Audience
+	if audience == "" {
+		audience = defaultFederatedCredentialAudience
+	}
+
+	sameIssuer := []string{}
+	for _, c := range credentials {
+		if *c.Properties.Issuer != fc.Issuer {
+			continue
+		}
+		audiences := []string{}
+		for _, a := range c.Properties.Audiences {
+			if a != nil {
+				audiences = append(audiences, *a)
+			}
+		}
+		if *c.Properties.Subject == fc.Subject && slices.Contains(audiences, audience) {
+			return
+		}
+		name := ""
+		if c.Name != nil {
+			name = *c.Name
+		}
+		sameIssuer = append(sameIssuer, fmt.Sprintf("'%s' (subject '%s', audiences %v)", name, *c.Properties.Subject, audiences))
+	}
+
+	failure := fmt.Sprintf("No federated identity credential matches issuer '%s', subject '%s', and audience '%s'.", fc.Issuer, fc.Subject, audience)
+	if len(sameIssuer) > 0 {
+		failure += fmt.Sprintf(" Federated identity credentials with the same issuer: %s.", strings.Join(sameIssuer, ", "))
+	}
+	*failures = append(*failures, failure)
+}
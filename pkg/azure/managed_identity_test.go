@@ -0,0 +1,150 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type managedIdentityAPIMock struct {
+	identityErr error
+	credentials []*armmsi.FederatedIdentityCredential
+}
+
+func (m managedIdentityAPIMock) GetUserAssignedIdentity(_, _, _ string) (*armmsi.Identity, error) {
+	return &armmsi.Identity{}, m.identityErr
+}
+
+func (m managedIdentityAPIMock) GetFederatedIdentityCredentials(_, _, _ string) ([]*armmsi.FederatedIdentityCredential, error) {
+	return m.credentials, nil
+}
+
+func federatedIdentityCredential(name, issuer, subject string, audiences ...string) *armmsi.FederatedIdentityCredential {
+	a := []*string{}
+	for _, aud := range audiences {
+		a = append(a, util.Ptr(aud))
+	}
+	return &armmsi.FederatedIdentityCredential{
+		Name: util.Ptr(name),
+		Properties: &armmsi.FederatedIdentityCredentialProperties{
+			Issuer:    util.Ptr(issuer),
+			Subject:   util.Ptr(subject),
+			Audiences: a,
+		},
+	}
+}
+
+func TestManagedIdentityRuleService_ReconcileManagedIdentityRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.ManagedIdentityRule
+		apiMock        managedIdentityAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	issuer := "https://eastus.oic.prod-aks.azure.com/tenant/cluster/"
+
+	rule := v1alpha1.ManagedIdentityRule{
+		RuleName:      "rule-1",
+		ResourceGroup: "rg",
+		Identity:      "identity",
+		FederatedCredentials: []v1alpha1.FederatedCredential{
+			{
+				Issuer:  issuer,
+				Subject: "system:serviceaccount:validator:validator-plugin-azure",
+			},
+			{
+				Issuer:   "https://token.actions.githubusercontent.com",
+				Subject:  "repo:org/repo:ref:refs/heads/main",
+				Audience: "custom",
+			},
+		},
+		SubscriptionID: "sub",
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (managed identity has matching federated identity credentials)",
+			rule: rule,
+			apiMock: managedIdentityAPIMock{
+				credentials: []*armmsi.FederatedIdentityCredential{
+					federatedIdentityCredential("fic-1", issuer, "system:serviceaccount:validator:validator-plugin-azure", "api://AzureADTokenExchange"),
+					federatedIdentityCredential("fic-2", "https://token.actions.githubusercontent.com", "repo:org/repo:ref:refs/heads/main", "custom"),
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-managed-identity",
+					ValidationRule: "validation-rule-1",
+					Message:        "Managed identity present with required federated identity credentials.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (federated identity credential subject mistyped and audience mismatched)",
+			rule: rule,
+			apiMock: managedIdentityAPIMock{
+				credentials: []*armmsi.FederatedIdentityCredential{
+					federatedIdentityCredential("fic-1", issuer, "system:serviceaccount:validator:validator-plugin-azur", "api://AzureADTokenExchange"),
+					federatedIdentityCredential("fic-2", "https://token.actions.githubusercontent.com", "repo:org/repo:ref:refs/heads/main", "api://AzureADTokenExchange"),
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-managed-identity",
+					ValidationRule: "validation-rule-1",
+					Message:        "Managed identity lacks required federated identity credentials. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"No federated identity credential matches issuer '" + issuer + "', subject 'system:serviceaccount:validator:validator-plugin-azure', and audience 'api://AzureADTokenExchange'. Federated identity credentials with the same issuer: 'fic-1' (subject 'system:serviceaccount:validator:validator-plugin-azur', audiences [api://AzureADTokenExchange]).",
+						"No federated identity credential matches issuer 'https://token.actions.githubusercontent.com', subject 'repo:org/repo:ref:refs/heads/main', and audience 'custom'. Federated identity credentials with the same issuer: 'fic-2' (subject 'repo:org/repo:ref:refs/heads/main', audiences [api://AzureADTokenExchange]).",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (error getting managed identity) - validation result remains passing, code returned to interprets error and changes result",
+			rule: rule,
+			apiMock: managedIdentityAPIMock{
+				// Can be any error message, just has to have this as substring.
+				identityErr: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("managed identity identity not found in resource group rg using subscription sub"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-managed-identity",
+					ValidationRule: "validation-rule-1",
+					Message:        "Managed identity present with required federated identity credentials.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewManagedIdentityRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileManagedIdentityRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
@@ -0,0 +1,142 @@
+package azure
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	// latestImageVersion is the image version that refers to the latest version of an image.
+	latestImageVersion = "latest"
+)
+
+var (
+	marketplaceImageRulePermissions = []string{
+		"Microsoft.MarketplaceOrdering/offerTypes/publishers/offers/plans/agreements/read",
+		"Microsoft.Compute/locations/publishers/artifacttypes/offers/skus/versions/read",
+	}
+)
+
+// marketplaceImageAPI contains methods that allow getting all the information we need for
+// marketplace image agreements and versions.
+type marketplaceImageAPI interface {
+	GetAgreement(publisher, offer, plan, subscriptionID string) (*azutils.MarketplaceAgreement, error)
+	GetImageVersions(location, publisher, offer, sku, subscriptionID string) ([]*armcompute.VirtualMachineImageResource, error)
+}
+
+// MarketplaceImageRuleService reconciles marketplace image rules.
+type MarketplaceImageRuleService struct {
+	api marketplaceImageAPI
+	log logr.Logger
+}
+
+// NewMarketplaceImageRuleService creates a new MarketplaceImageRuleService. Requires an Azure
+// client facade that supports getting marketplace agreements and image versions.
+func NewMarketplaceImageRuleService(api marketplaceImageAPI, log logr.Logger) *MarketplaceImageRuleService {
+	return &MarketplaceImageRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileMarketplaceImageRule reconciles a marketplace image rule.
+func (s *MarketplaceImageRuleService) ReconcileMarketplaceImageRule(rule v1alpha1.MarketplaceImageRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "location", rule.Location, "subscription", rule.SubscriptionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "All required marketplace image terms accepted and images present in location."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeMarketplaceImage
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	for _, image := range rule.Images {
+		if err := s.processImage(image, rule.Location, rule.SubscriptionID, &latestCondition.Failures, &latestCondition.Details, log); err != nil {
+			// Code this is returning to will take care of changing the validation result to a
+			// failed validation, using the error returned.
+			return validationResult, err
+		}
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "One or more required marketplace images unusable in subscription. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// processImage checks the terms agreement and versions of a marketplace image from the rule.
+func (s *MarketplaceImageRuleService) processImage(image v1alpha1.MarketplaceImage, location, subscriptionID string, failures, details *[]string, log logr.Logger) error {
+	urn := fmt.Sprintf("%s:%s:%s", image.Publisher, image.Offer, image.Plan)
+
+	agreement, err := s.api.GetAgreement(image.Publisher, image.Offer, image.Plan, subscriptionID)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			*failures = append(*failures, fmt.Sprintf("Marketplace plan '%s' not found.", urn))
+			return nil
+		}
+		return fmt.Errorf("failed to get marketplace agreement: %w", azerr.AsAugmented(err, marketplaceImageRulePermissions))
+	}
+	if agreement.Properties == nil || agreement.Properties.Accepted == nil || !*agreement.Properties.Accepted {
+		*failures = append(*failures, fmt.Sprintf("Terms for marketplace plan '%s' not accepted in subscription.", urn))
+	} else {
+		*details = append(*details, fmt.Sprintf("Found accepted terms for marketplace plan; Plan: '%s'", urn))
+	}
+
+	sku := image.SKU
+	if sku == "" {
+		sku = image.Plan
+	}
+	version := image.Version
+	if version == "" {
+		version = latestImageVersion
+	}
+	imageURN := fmt.Sprintf("%s:%s:%s:%s", image.Publisher, image.Offer, sku, version)
+
+	versions, err := s.api.GetImageVersions(location, image.Publisher, image.Offer, sku, subscriptionID)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			*failures = append(*failures, fmt.Sprintf("Image '%s' not present in location '%s'.", imageURN, location))
+			return nil
+		}
+		return fmt.Errorf("failed to get marketplace image versions: %w", azerr.AsAugmented(err, marketplaceImageRulePermissions))
+	}
+	found := false
+	for _, v := range versions {
+		if v == nil || v.Name == nil {
+			log.Error(nil, "Image version name in API response was nil.")
+			continue
+		}
+		if version == latestImageVersion || *v.Name == version {
+			found = true
+			break
+		}
+	}
+	if !found {
+		*failures = append(*failures, fmt.Sprintf("Image '%s' not present in location '%s'.", imageURN, location))
+		return nil
+	}
+	*details = append(*details, fmt.Sprintf("Found image; URN: '%s'", imageURN))
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type marketplaceImageAPIMock struct {
+	// keyed by plan
+	agreements   map[string]*azutils.MarketplaceAgreement
+	agreementErr error
+	// keyed by sku
+	versions   map[string][]*armcompute.VirtualMachineImageResource
+	versionErr error
+}
+
+func (m marketplaceImageAPIMock) GetAgreement(_, _, plan, _ string) (*azutils.MarketplaceAgreement, error) {
+	return m.agreements[plan], m.agreementErr
+}
+
+func (m marketplaceImageAPIMock) GetImageVersions(_, _, _, sku, _ string) ([]*armcompute.VirtualMachineImageResource, error) {
+	return m.versions[sku], m.versionErr
+}
+
+func TestMarketplaceImageRuleService_ReconcileMarketplaceImageRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.MarketplaceImageRule
+		apiMock        marketplaceImageAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (terms accepted and pinned and latest image versions present)",
+			rule: v1alpha1.MarketplaceImageRule{
+				RuleName: "rule-1",
+				Location: "westus",
+				Images: []v1alpha1.MarketplaceImage{
+					{
+						Publisher: "pub",
+						Offer:     "offer",
+						Plan:      "plan1",
+						Version:   "1.0.0",
+					},
+					{
+						Publisher: "pub",
+						Offer:     "offer",
+						Plan:      "plan2",
+						SKU:       "sku2",
+					},
+				},
+				SubscriptionID: "sub",
+			},
+			apiMock: marketplaceImageAPIMock{
+				agreements: map[string]*azutils.MarketplaceAgreement{
+					"plan1": {Properties: &azutils.MarketplaceAgreementProperties{Accepted: util.Ptr(true)}},
+					"plan2": {Properties: &azutils.MarketplaceAgreementProperties{Accepted: util.Ptr(true)}},
+				},
+				versions: map[string][]*armcompute.VirtualMachineImageResource{
+					"plan1": {{Name: util.Ptr("0.9.0")}, {Name: util.Ptr("1.0.0")}},
+					"sku2":  {{Name: util.Ptr("2.0.0")}},
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-marketplace-image",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required marketplace image terms accepted and images present in location.",
+					Details: []string{
+						"Found accepted terms for marketplace plan; Plan: 'pub:offer:plan1'",
+						"Found image; URN: 'pub:offer:plan1:1.0.0'",
+						"Found accepted terms for marketplace plan; Plan: 'pub:offer:plan2'",
+						"Found image; URN: 'pub:offer:sku2:latest'",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (terms not accepted and pinned image version not present)",
+			rule: v1alpha1.MarketplaceImageRule{
+				RuleName: "rule-1",
+				Location: "westus",
+				Images: []v1alpha1.MarketplaceImage{
+					{
+						Publisher: "pub",
+						Offer:     "offer",
+						Plan:      "plan1",
+						Version:   "1.0.0",
+					},
+				},
+				SubscriptionID: "sub",
+			},
+			apiMock: marketplaceImageAPIMock{
+				agreements: map[string]*azutils.MarketplaceAgreement{
+					"plan1": {Properties: &azutils.MarketplaceAgreementProperties{Accepted: util.Ptr(false)}},
+				},
+				versions: map[string][]*armcompute.VirtualMachineImageResource{
+					"plan1": {{Name: util.Ptr("0.9.0")}},
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-marketplace-image",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more required marketplace images unusable in subscription. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Terms for marketplace plan 'pub:offer:plan1' not accepted in subscription.",
+						"Image 'pub:offer:plan1:1.0.0' not present in location 'westus'.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (plan and image not found)",
+			rule: v1alpha1.MarketplaceImageRule{
+				RuleName: "rule-1",
+				Location: "westus",
+				Images: []v1alpha1.MarketplaceImage{
+					{
+						Publisher: "pub",
+						Offer:     "offer",
+						Plan:      "plan1",
+					},
+				},
+				SubscriptionID: "sub",
+			},
+			apiMock: marketplaceImageAPIMock{
+				// Can be any error message, just has to have this as substring.
+				agreementErr: errors.New("RESPONSE 404"),
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-marketplace-image",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more required marketplace images unusable in subscription. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Marketplace plan 'pub:offer:plan1' not found.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (error getting image versions) - validation result remains passing, code returned to interprets error and changes result",
+			rule: v1alpha1.MarketplaceImageRule{
+				RuleName: "rule-1",
+				Location: "westus",
+				Images: []v1alpha1.MarketplaceImage{
+					{
+						Publisher: "pub",
+						Offer:     "offer",
+						Plan:      "plan1",
+					},
+				},
+				SubscriptionID: "sub",
+			},
+			apiMock: marketplaceImageAPIMock{
+				agreements: map[string]*azutils.MarketplaceAgreement{
+					"plan1": {Properties: &azutils.MarketplaceAgreementProperties{Accepted: util.Ptr(true)}},
+				},
+				versionErr: errors.New("list versions failed"),
+			},
+			expectedError: errors.New("failed to get marketplace image versions: list versions failed"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-marketplace-image",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required marketplace image terms accepted and images present in location.",
+					Details: []string{
+						"Found accepted terms for marketplace plan; Plan: 'pub:offer:plan1'",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewMarketplaceImageRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileMarketplaceImageRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
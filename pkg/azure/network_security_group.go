@@ -0,0 +1,507 @@
+package azure
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+var (
+	networkSecurityGroupRulePermissions = []string{
+		"Microsoft.Network/networkSecurityGroups/read",
+		"Microsoft.Network/virtualNetworks/read",
+	}
+)
+
+// networkSecurityGroupAPI contains methods that allow getting all the information we need for
+// network security groups and their security rules.
+type networkSecurityGroupAPI interface {
+	GetNetworkSecurityGroup(resourceGroup, name, subscriptionID string) (*armnetwork.SecurityGroup, error)
+}
+
+// NetworkSecurityGroupRuleService reconciles network security group rules.
+type NetworkSecurityGroupRuleService struct {
+	nsgAPI  networkSecurityGroupAPI
+	vnetAPI subnetAPI
+	log     logr.Logger
+}
+
+// NewNetworkSecurityGroupRuleService creates a new NetworkSecurityGroupRuleService. Requires an
+// Azure client facade that supports getting network security groups and one that supports
+// getting virtual networks.
+func NewNetworkSecurityGroupRuleService(nsgAPI networkSecurityGroupAPI, vnetAPI subnetAPI, log logr.Logger) *NetworkSecurityGroupRuleService {
+	return &NetworkSecurityGroupRuleService{
+		nsgAPI:  nsgAPI,
+		vnetAPI: vnetAPI,
+		log:     log,
+	}
+}
+
+// ReconcileNetworkSecurityGroupRule reconciles a network security group rule.
+func (s *NetworkSecurityGroupRuleService) ReconcileNetworkSecurityGroupRule(rule v1alpha1.NetworkSecurityGroupRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "networkSecurityGroup", rule.NetworkSecurityGroup, "virtualNetwork", rule.VirtualNetwork, "subnet", rule.Subnet, "resourceGroup", rule.ResourceGroup, "subscription", rule.SubscriptionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "All required network flows allowed by network security group."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeNetworkSecurityGroup
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	nsg, vnet, err := s.getNetworkSecurityGroup(rule, &latestCondition.Failures, &latestCondition.Details, log)
+	if err != nil {
+		// Code this is returning to will take care of changing the validation result to a failed
+		// validation, using the error returned.
+		return validationResult, err
+	}
+
+	if nsg != nil {
+		if vnet == nil {
+			vnet, err = s.getNetworkSecurityGroupVirtualNetwork(nsg, log)
+			if err != nil {
+				return validationResult, err
+			}
+		}
+		securityRules := sortedSecurityRules(nsg)
+		vnetAddressSpace := virtualNetworkAddressSpace(vnet)
+		for _, flow := range rule.Flows {
+			securityRule, evaluable := evaluateNetworkFlow(flow, securityRules, vnetAddressSpace)
+			if securityRule == nil {
+				latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Flow '%s' not allowed by any security rule.", networkFlowString(flow)))
+				continue
+			}
+			name, priority := securityRuleNameAndPriority(securityRule)
+			if !evaluable {
+				latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Flow '%s' cannot be evaluated against security rule '%s' (priority %d), which uses a service tag or application security group whose addresses are unknown.", networkFlowString(flow), name, priority))
+				continue
+			}
+			if *securityRule.Properties.Access != armnetwork.SecurityRuleAccessAllow {
+				latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Flow '%s' denied by security rule '%s' (priority %d).", networkFlowString(flow), name, priority))
+				continue
+			}
+			latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Flow '%s' allowed by security rule '%s' (priority %d).", networkFlowString(flow), name, priority))
+		}
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "One or more required network flows not allowed by network security group. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// getNetworkSecurityGroup gets the network security group from the rule, either by name or by
+// looking it up from the subnet. When looked up from the subnet, the subnet's virtual network is
+// also returned. Returns nil, without an error, if the subnet is missing (which is recorded as a
+// failure) or has no network security group (which allows all flows).
+func (s *NetworkSecurityGroupRuleService) getNetworkSecurityGroup(rule v1alpha1.NetworkSecurityGroupRule, failures, details *[]string, log logr.Logger) (*armnetwork.SecurityGroup, *armnetwork.VirtualNetwork, error) {
+	resourceGroup, name, subscriptionID := rule.ResourceGroup, rule.NetworkSecurityGroup, rule.SubscriptionID
+
+	var vnet *armnetwork.VirtualNetwork
+	if rule.NetworkSecurityGroup == "" {
+		var err error
+		vnet, err = s.vnetAPI.GetVirtualNetwork(rule.ResourceGroup, rule.VirtualNetwork, rule.SubscriptionID)
+		if err != nil {
+			if azerr.IsNotFound(err) {
+				return nil, nil, fmt.Errorf("virtual network %s not found in resource group %s using subscription %s", rule.VirtualNetwork, rule.ResourceGroup, rule.SubscriptionID)
+			}
+			return nil, nil, fmt.Errorf("failed to get virtual network: %w", azerr.AsAugmented(err, networkSecurityGroupRulePermissions))
+		}
+		var subnet *armnetwork.Subnet
+		if vnet.Properties != nil {
+			for _, sn := range vnet.Properties.Subnets {
+				if sn != nil && sn.Name != nil && strings.EqualFold(*sn.Name, rule.Subnet) {
+					subnet = sn
+					break
+				}
+			}
+		}
+		if subnet == nil {
+			*failures = append(*failures, fmt.Sprintf("Subnet '%s' not present in virtual network '%s'.", rule.Subnet, rule.VirtualNetwork))
+			return nil, nil, nil
+		}
+		if subnet.Properties == nil || subnet.Properties.NetworkSecurityGroup == nil || subnet.Properties.NetworkSecurityGroup.ID == nil {
+			*details = append(*details, fmt.Sprintf("Subnet '%s' has no network security group; all flows allowed.", rule.Subnet))
+			return nil, nil, nil
+		}
+		id, err := arm.ParseResourceID(*subnet.Properties.NetworkSecurityGroup.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse network security group ID of subnet: %w", err)
+		}
+		resourceGroup, name, subscriptionID = id.ResourceGroupName, id.Name, id.SubscriptionID
+		log.V(1).Info("Found network security group of subnet.", "networkSecurityGroupID", id.String())
+	}
+
+	nsg, err := s.nsgAPI.GetNetworkSecurityGroup(resourceGroup, name, subscriptionID)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return nil, nil, fmt.Errorf("network security group %s not found in resource group %s using subscription %s", name, resourceGroup, subscriptionID)
+		}
+		return nil, nil, fmt.Errorf("failed to get network security group: %w", azerr.AsAugmented(err, networkSecurityGroupRulePermissions))
+	}
+	return nsg, vnet, nil
+}
+
+// getNetworkSecurityGroupVirtualNetwork gets the virtual network of the subnets a network security
+// group is associated with, which is needed to evaluate the VirtualNetwork service tag. Returns
+// nil, without an error, if the network security group isn't associated with subnets of exactly one
+// virtual network.
+func (s *NetworkSecurityGroupRuleService) getNetworkSecurityGroupVirtualNetwork(nsg *armnetwork.SecurityGroup, log logr.Logger) (*armnetwork.VirtualNetwork, error) {
+	if nsg.Properties == nil {
+		return nil, nil
+	}
+	vnetIDs := map[string]*arm.ResourceID{}
+	for _, sn := range nsg.Properties.Subnets {
+		if sn == nil || sn.ID == nil {
+			continue
+		}
+		id, err := arm.ParseResourceID(*sn.ID)
+		if err != nil || id.Parent == nil {
+			continue
+		}
+		vnetIDs[strings.ToLower(id.Parent.String())] = id.Parent
+	}
+	if len(vnetIDs) != 1 {
+		log.V(1).Info("Network security group not associated with subnets of exactly one virtual network; VirtualNetwork service tag can't be evaluated.", "virtualNetworks", len(vnetIDs))
+		return nil, nil
+	}
+	for _, id := range vnetIDs {
+		vnet, err := s.vnetAPI.GetVirtualNetwork(id.ResourceGroupName, id.Name, id.SubscriptionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get virtual network of network security group: %w", azerr.AsAugmented(err, networkSecurityGroupRulePermissions))
+		}
+		return vnet, nil
+	}
+	return nil, nil
+}
+
+// virtualNetworkAddressSpace returns the address prefixes of a virtual network, which are the
+// addresses of the VirtualNetwork service tag. Returns nil if they're unknown.
+func virtualNetworkAddressSpace(vnet *armnetwork.VirtualNetwork) []netip.Prefix {
+	var prefixes []netip.Prefix
+	if vnet == nil || vnet.Properties == nil || vnet.Properties.AddressSpace == nil {
+		return prefixes
+	}
+	for _, p := range vnet.Properties.AddressSpace.AddressPrefixes {
+		if p == nil {
+			continue
+		}
+		if prefix, ok := parsePrefixOrAddr(*p); ok {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// sortedSecurityRules returns the security rules and default security rules of a network security
+// group in the order Azure evaluates them, which is by ascending priority. Rules missing the
+// properties needed to evaluate them are dropped.
+func sortedSecurityRules(nsg *armnetwork.SecurityGroup) []*armnetwork.SecurityRule {
+	var rules []*armnetwork.SecurityRule
+	if nsg.Properties == nil {
+		return rules
+	}
+	for _, r := range slices.Concat(nsg.Properties.SecurityRules, nsg.Properties.DefaultSecurityRules) {
+		if r == nil || r.Properties == nil || r.Properties.Priority == nil || r.Properties.Access == nil ||
+			r.Properties.Direction == nil || r.Properties.Protocol == nil {
+			continue
+		}
+		rules = append(rules, r)
+	}
+	slices.SortStableFunc(rules, func(a, b *armnetwork.SecurityRule) int {
+		return int(*a.Properties.Priority - *b.Properties.Priority)
+	})
+	return rules
+}
+
+// evaluateNetworkFlow returns the first security rule that matches a network flow, which decides
+// whether the flow is allowed. The security rules must already be sorted by priority.
+//
+// Flows don't have a source port, and their addresses can cover more than one address, so a
+// security rule can apply to only part of a flow. Deny rules that apply to part of a flow match it,
+// because they deny that part of it. Allow rules only match flows they apply to entirely. If
+// whether a security rule applies to a flow can't be evaluated because it uses a service tag or an
+// application security group whose addresses are unknown, that security rule is returned and
+// evaluable is false. Returns nil if no
+// security rule matches.
+func evaluateNetworkFlow(flow v1alpha1.NetworkFlow, rules []*armnetwork.SecurityRule, vnetAddressSpace []netip.Prefix) (rule *armnetwork.SecurityRule, evaluable bool) {
+	for _, r := range rules {
+		p := r.Properties
+		if !strings.EqualFold(string(*p.Direction), flow.Direction) {
+			continue
+		}
+		if *p.Protocol != armnetwork.SecurityRuleProtocolAsterisk && !strings.EqualFold(string(*p.Protocol), flow.Protocol) {
+			continue
+		}
+		if !strings.EqualFold(flow.Protocol, string(armnetwork.SecurityRuleProtocolIcmp)) &&
+			!portInRanges(flow.Port, stringsWithSingle(p.DestinationPortRange, p.DestinationPortRanges)) {
+			continue
+		}
+		source := addressInPrefixes(flow.SourceAddressPrefix, stringsWithSingle(p.SourceAddressPrefix, p.SourceAddressPrefixes), vnetAddressSpace)
+		destination := addressInPrefixes(flow.DestinationAddressPrefix, stringsWithSingle(p.DestinationAddressPrefix, p.DestinationAddressPrefixes), vnetAddressSpace)
+		// Security rules that use application security groups have no address prefixes for them,
+		// and which addresses the groups contain is unknown.
+		if len(p.SourceApplicationSecurityGroups) > 0 {
+			source = addressUnknown
+		}
+		if len(p.DestinationApplicationSecurityGroups) > 0 {
+			destination = addressUnknown
+		}
+		if source == addressNotInPrefixes || destination == addressNotInPrefixes {
+			continue
+		}
+		if source == addressUnknown || destination == addressUnknown {
+			return r, false
+		}
+		anySourcePort := slices.Contains(stringsWithSingle(p.SourcePortRange, p.SourcePortRanges), "*")
+		if (source == addressPartlyInPrefixes || destination == addressPartlyInPrefixes || !anySourcePort) &&
+			*p.Access == armnetwork.SecurityRuleAccessAllow {
+			continue
+		}
+		return r, true
+	}
+	return nil, true
+}
+
+// stringsWithSingle combines the single and plural forms of a security rule property (e.g.
+// DestinationPortRange and DestinationPortRanges). Azure only sets one of them.
+func stringsWithSingle(single *string, plural []*string) []string {
+	vals := []string{}
+	if single != nil && *single != "" {
+		vals = append(vals, *single)
+	}
+	for _, v := range plural {
+		if v != nil && *v != "" {
+			vals = append(vals, *v)
+		}
+	}
+	return vals
+}
+
+// portInRanges returns whether a port is in any of the port ranges of a security rule. Port ranges
+// are "*", a single port (e.g. "443"), or an inclusive range (e.g. "1000-2000").
+func portInRanges(port int32, ranges []string) bool {
+	for _, r := range ranges {
+		if r == "*" {
+			return true
+		}
+		low, high, isRange := strings.Cut(r, "-")
+		if !isRange {
+			high = low
+		}
+		lowPort, err := strconv.ParseInt(strings.TrimSpace(low), 10, 32)
+		if err != nil {
+			continue
+		}
+		highPort, err := strconv.ParseInt(strings.TrimSpace(high), 10, 32)
+		if err != nil {
+			continue
+		}
+		if int64(port) >= lowPort && int64(port) <= highPort {
+			return true
+		}
+	}
+	return false
+}
+
+// addressMatch is how much of an address from a flow is in the address prefixes of a security rule.
+type addressMatch int
+
+const (
+	addressNotInPrefixes addressMatch = iota
+	addressPartlyInPrefixes
+	addressInPrefixesEntirely
+	addressUnknown
+)
+
+const (
+	internetServiceTag          = "Internet"
+	virtualNetworkServiceTag    = "VirtualNetwork"
+	azureLoadBalancerServiceTag = "AzureLoadBalancer"
+)
+
+var (
+	// builtInServiceTags are the service tags whose addresses don't overlap each other.
+	builtInServiceTags = []string{internetServiceTag, virtualNetworkServiceTag, azureLoadBalancerServiceTag}
+	// privatePrefixes are the private address ranges, which aren't part of the Internet service tag.
+	privatePrefixes = []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("172.16.0.0/12"),
+		netip.MustParsePrefix("192.168.0.0/16"),
+		netip.MustParsePrefix("fc00::/7"),
+	}
+	// azureLoadBalancerPrefixes are the addresses of the AzureLoadBalancer service tag, which is
+	// the source of Azure load balancer health probes.
+	azureLoadBalancerPrefixes = []netip.Prefix{netip.MustParsePrefix("168.63.129.16/32")}
+)
+
+// addressSet is a set of addresses, either from IP addresses and CIDR blocks or from a service tag.
+// The Internet service tag is every address that isn't private or in the virtual network.
+type addressSet struct {
+	prefixes []netip.Prefix
+	internet bool
+}
+
+// addressInPrefixes returns how much of an address from a flow is in the address prefixes of a
+// security rule. Addresses and prefixes can be IP addresses, CIDR blocks, or service tags. The
+// Internet, VirtualNetwork, and AzureLoadBalancer service tags are evaluated using their addresses.
+// VirtualNetwork is the address space of the virtual network, so it is unknown if the virtual
+// network is. Other service tags only match "*" or the same service tag, and are otherwise unknown.
+func addressInPrefixes(address string, prefixes []string, vnetAddressSpace []netip.Prefix) addressMatch {
+	result := addressNotInPrefixes
+	for _, p := range prefixes {
+		m := addressInPrefix(address, p, vnetAddressSpace)
+		if m == addressInPrefixesEntirely {
+			return m
+		}
+		// Unknown takes precedence over partly, since the prefix might contain the whole address.
+		if m > result {
+			result = m
+		}
+	}
+	return result
+}
+
+// addressInPrefix returns how much of an address from a flow is in a single address prefix of a
+// security rule.
+func addressInPrefix(address, prefix string, vnetAddressSpace []netip.Prefix) addressMatch {
+	if prefix == "*" || strings.EqualFold(prefix, "Any") || strings.EqualFold(prefix, address) {
+		return addressInPrefixesEntirely
+	}
+	if isBuiltInServiceTag(address) && isBuiltInServiceTag(prefix) {
+		return addressNotInPrefixes
+	}
+	flowAddresses, ok := resolveAddress(address, vnetAddressSpace)
+	if !ok {
+		return addressUnknown
+	}
+	ruleAddresses, ok := resolveAddress(prefix, vnetAddressSpace)
+	if !ok {
+		return addressUnknown
+	}
+
+	notInternet := slices.Concat(privatePrefixes, vnetAddressSpace)
+	switch {
+	case flowAddresses.internet:
+		for _, p := range ruleAddresses.prefixes {
+			if p.Bits() == 0 {
+				return addressInPrefixesEntirely
+			}
+		}
+		if allPrefixesIn(ruleAddresses.prefixes, notInternet) {
+			return addressNotInPrefixes
+		}
+		return addressPartlyInPrefixes
+	case ruleAddresses.internet:
+		if noPrefixesOverlap(flowAddresses.prefixes, notInternet) {
+			return addressInPrefixesEntirely
+		}
+		if allPrefixesIn(flowAddresses.prefixes, notInternet) {
+			return addressNotInPrefixes
+		}
+		return addressPartlyInPrefixes
+	default:
+		if allPrefixesIn(flowAddresses.prefixes, ruleAddresses.prefixes) {
+			return addressInPrefixesEntirely
+		}
+		if noPrefixesOverlap(flowAddresses.prefixes, ruleAddresses.prefixes) {
+			return addressNotInPrefixes
+		}
+		return addressPartlyInPrefixes
+	}
+}
+
+// resolveAddress returns the addresses of an IP address, CIDR block, or built-in service tag.
+// Returns false if they're unknown.
+func resolveAddress(address string, vnetAddressSpace []netip.Prefix) (addressSet, bool) {
+	if prefix, ok := parsePrefixOrAddr(address); ok {
+		return addressSet{prefixes: []netip.Prefix{prefix}}, true
+	}
+	switch {
+	case strings.EqualFold(address, internetServiceTag):
+		return addressSet{internet: true}, true
+	case strings.EqualFold(address, virtualNetworkServiceTag) && len(vnetAddressSpace) > 0:
+		return addressSet{prefixes: vnetAddressSpace}, true
+	case strings.EqualFold(address, azureLoadBalancerServiceTag):
+		return addressSet{prefixes: azureLoadBalancerPrefixes}, true
+	}
+	return addressSet{}, false
+}
+
+// isBuiltInServiceTag returns whether an address is one of the built-in service tags.
+func isBuiltInServiceTag(address string) bool {
+	return slices.ContainsFunc(builtInServiceTags, func(t string) bool { return strings.EqualFold(t, address) })
+}
+
+// allPrefixesIn returns whether every prefix is contained in one of the outer prefixes.
+func allPrefixesIn(prefixes, outer []netip.Prefix) bool {
+	for _, p := range prefixes {
+		if !slices.ContainsFunc(outer, func(o netip.Prefix) bool { return o.Bits() <= p.Bits() && o.Contains(p.Addr()) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// noPrefixesOverlap returns whether none of the prefixes overlap any of the other prefixes.
+func noPrefixesOverlap(prefixes, other []netip.Prefix) bool {
+	for _, p := range prefixes {
+		if slices.ContainsFunc(other, p.Overlaps) {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePrefixOrAddr parses a CIDR block or an IP address, which is treated as a single address
+// CIDR block. Returns false if it's neither (e.g. it's a service tag).
+func parsePrefixOrAddr(s string) (netip.Prefix, bool) {
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return prefix.Masked(), true
+	}
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen()), true
+	}
+	return netip.Prefix{}, false
+}
+
+// securityRuleNameAndPriority returns the name and priority of a security rule for use in details
+// and failures.
+func securityRuleNameAndPriority(r *armnetwork.SecurityRule) (string, int32) {
+	name := ""
+	if r.Name != nil {
+		name = *r.Name
+	}
+	return name, *r.Properties.Priority
+}
+
+// networkFlowString returns a description of a network flow for use in details and failures.
+func networkFlowString(flow v1alpha1.NetworkFlow) string {
+	if strings.EqualFold(flow.Protocol, string(armnetwork.SecurityRuleProtocolIcmp)) {
+		return fmt.Sprintf("%s %s from %s to %s", flow.Direction, flow.Protocol, flow.SourceAddressPrefix, flow.DestinationAddressPrefix)
+	}
+	return fmt.Sprintf("%s %s/%d from %s to %s", flow.Direction, flow.Protocol, flow.Port, flow.SourceAddressPrefix, flow.DestinationAddressPrefix)
+}
@@ -0,0 +1,325 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type networkSecurityGroupAPIMock struct {
+	data *armnetwork.SecurityGroup
+	err  error
+}
+
+func (m networkSecurityGroupAPIMock) GetNetworkSecurityGroup(_, _, _ string) (*armnetwork.SecurityGroup, error) {
+	return m.data, m.err
+}
+
+func securityRule(name string, priority int32, access armnetwork.SecurityRuleAccess, direction armnetwork.SecurityRuleDirection, protocol armnetwork.SecurityRuleProtocol, destPortRange, srcPrefix, destPrefix string) *armnetwork.SecurityRule {
+	return &armnetwork.SecurityRule{
+		Name: util.Ptr(name),
+		Properties: &armnetwork.SecurityRulePropertiesFormat{
+			Priority:                 util.Ptr(priority),
+			Access:                   util.Ptr(access),
+			Direction:                util.Ptr(direction),
+			Protocol:                 util.Ptr(protocol),
+			SourcePortRange:          util.Ptr("*"),
+			DestinationPortRange:     util.Ptr(destPortRange),
+			SourceAddressPrefix:      util.Ptr(srcPrefix),
+			DestinationAddressPrefix: util.Ptr(destPrefix),
+		},
+	}
+}
+
+func TestNetworkSecurityGroupRuleService_ReconcileNetworkSecurityGroupRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.NetworkSecurityGroupRule
+		nsgAPIMock     networkSecurityGroupAPIMock
+		vnetAPIMock    subnetAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	nsg := &armnetwork.SecurityGroup{
+		Properties: &armnetwork.SecurityGroupPropertiesFormat{
+			SecurityRules: []*armnetwork.SecurityRule{
+				securityRule("DenyInternet6443", 200, armnetwork.SecurityRuleAccessDeny, armnetwork.SecurityRuleDirectionOutbound, armnetwork.SecurityRuleProtocolTCP, "6443", "*", "Internet"),
+				securityRule("AllowAPIServer", 100, armnetwork.SecurityRuleAccessAllow, armnetwork.SecurityRuleDirectionOutbound, armnetwork.SecurityRuleProtocolTCP, "6443", "10.0.0.0/16", "20.0.0.0/8"),
+				securityRule("AllowHTTPS", 300, armnetwork.SecurityRuleAccessAllow, armnetwork.SecurityRuleDirectionOutbound, armnetwork.SecurityRuleProtocolAsterisk, "400-500", "*", "*"),
+			},
+			DefaultSecurityRules: []*armnetwork.SecurityRule{
+				securityRule("AllowVnetInBound", 65000, armnetwork.SecurityRuleAccessAllow, armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleProtocolAsterisk, "*", "VirtualNetwork", "VirtualNetwork"),
+				securityRule("DenyAllInBound", 65500, armnetwork.SecurityRuleAccessDeny, armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleProtocolAsterisk, "*", "*", "*"),
+				securityRule("DenyAllOutBound", 65500, armnetwork.SecurityRuleAccessDeny, armnetwork.SecurityRuleDirectionOutbound, armnetwork.SecurityRuleProtocolAsterisk, "*", "*", "*"),
+			},
+		},
+	}
+
+	denySSHFromHighPorts := securityRule("DenySSHFromHighPorts", 100, armnetwork.SecurityRuleAccessDeny, armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleProtocolTCP, "22", "*", "*")
+	denySSHFromHighPorts.Properties.SourcePortRange = util.Ptr("1024-65535")
+	denyWebServers := securityRule("DenyWebServers", 140, armnetwork.SecurityRuleAccessDeny, armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleProtocolTCP, "9090", "*", "")
+	denyWebServers.Properties.DestinationApplicationSecurityGroups = []*armnetwork.ApplicationSecurityGroup{
+		{ID: util.Ptr("/subscriptions/sub/resourceGroups/rg1/providers/Microsoft.Network/applicationSecurityGroups/web")},
+	}
+	nsgWithServiceTags := &armnetwork.SecurityGroup{
+		Properties: &armnetwork.SecurityGroupPropertiesFormat{
+			Subnets: []*armnetwork.Subnet{
+				{ID: util.Ptr("/subscriptions/sub/resourceGroups/rg1/providers/Microsoft.Network/virtualNetworks/vnet1/subnets/subnet1")},
+				{ID: util.Ptr("/subscriptions/sub/resourceGroups/rg1/providers/Microsoft.Network/virtualNetworks/vnet1/subnets/subnet2")},
+			},
+			SecurityRules: []*armnetwork.SecurityRule{
+				denySSHFromHighPorts,
+				securityRule("AllowInternetHTTP", 110, armnetwork.SecurityRuleAccessAllow, armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleProtocolTCP, "80", "Internet", "VirtualNetwork"),
+				securityRule("DenyStorage", 120, armnetwork.SecurityRuleAccessDeny, armnetwork.SecurityRuleDirectionOutbound, armnetwork.SecurityRuleProtocolTCP, "443", "*", "Storage"),
+				securityRule("DenyManagement8080", 130, armnetwork.SecurityRuleAccessDeny, armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleProtocolTCP, "8080", "10.0.0.0/24", "*"),
+				denyWebServers,
+			},
+			DefaultSecurityRules: []*armnetwork.SecurityRule{
+				securityRule("AllowVnetInBound", 65000, armnetwork.SecurityRuleAccessAllow, armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleProtocolAsterisk, "*", "VirtualNetwork", "VirtualNetwork"),
+				securityRule("AllowAzureLoadBalancerInBound", 65001, armnetwork.SecurityRuleAccessAllow, armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleProtocolAsterisk, "*", "AzureLoadBalancer", "*"),
+				securityRule("DenyAllInBound", 65500, armnetwork.SecurityRuleAccessDeny, armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleProtocolAsterisk, "*", "*", "*"),
+				securityRule("DenyAllOutBound", 65500, armnetwork.SecurityRuleAccessDeny, armnetwork.SecurityRuleDirectionOutbound, armnetwork.SecurityRuleProtocolAsterisk, "*", "*", "*"),
+			},
+		},
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (flows allowed by security rules in priority order)",
+			rule: v1alpha1.NetworkSecurityGroupRule{
+				RuleName:             "rule-1",
+				ResourceGroup:        "rg1",
+				NetworkSecurityGroup: "nsg1",
+				Flows: []v1alpha1.NetworkFlow{
+					{Direction: "Outbound", Protocol: "Tcp", Port: 6443, SourceAddressPrefix: "10.0.1.4", DestinationAddressPrefix: "20.1.0.0/24"},
+					{Direction: "Outbound", Protocol: "Tcp", Port: 443, SourceAddressPrefix: "10.0.1.0/24", DestinationAddressPrefix: "Internet"},
+					{Direction: "Inbound", Protocol: "Udp", Port: 53, SourceAddressPrefix: "VirtualNetwork", DestinationAddressPrefix: "VirtualNetwork"},
+				},
+				SubscriptionID: "sub",
+			},
+			nsgAPIMock: networkSecurityGroupAPIMock{
+				data: nsg,
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-network-security-group",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required network flows allowed by network security group.",
+					Details: []string{
+						"Flow 'Outbound Tcp/6443 from 10.0.1.4 to 20.1.0.0/24' allowed by security rule 'AllowAPIServer' (priority 100).",
+						"Flow 'Outbound Tcp/443 from 10.0.1.0/24 to Internet' allowed by security rule 'AllowHTTPS' (priority 300).",
+						"Flow 'Inbound Udp/53 from VirtualNetwork to VirtualNetwork' allowed by security rule 'AllowVnetInBound' (priority 65000).",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (flows denied by security rules)",
+			rule: v1alpha1.NetworkSecurityGroupRule{
+				RuleName:             "rule-1",
+				ResourceGroup:        "rg1",
+				NetworkSecurityGroup: "nsg1",
+				Flows: []v1alpha1.NetworkFlow{
+					{Direction: "Outbound", Protocol: "Tcp", Port: 6443, SourceAddressPrefix: "10.0.1.4", DestinationAddressPrefix: "Internet"},
+					{Direction: "Outbound", Protocol: "Tcp", Port: 6443, SourceAddressPrefix: "10.1.0.0/24", DestinationAddressPrefix: "10.2.0.0/24"},
+					{Direction: "Inbound", Protocol: "Icmp", SourceAddressPrefix: "Internet", DestinationAddressPrefix: "10.0.1.4"},
+				},
+				SubscriptionID: "sub",
+			},
+			nsgAPIMock: networkSecurityGroupAPIMock{
+				data: nsg,
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-network-security-group",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more required network flows not allowed by network security group. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Flow 'Outbound Tcp/6443 from 10.0.1.4 to Internet' denied by security rule 'DenyInternet6443' (priority 200).",
+						"Flow 'Outbound Tcp/6443 from 10.1.0.0/24 to 10.2.0.0/24' denied by security rule 'DenyAllOutBound' (priority 65500).",
+						"Flow 'Inbound Icmp from Internet to 10.0.1.4' denied by security rule 'DenyAllInBound' (priority 65500).",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (deny rules with source ports or covering part of a flow deny it, service tags evaluated using virtual network of network security group, application security groups can't be evaluated)",
+			rule: v1alpha1.NetworkSecurityGroupRule{
+				RuleName:             "rule-1",
+				ResourceGroup:        "rg1",
+				NetworkSecurityGroup: "nsg1",
+				Flows: []v1alpha1.NetworkFlow{
+					{Direction: "Inbound", Protocol: "Tcp", Port: 22, SourceAddressPrefix: "10.0.1.4", DestinationAddressPrefix: "10.0.1.5"},
+					{Direction: "Inbound", Protocol: "Tcp", Port: 80, SourceAddressPrefix: "20.1.2.3", DestinationAddressPrefix: "10.0.1.4"},
+					{Direction: "Inbound", Protocol: "Tcp", Port: 80, SourceAddressPrefix: "172.16.0.4", DestinationAddressPrefix: "10.0.1.4"},
+					{Direction: "Outbound", Protocol: "Tcp", Port: 443, SourceAddressPrefix: "10.0.1.4", DestinationAddressPrefix: "20.1.2.3"},
+					{Direction: "Inbound", Protocol: "Tcp", Port: 8080, SourceAddressPrefix: "10.0.0.0/16", DestinationAddressPrefix: "10.0.1.4"},
+					{Direction: "Inbound", Protocol: "Tcp", Port: 8080, SourceAddressPrefix: "10.0.1.0/24", DestinationAddressPrefix: "10.0.1.4"},
+					{Direction: "Inbound", Protocol: "Tcp", Port: 8081, SourceAddressPrefix: "AzureLoadBalancer", DestinationAddressPrefix: "10.0.1.4"},
+					{Direction: "Inbound", Protocol: "Tcp", Port: 9090, SourceAddressPrefix: "10.0.1.4", DestinationAddressPrefix: "10.0.1.5"},
+				},
+				SubscriptionID: "sub",
+			},
+			nsgAPIMock: networkSecurityGroupAPIMock{
+				data: nsgWithServiceTags,
+			},
+			vnetAPIMock: subnetAPIMock{
+				data: &armnetwork.VirtualNetwork{
+					Properties: &armnetwork.VirtualNetworkPropertiesFormat{
+						AddressSpace: &armnetwork.AddressSpace{AddressPrefixes: []*string{util.Ptr("10.0.0.0/16")}},
+					},
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-network-security-group",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more required network flows not allowed by network security group. See failures for details.",
+					Details: []string{
+						"Flow 'Inbound Tcp/80 from 20.1.2.3 to 10.0.1.4' allowed by security rule 'AllowInternetHTTP' (priority 110).",
+						"Flow 'Inbound Tcp/8080 from 10.0.1.0/24 to 10.0.1.4' allowed by security rule 'AllowVnetInBound' (priority 65000).",
+						"Flow 'Inbound Tcp/8081 from AzureLoadBalancer to 10.0.1.4' allowed by security rule 'AllowAzureLoadBalancerInBound' (priority 65001).",
+					},
+					Failures: []string{
+						"Flow 'Inbound Tcp/22 from 10.0.1.4 to 10.0.1.5' denied by security rule 'DenySSHFromHighPorts' (priority 100).",
+						"Flow 'Inbound Tcp/80 from 172.16.0.4 to 10.0.1.4' denied by security rule 'DenyAllInBound' (priority 65500).",
+						"Flow 'Outbound Tcp/443 from 10.0.1.4 to 20.1.2.3' cannot be evaluated against security rule 'DenyStorage' (priority 120), which uses a service tag or application security group whose addresses are unknown.",
+						"Flow 'Inbound Tcp/8080 from 10.0.0.0/16 to 10.0.1.4' denied by security rule 'DenyManagement8080' (priority 130).",
+						"Flow 'Inbound Tcp/9090 from 10.0.1.4 to 10.0.1.5' cannot be evaluated against security rule 'DenyWebServers' (priority 140), which uses a service tag or application security group whose addresses are unknown.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Pass (network security group looked up from subnet)",
+			rule: v1alpha1.NetworkSecurityGroupRule{
+				RuleName:       "rule-1",
+				ResourceGroup:  "rg1",
+				VirtualNetwork: "vnet1",
+				Subnet:         "subnet1",
+				Flows: []v1alpha1.NetworkFlow{
+					{Direction: "Outbound", Protocol: "Tcp", Port: 443, SourceAddressPrefix: "10.0.1.4", DestinationAddressPrefix: "Internet"},
+				},
+				SubscriptionID: "sub",
+			},
+			nsgAPIMock: networkSecurityGroupAPIMock{
+				data: nsg,
+			},
+			vnetAPIMock: subnetAPIMock{
+				data: &armnetwork.VirtualNetwork{
+					Properties: &armnetwork.VirtualNetworkPropertiesFormat{
+						Subnets: []*armnetwork.Subnet{
+							{
+								Name: util.Ptr("subnet1"),
+								Properties: &armnetwork.SubnetPropertiesFormat{
+									NetworkSecurityGroup: &armnetwork.SecurityGroup{
+										ID: util.Ptr("/subscriptions/sub/resourceGroups/rg2/providers/Microsoft.Network/networkSecurityGroups/nsg1"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-network-security-group",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required network flows allowed by network security group.",
+					Details: []string{
+						"Flow 'Outbound Tcp/443 from 10.0.1.4 to Internet' allowed by security rule 'AllowHTTPS' (priority 300).",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Pass (subnet has no network security group)",
+			rule: v1alpha1.NetworkSecurityGroupRule{
+				RuleName:       "rule-1",
+				ResourceGroup:  "rg1",
+				VirtualNetwork: "vnet1",
+				Subnet:         "subnet1",
+				Flows: []v1alpha1.NetworkFlow{
+					{Direction: "Outbound", Protocol: "Tcp", Port: 443, SourceAddressPrefix: "10.0.1.4", DestinationAddressPrefix: "Internet"},
+				},
+				SubscriptionID: "sub",
+			},
+			vnetAPIMock: subnetAPIMock{
+				data: vnetWithSubnet("subnet1", "10.0.1.0/24", 0, nil, nil),
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-network-security-group",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required network flows allowed by network security group.",
+					Details: []string{
+						"Subnet 'subnet1' has no network security group; all flows allowed.",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (network security group does not exist or is not accessible using subscription) - validation result remains passing, code returned to interprets error and changes result",
+			rule: v1alpha1.NetworkSecurityGroupRule{
+				RuleName:             "rule-1",
+				ResourceGroup:        "rg1",
+				NetworkSecurityGroup: "nsg1",
+				Flows: []v1alpha1.NetworkFlow{
+					{Direction: "Outbound", Protocol: "Tcp", Port: 443, SourceAddressPrefix: "10.0.1.4", DestinationAddressPrefix: "Internet"},
+				},
+				SubscriptionID: "sub",
+			},
+			nsgAPIMock: networkSecurityGroupAPIMock{
+				// Can be any error message, just has to have this as substring.
+				err: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("network security group nsg1 not found in resource group rg1 using subscription sub"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-network-security-group",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required network flows allowed by network security group.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewNetworkSecurityGroupRuleService(tc.nsgAPIMock, tc.vnetAPIMock, logr.Logger{})
+		result, err := svc.ReconcileNetworkSecurityGroupRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
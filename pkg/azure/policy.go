@@ -0,0 +1,312 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	// policyEnforcementModeDoNotEnforce is the enforcement mode of policy assignments whose effects
+	// aren't enforced.
+	policyEnforcementModeDoNotEnforce = "DoNotEnforce"
+)
+
+var (
+	policyRulePermissions = []string{
+		"Microsoft.Authorization/policyAssignments/read",
+		"Microsoft.Authorization/policyDefinitions/read",
+		"Microsoft.Authorization/policySetDefinitions/read",
+	}
+
+	// denyPolicyEffects are the policy effects that block requests.
+	denyPolicyEffects = []string{"deny", "denyAction"}
+)
+
+// policyAPI contains methods that allow getting all the information we need for policy
+// assignments and the policy definitions and policy set definitions they assign.
+type policyAPI interface {
+	GetPolicyAssignmentsForScope(scope string) ([]*azutils.PolicyAssignment, error)
+	GetPolicyDefinition(id string) (*azutils.PolicyDefinition, error)
+	GetPolicySetDefinition(id string) (*azutils.PolicySetDefinition, error)
+}
+
+// assignedPolicyDefinition is a policy definition assigned by a policy assignment, either directly
+// or through a policy set definition, with the parameter values it was assigned with.
+type assignedPolicyDefinition struct {
+	definition *azutils.PolicyDefinition
+	params     map[string]any
+}
+
+// PolicyRuleService reconciles policy rules.
+type PolicyRuleService struct {
+	api policyAPI
+	log logr.Logger
+}
+
+// NewPolicyRuleService creates a new PolicyRuleService. Requires an Azure client facade that
+// supports getting policy assignments, policy definitions, and policy set definitions.
+func NewPolicyRuleService(api policyAPI, log logr.Logger) *PolicyRuleService {
+	return &PolicyRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcilePolicyRule reconciles a policy rule.
+func (s *PolicyRuleService) ReconcilePolicyRule(rule v1alpha1.PolicyRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "scope", rule.Scope)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "No policy assignments deny resources."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypePolicy
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	assignments, err := s.api.GetPolicyAssignmentsForScope(rule.Scope)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return validationResult, fmt.Errorf("scope %s not found", rule.Scope)
+		}
+		return validationResult, fmt.Errorf("failed to get policy assignments for scope: %w", azerr.AsAugmented(err, policyRulePermissions))
+	}
+
+	// Policy definitions are often assigned more than once (e.g. by more than one policy set
+	// definition), so they're cached to avoid getting them more than once.
+	definitions := map[string]*azutils.PolicyDefinition{}
+
+	denied := map[int]bool{}
+	for _, assignment := range assignments {
+		if assignment == nil || assignment.Properties == nil || assignment.Properties.PolicyDefinitionID == nil {
+			log.Error(nil, "Policy assignment in API response was missing properties.")
+			continue
+		}
+		if !policyAssignmentApplies(assignment, rule.Scope) {
+			continue
+		}
+		assigned, err := s.assignedPolicyDefinitions(assignment, definitions)
+		if err != nil {
+			// Code this is returning to will take care of changing the validation result to a
+			// failed validation, using the error returned.
+			return validationResult, err
+		}
+		for _, a := range assigned {
+			effect, ok := policyDefinitionEffect(a)
+			if !ok || !isDenyPolicyEffect(effect) {
+				continue
+			}
+			for i, res := range rule.Resources {
+				switch evaluatePolicyCondition(a.definition.Properties.PolicyRule.If, res, a.params) {
+				case conditionTrue:
+					denied[i] = true
+					latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf(
+						"Policy assignment '%s' denies resource '%s' with policy definition '%s'; Effect: '%s'",
+						policyName(assignment.Properties.DisplayName, assignment.Name), policyResourceString(res),
+						policyName(a.definition.Properties.DisplayName, a.definition.Name), effect,
+					))
+				case conditionUnknown:
+					latestCondition.Details = append(latestCondition.Details, fmt.Sprintf(
+						"Policy assignment '%s' may deny resource '%s' with policy definition '%s', but its conditions could not be fully evaluated; Effect: '%s'",
+						policyName(assignment.Properties.DisplayName, assignment.Name), policyResourceString(res),
+						policyName(a.definition.Properties.DisplayName, a.definition.Name), effect,
+					))
+				}
+			}
+		}
+	}
+
+	for i, res := range rule.Resources {
+		if !denied[i] {
+			latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("No policy assignments deny resource; Resource: '%s'", policyResourceString(res)))
+		}
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "One or more policy assignments deny resources. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// assignedPolicyDefinitions gets the policy definitions assigned by a policy assignment, resolving
+// policy set definitions into the policy definitions they contain, along with the parameter values
+// each policy definition is assigned with.
+func (s *PolicyRuleService) assignedPolicyDefinitions(assignment *azutils.PolicyAssignment, cache map[string]*azutils.PolicyDefinition) ([]assignedPolicyDefinition, error) {
+	id := *assignment.Properties.PolicyDefinitionID
+
+	if !strings.Contains(strings.ToLower(id), "/policysetdefinitions/") {
+		definition, err := s.getPolicyDefinition(id, cache)
+		if err != nil {
+			return nil, err
+		}
+		params := policyParams(definitionParamDefaults(definition), assignment.Properties.Parameters, nil)
+		return []assignedPolicyDefinition{{definition: definition, params: params}}, nil
+	}
+
+	setDefinition, err := s.api.GetPolicySetDefinition(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy set definition: %w", azerr.AsAugmented(err, policyRulePermissions))
+	}
+	if setDefinition.Properties == nil {
+		return nil, nil
+	}
+	setDefaults := map[string]any{}
+	for name, p := range setDefinition.Properties.Parameters {
+		if p != nil && p.DefaultValue != nil {
+			setDefaults[name] = p.DefaultValue
+		}
+	}
+	setParams := policyParams(setDefaults, assignment.Properties.Parameters, nil)
+
+	var assigned []assignedPolicyDefinition
+	for _, ref := range setDefinition.Properties.PolicyDefinitions {
+		if ref == nil || ref.PolicyDefinitionID == nil {
+			continue
+		}
+		definition, err := s.getPolicyDefinition(*ref.PolicyDefinitionID, cache)
+		if err != nil {
+			return nil, err
+		}
+		params := policyParams(definitionParamDefaults(definition), ref.Parameters, setParams)
+		assigned = append(assigned, assignedPolicyDefinition{definition: definition, params: params})
+	}
+	return assigned, nil
+}
+
+// getPolicyDefinition gets a policy definition, using the cache if it has already been gotten.
+func (s *PolicyRuleService) getPolicyDefinition(id string, cache map[string]*azutils.PolicyDefinition) (*azutils.PolicyDefinition, error) {
+	key := strings.ToLower(id)
+	if definition, ok := cache[key]; ok {
+		return definition, nil
+	}
+	definition, err := s.api.GetPolicyDefinition(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy definition: %w", azerr.AsAugmented(err, policyRulePermissions))
+	}
+	cache[key] = definition
+	return definition, nil
+}
+
+// policyAssignmentApplies returns whether a policy assignment is enforced for resources deployed
+// to a scope. Assignments that aren't enforced and assignments that exclude the scope don't apply.
+func policyAssignmentApplies(assignment *azutils.PolicyAssignment, scope string) bool {
+	if assignment.Properties.EnforcementMode != nil && strings.EqualFold(*assignment.Properties.EnforcementMode, policyEnforcementModeDoNotEnforce) {
+		return false
+	}
+	scope = strings.ToLower(strings.TrimSuffix(scope, "/"))
+	for _, notScope := range assignment.Properties.NotScopes {
+		if notScope == nil {
+			continue
+		}
+		ns := strings.ToLower(strings.TrimSuffix(*notScope, "/"))
+		if scope == ns || strings.HasPrefix(scope, ns+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// definitionParamDefaults returns the default values of the parameters of a policy definition.
+func definitionParamDefaults(definition *azutils.PolicyDefinition) map[string]any {
+	defaults := map[string]any{}
+	if definition.Properties == nil {
+		return defaults
+	}
+	for name, p := range definition.Properties.Parameters {
+		if p != nil && p.DefaultValue != nil {
+			defaults[name] = p.DefaultValue
+		}
+	}
+	return defaults
+}
+
+// policyParams combines default parameter values with the parameter values passed by a policy
+// assignment or policy set definition. Passed values can be parameter expressions, which are
+// resolved using outer. Values that can't be resolved are left out, which makes conditions that use
+// them unknown.
+func policyParams(defaults map[string]any, values map[string]*azutils.PolicyParameterValue, outer map[string]any) map[string]any {
+	params := map[string]any{}
+	for name, v := range defaults {
+		params[name] = v
+	}
+	for name, v := range values {
+		if v == nil {
+			continue
+		}
+		if resolved, ok := resolvePolicyValue(v.Value, outer); ok {
+			params[name] = resolved
+		} else {
+			delete(params, name)
+		}
+	}
+	return params
+}
+
+// policyDefinitionEffect returns the effect of an assigned policy definition, resolving it if it's
+// a parameter expression. Returns false if the policy definition has no policy rule or the effect
+// can't be resolved.
+func policyDefinitionEffect(a assignedPolicyDefinition) (string, bool) {
+	if a.definition.Properties == nil || a.definition.Properties.PolicyRule == nil ||
+		a.definition.Properties.PolicyRule.Then == nil || a.definition.Properties.PolicyRule.Then.Effect == nil {
+		return "", false
+	}
+	effect, ok := resolvePolicyValue(*a.definition.Properties.PolicyRule.Then.Effect, a.params)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprint(effect), true
+}
+
+// isDenyPolicyEffect returns whether a policy effect blocks requests.
+func isDenyPolicyEffect(effect string) bool {
+	for _, e := range denyPolicyEffects {
+		if strings.EqualFold(effect, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyName returns the display name of a policy assignment or policy definition, falling back to
+// its name if it has no display name.
+func policyName(displayName, name *string) string {
+	if displayName != nil && *displayName != "" {
+		return *displayName
+	}
+	if name != nil {
+		return *name
+	}
+	return ""
+}
+
+// policyResourceString returns a description of a resource for use in details and failures.
+func policyResourceString(res v1alpha1.PolicyResource) string {
+	parts := []string{res.Type}
+	if res.Location != "" {
+		parts = append(parts, fmt.Sprintf("location=%s", res.Location))
+	}
+	if res.SKU != "" {
+		parts = append(parts, fmt.Sprintf("sku=%s", res.SKU))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,254 @@
+package azure
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+)
+
+// conditionResult is the result of evaluating a policy condition against a resource description.
+// Resource descriptions don't include every property of a resource, so some conditions can't be
+// fully evaluated.
+type conditionResult int
+
+const (
+	conditionFalse conditionResult = iota
+	conditionTrue
+	conditionUnknown
+)
+
+var (
+	// parameterExpression matches policy language expressions that only refer to a parameter
+	// (e.g. "[parameters('effect')]").
+	parameterExpression = regexp.MustCompile(`^\[\s*parameters\(\s*'([^']+)'\s*\)\s*\]$`)
+	// tagField matches policy fields that refer to a tag (e.g. "tags['env']", "tags[env]", and
+	// "tags.env").
+	tagField = regexp.MustCompile(`^(?i:tags)(?:\['([^']+)'\]|\[([^\]]+)\]|\.(.+))$`)
+)
+
+// boolCondition converts a bool to a conditionResult.
+func boolCondition(b bool) conditionResult {
+	if b {
+		return conditionTrue
+	}
+	return conditionFalse
+}
+
+// evaluatePolicyCondition evaluates a policy condition (the "if" of a policy rule) against a
+// resource description. Parameter expressions in the condition are resolved using params.
+func evaluatePolicyCondition(cond map[string]any, res v1alpha1.PolicyResource, params map[string]any) conditionResult {
+	if v, ok := cond["allOf"]; ok {
+		conds, ok := v.([]any)
+		if !ok {
+			return conditionUnknown
+		}
+		result := conditionTrue
+		for _, c := range conds {
+			cm, ok := c.(map[string]any)
+			if !ok {
+				return conditionUnknown
+			}
+			switch evaluatePolicyCondition(cm, res, params) {
+			case conditionFalse:
+				return conditionFalse
+			case conditionUnknown:
+				result = conditionUnknown
+			}
+		}
+		return result
+	}
+	if v, ok := cond["anyOf"]; ok {
+		conds, ok := v.([]any)
+		if !ok {
+			return conditionUnknown
+		}
+		result := conditionFalse
+		for _, c := range conds {
+			cm, ok := c.(map[string]any)
+			if !ok {
+				return conditionUnknown
+			}
+			switch evaluatePolicyCondition(cm, res, params) {
+			case conditionTrue:
+				return conditionTrue
+			case conditionUnknown:
+				result = conditionUnknown
+			}
+		}
+		return result
+	}
+	if v, ok := cond["not"]; ok {
+		cm, ok := v.(map[string]any)
+		if !ok {
+			return conditionUnknown
+		}
+		switch evaluatePolicyCondition(cm, res, params) {
+		case conditionTrue:
+			return conditionFalse
+		case conditionFalse:
+			return conditionTrue
+		}
+		return conditionUnknown
+	}
+
+	var value any
+	var exists bool
+	if field, ok := cond["field"]; ok {
+		fieldName, ok := field.(string)
+		if !ok {
+			return conditionUnknown
+		}
+		var known bool
+		value, exists, known = policyFieldValue(fieldName, res)
+		if !known {
+			return conditionUnknown
+		}
+	} else if v, ok := cond["value"]; ok {
+		var known bool
+		value, known = resolvePolicyValue(v, params)
+		if !known {
+			return conditionUnknown
+		}
+		exists = true
+	} else {
+		// Includes "count" expressions, which we don't support.
+		return conditionUnknown
+	}
+
+	for operator, operand := range cond {
+		if operator == "field" || operator == "value" {
+			continue
+		}
+		resolved, known := resolvePolicyValue(operand, params)
+		if !known {
+			return conditionUnknown
+		}
+		return evaluatePolicyOperator(operator, value, exists, resolved)
+	}
+	return conditionUnknown
+}
+
+// evaluatePolicyOperator evaluates a policy condition operator (e.g. "equals") against the value
+// of a field. String comparisons are case-insensitive, like they are in Azure Policy.
+func evaluatePolicyOperator(operator string, value any, exists bool, operand any) conditionResult {
+	str := fmt.Sprint(value)
+	switch strings.ToLower(operator) {
+	case "exists":
+		want, ok := operand.(bool)
+		if !ok {
+			want = strings.EqualFold(fmt.Sprint(operand), "true")
+		}
+		return boolCondition(exists == want)
+	case "equals":
+		return boolCondition(exists && strings.EqualFold(str, fmt.Sprint(operand)))
+	case "notequals":
+		return boolCondition(!exists || !strings.EqualFold(str, fmt.Sprint(operand)))
+	case "in":
+		return boolCondition(exists && policyValueIn(str, operand))
+	case "notin":
+		return boolCondition(!exists || !policyValueIn(str, operand))
+	case "like":
+		return boolCondition(exists && policyLike(str, fmt.Sprint(operand)))
+	case "notlike":
+		return boolCondition(!exists || !policyLike(str, fmt.Sprint(operand)))
+	case "contains":
+		return boolCondition(exists && strings.Contains(strings.ToLower(str), strings.ToLower(fmt.Sprint(operand))))
+	case "notcontains":
+		return boolCondition(!exists || !strings.Contains(strings.ToLower(str), strings.ToLower(fmt.Sprint(operand))))
+	case "containskey":
+		m, ok := value.(map[string]string)
+		return boolCondition(ok && mapHasKeyFold(m, fmt.Sprint(operand)))
+	case "notcontainskey":
+		m, ok := value.(map[string]string)
+		return boolCondition(!ok || !mapHasKeyFold(m, fmt.Sprint(operand)))
+	}
+	// Includes operators like "match" and "greater", which we don't support.
+	return conditionUnknown
+}
+
+// policyFieldValue returns the value of a policy field for a resource description, whether the
+// field exists on the resource, and whether the value is known from the description.
+func policyFieldValue(field string, res v1alpha1.PolicyResource) (value any, exists bool, known bool) {
+	switch {
+	case strings.EqualFold(field, "type"):
+		return res.Type, true, true
+	case strings.EqualFold(field, "location"):
+		return res.Location, res.Location != "", res.Location != ""
+	case strings.EqualFold(field, "tags"):
+		return res.Tags, true, true
+	case strings.HasSuffix(strings.ToLower(field), "sku.name"):
+		return res.SKU, res.SKU != "", res.SKU != ""
+	}
+	if m := tagField.FindStringSubmatch(field); m != nil {
+		key := m[1] + m[2] + m[3]
+		for k, v := range res.Tags {
+			if strings.EqualFold(k, key) {
+				return v, true, true
+			}
+		}
+		return "", false, true
+	}
+	return nil, false, false
+}
+
+// resolvePolicyValue resolves a value from a policy condition or policy rule. Parameter
+// expressions are resolved using params. Returns false if the value is an expression we can't
+// resolve.
+func resolvePolicyValue(v any, params map[string]any) (any, bool) {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, "[") {
+		return v, true
+	}
+	// Values starting with "[[" are escaped literals.
+	if strings.HasPrefix(s, "[[") {
+		return s[1:], true
+	}
+	m := parameterExpression.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false
+	}
+	for name, value := range params {
+		if strings.EqualFold(name, m[1]) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// policyValueIn returns whether a value is in the list operand of an "in" or "notIn" condition.
+func policyValueIn(value string, operand any) bool {
+	list, ok := operand.([]any)
+	if !ok {
+		return false
+	}
+	return slices.ContainsFunc(list, func(item any) bool {
+		return strings.EqualFold(value, fmt.Sprint(item))
+	})
+}
+
+// policyLike returns whether a value matches the pattern of a "like" condition, where "*" matches
+// any sequence of characters.
+func policyLike(value, pattern string) bool {
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	re, err := regexp.Compile("(?i)^" + strings.Join(parts, ".*") + "$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(value)
+}
+
+// mapHasKeyFold returns whether a map has a key, compared case-insensitively.
+func mapHasKeyFold(m map[string]string, key string) bool {
+	for k := range m {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package azure
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+)
+
+func policyCondition(t *testing.T, s string) map[string]any {
+	t.Helper()
+	cond := map[string]any{}
+	if err := json.Unmarshal([]byte(s), &cond); err != nil {
+		t.Fatalf("failed to unmarshal policy condition: %v", err)
+	}
+	return cond
+}
+
+func Test_evaluatePolicyCondition(t *testing.T) {
+	vm := v1alpha1.PolicyResource{
+		Type:     "Microsoft.Compute/virtualMachines",
+		Location: "eastus",
+		SKU:      "Standard_D4s_v5",
+		Tags:     map[string]string{"env": "prod"},
+	}
+
+	tests := []struct {
+		name   string
+		cond   string
+		res    v1alpha1.PolicyResource
+		params map[string]any
+		want   conditionResult
+	}{
+		{
+			name: "type equals (case-insensitive)",
+			cond: `{"field": "type", "equals": "microsoft.compute/VIRTUALMACHINES"}`,
+			res:  vm,
+			want: conditionTrue,
+		},
+		{
+			name:   "location not in allowed locations parameter",
+			cond:   `{"field": "location", "notIn": "[parameters('listOfAllowedLocations')]"}`,
+			res:    vm,
+			params: map[string]any{"listOfAllowedLocations": []any{"westus", "eastus"}},
+			want:   conditionFalse,
+		},
+		{
+			name: "allOf with type and SKU alias not in list",
+			cond: `{"allOf": [
+				{"field": "type", "equals": "Microsoft.Compute/virtualMachines"},
+				{"not": {"field": "Microsoft.Compute/virtualMachines/sku.name", "in": ["Standard_B2s"]}}
+			]}`,
+			res:  vm,
+			want: conditionTrue,
+		},
+		{
+			name: "missing tag",
+			cond: `{"field": "tags['costCenter']", "exists": "false"}`,
+			res:  vm,
+			want: conditionTrue,
+		},
+		{
+			name: "tag like pattern",
+			cond: `{"field": "tags.env", "like": "pr*"}`,
+			res:  vm,
+			want: conditionTrue,
+		},
+		{
+			name: "anyOf with unsupported alias is unknown",
+			cond: `{"anyOf": [
+				{"field": "Microsoft.Storage/storageAccounts/minimumTlsVersion", "notEquals": "TLS1_2"},
+				{"field": "type", "equals": "Microsoft.Network/publicIPAddresses"}
+			]}`,
+			res:  vm,
+			want: conditionUnknown,
+		},
+		{
+			name: "allOf with a false condition is false even if another is unknown",
+			cond: `{"allOf": [
+				{"field": "Microsoft.Storage/storageAccounts/minimumTlsVersion", "notEquals": "TLS1_2"},
+				{"field": "type", "equals": "Microsoft.Network/publicIPAddresses"}
+			]}`,
+			res:  vm,
+			want: conditionFalse,
+		},
+		{
+			name: "location unknown when not described",
+			cond: `{"field": "location", "equals": "eastus"}`,
+			res:  v1alpha1.PolicyResource{Type: "Microsoft.Compute/virtualMachines"},
+			want: conditionUnknown,
+		},
+		{
+			name: "unresolvable parameter is unknown",
+			cond: `{"field": "location", "in": "[parameters('locations')]"}`,
+			res:  vm,
+			want: conditionUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluatePolicyCondition(policyCondition(t, tt.cond), tt.res, tt.params)
+			if got != tt.want {
+				t.Errorf("evaluatePolicyCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type policyAPIMock struct {
+	assignments    []*azutils.PolicyAssignment
+	assignmentsErr error
+	// keyed by ID
+	definitions    map[string]*azutils.PolicyDefinition
+	setDefinitions map[string]*azutils.PolicySetDefinition
+}
+
+func (m policyAPIMock) GetPolicyAssignmentsForScope(_ string) ([]*azutils.PolicyAssignment, error) {
+	return m.assignments, m.assignmentsErr
+}
+
+func (m policyAPIMock) GetPolicyDefinition(id string) (*azutils.PolicyDefinition, error) {
+	return m.definitions[id], nil
+}
+
+func (m policyAPIMock) GetPolicySetDefinition(id string) (*azutils.PolicySetDefinition, error) {
+	return m.setDefinitions[id], nil
+}
+
+func policyAssignment(name, definitionID string, params map[string]any, notScopes ...string) *azutils.PolicyAssignment {
+	values := map[string]*azutils.PolicyParameterValue{}
+	for k, v := range params {
+		values[k] = &azutils.PolicyParameterValue{Value: v}
+	}
+	ns := []*string{}
+	for _, s := range notScopes {
+		ns = append(ns, util.Ptr(s))
+	}
+	return &azutils.PolicyAssignment{
+		Name: util.Ptr(name),
+		Properties: &azutils.PolicyAssignmentProperties{
+			PolicyDefinitionID: util.Ptr(definitionID),
+			Parameters:         values,
+			NotScopes:          ns,
+		},
+	}
+}
+
+func TestPolicyRuleService_ReconcilePolicyRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.PolicyRule
+		apiMock        policyAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	allowedLocationsID := "/providers/Microsoft.Authorization/policyDefinitions/allowed-locations"
+	allowedSKUsID := "/providers/Microsoft.Authorization/policyDefinitions/allowed-skus"
+	requireTagID := "/providers/Microsoft.Authorization/policyDefinitions/require-tag"
+	initiativeID := "/providers/Microsoft.Authorization/policySetDefinitions/initiative"
+
+	definitions := map[string]*azutils.PolicyDefinition{
+		allowedLocationsID: {
+			Name: util.Ptr("allowed-locations"),
+			Properties: &azutils.PolicyDefinitionProperties{
+				DisplayName: util.Ptr("Allowed locations"),
+				PolicyRule: &azutils.PolicyDefinitionRule{
+					If: policyCondition(t, `{"field": "location", "notIn": "[parameters('listOfAllowedLocations')]"}`),
+					Then: &azutils.PolicyDefinitionRuleEffect{
+						Effect: util.Ptr("deny"),
+					},
+				},
+			},
+		},
+		allowedSKUsID: {
+			Name: util.Ptr("allowed-skus"),
+			Properties: &azutils.PolicyDefinitionProperties{
+				DisplayName: util.Ptr("Allowed virtual machine size SKUs"),
+				Parameters: map[string]*azutils.PolicyParameterDefinition{
+					"effect": {DefaultValue: "Deny"},
+				},
+				PolicyRule: &azutils.PolicyDefinitionRule{
+					If: policyCondition(t, `{"allOf": [
+						{"field": "type", "equals": "Microsoft.Compute/virtualMachines"},
+						{"not": {"field": "Microsoft.Compute/virtualMachines/sku.name", "in": "[parameters('listOfAllowedSKUs')]"}}
+					]}`),
+					Then: &azutils.PolicyDefinitionRuleEffect{
+						Effect: util.Ptr("[parameters('effect')]"),
+					},
+				},
+			},
+		},
+		requireTagID: {
+			Name: util.Ptr("require-tag"),
+			Properties: &azutils.PolicyDefinitionProperties{
+				PolicyRule: &azutils.PolicyDefinitionRule{
+					If: policyCondition(t, `{"allOf": [
+						{"field": "[concat('tags[', parameters('tagName'), ']')]", "exists": "false"}
+					]}`),
+					Then: &azutils.PolicyDefinitionRuleEffect{
+						Effect: util.Ptr("deny"),
+					},
+				},
+			},
+		},
+	}
+	setDefinitions := map[string]*azutils.PolicySetDefinition{
+		initiativeID: {
+			Properties: &azutils.PolicySetDefinitionProperties{
+				Parameters: map[string]*azutils.PolicyParameterDefinition{
+					"skuEffect": {DefaultValue: "Audit"},
+				},
+				PolicyDefinitions: []*azutils.PolicyDefinitionReference{
+					{
+						PolicyDefinitionID: util.Ptr(allowedSKUsID),
+						Parameters: map[string]*azutils.PolicyParameterValue{
+							"effect":            {Value: "[parameters('skuEffect')]"},
+							"listOfAllowedSKUs": {Value: []any{"Standard_B2s"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	vm := v1alpha1.PolicyResource{
+		Type:     "Microsoft.Compute/virtualMachines",
+		Location: "eastus",
+		SKU:      "Standard_D4s_v5",
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (no policy assignments deny resource)",
+			rule: v1alpha1.PolicyRule{
+				RuleName:  "rule-1",
+				Scope:     "/subscriptions/sub/resourceGroups/rg1",
+				Resources: []v1alpha1.PolicyResource{vm},
+			},
+			apiMock: policyAPIMock{
+				assignments: []*azutils.PolicyAssignment{
+					policyAssignment("allowed-locations", allowedLocationsID, map[string]any{"listOfAllowedLocations": []any{"eastus"}}),
+					// Effect of the SKU policy defaults to "Audit" in the initiative.
+					policyAssignment("initiative", initiativeID, nil),
+					// Resource group is excluded from this assignment.
+					policyAssignment("allowed-locations-2", allowedLocationsID, map[string]any{"listOfAllowedLocations": []any{"westus"}}, "/subscriptions/sub/resourceGroups/RG1"),
+				},
+				definitions:    definitions,
+				setDefinitions: setDefinitions,
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-policy",
+					ValidationRule: "validation-rule-1",
+					Message:        "No policy assignments deny resources.",
+					Details: []string{
+						"No policy assignments deny resource; Resource: 'Microsoft.Compute/virtualMachines, location=eastus, sku=Standard_D4s_v5'",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (policy assignment and initiative deny resource)",
+			rule: v1alpha1.PolicyRule{
+				RuleName:  "rule-1",
+				Scope:     "/subscriptions/sub/resourceGroups/rg1",
+				Resources: []v1alpha1.PolicyResource{vm},
+			},
+			apiMock: policyAPIMock{
+				assignments: []*azutils.PolicyAssignment{
+					policyAssignment("allowed-locations", allowedLocationsID, map[string]any{"listOfAllowedLocations": []any{"westus"}}),
+					policyAssignment("initiative", initiativeID, map[string]any{"skuEffect": "Deny"}),
+				},
+				definitions:    definitions,
+				setDefinitions: setDefinitions,
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-policy",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more policy assignments deny resources. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Policy assignment 'allowed-locations' denies resource 'Microsoft.Compute/virtualMachines, location=eastus, sku=Standard_D4s_v5' with policy definition 'Allowed locations'; Effect: 'deny'",
+						"Policy assignment 'initiative' denies resource 'Microsoft.Compute/virtualMachines, location=eastus, sku=Standard_D4s_v5' with policy definition 'Allowed virtual machine size SKUs'; Effect: 'Deny'",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Pass (policy assignment conditions could not be fully evaluated)",
+			rule: v1alpha1.PolicyRule{
+				RuleName:  "rule-1",
+				Scope:     "/subscriptions/sub",
+				Resources: []v1alpha1.PolicyResource{vm},
+			},
+			apiMock: policyAPIMock{
+				assignments: []*azutils.PolicyAssignment{
+					policyAssignment("require-tag", requireTagID, map[string]any{"tagName": "costCenter"}),
+				},
+				definitions: definitions,
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-policy",
+					ValidationRule: "validation-rule-1",
+					Message:        "No policy assignments deny resources.",
+					Details: []string{
+						"Policy assignment 'require-tag' may deny resource 'Microsoft.Compute/virtualMachines, location=eastus, sku=Standard_D4s_v5' with policy definition 'require-tag', but its conditions could not be fully evaluated; Effect: 'deny'",
+						"No policy assignments deny resource; Resource: 'Microsoft.Compute/virtualMachines, location=eastus, sku=Standard_D4s_v5'",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (error getting policy assignments) - validation result remains passing, code returned to interprets error and changes result",
+			rule: v1alpha1.PolicyRule{
+				RuleName:  "rule-1",
+				Scope:     "/subscriptions/sub",
+				Resources: []v1alpha1.PolicyResource{vm},
+			},
+			apiMock: policyAPIMock{
+				// Can be any error message, just has to have this as substring.
+				assignmentsErr: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("scope /subscriptions/sub not found"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-policy",
+					ValidationRule: "validation-rule-1",
+					Message:        "No policy assignments deny resources.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewPolicyRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcilePolicyRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
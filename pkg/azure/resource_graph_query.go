@@ -0,0 +1,234 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	// maxListedRows is the maximum number of offending rows listed in the failures of a Resource
+	// Graph query rule, which keeps validation results readable for queries returning many rows.
+	maxListedRows = 100
+)
+
+var (
+	resourceGraphQueryRulePermissions = []string{
+		"Microsoft.ResourceGraph/resources/read",
+	}
+)
+
+// resourceGraphQueryAPI contains methods that allow getting all the information we need for an
+// Azure Resource Graph query.
+type resourceGraphQueryAPI interface {
+	QueryResources(query string, subscriptionIDs []string, managementGroup string) (*azutils.ResourceGraphQueryResult, error)
+}
+
+// ResourceGraphQueryRuleService reconciles Resource Graph query rules.
+type ResourceGraphQueryRuleService struct {
+	api resourceGraphQueryAPI
+	log logr.Logger
+}
+
+// NewResourceGraphQueryRuleService creates a new ResourceGraphQueryRuleService. Requires an Azure
+// client facade that supports running Azure Resource Graph queries.
+func NewResourceGraphQueryRuleService(api resourceGraphQueryAPI, log logr.Logger) *ResourceGraphQueryRuleService {
+	return &ResourceGraphQueryRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileResourceGraphQueryRule reconciles a Resource Graph query rule.
+func (s *ResourceGraphQueryRuleService) ReconcileResourceGraphQueryRule(rule v1alpha1.ResourceGraphQueryRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "Query result matches expectations."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeResourceGraphQuery
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	expectations, err := compileRowExpectations(rule.RowExpectations)
+	if err != nil {
+		return validationResult, err
+	}
+
+	result, err := s.api.QueryResources(rule.Query, rule.SubscriptionIDs, rule.ManagementGroup)
+	if err != nil {
+		return validationResult, fmt.Errorf("failed to run Resource Graph query: %w", azerr.AsAugmented(err, resourceGraphQueryRulePermissions))
+	}
+
+	latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Query returned %d row(s).", len(result.Rows)))
+	if result.Truncated {
+		latestCondition.Details = append(latestCondition.Details, "Warning: Azure Resource Graph truncated the query result, so not all rows were checked. Include the id column in the query's result to get all rows.")
+	}
+
+	// Rows are offending when there are too many of them or when they don't match the row
+	// expectations.
+	offending := make([]bool, len(result.Rows))
+	rowProblems := make([][]string, len(result.Rows))
+
+	if rule.RowCount != nil {
+		failure, tooMany := rowCountFailure(len(result.Rows), *rule.RowCount)
+		if failure != "" {
+			latestCondition.Failures = append(latestCondition.Failures, failure)
+		}
+		if tooMany {
+			for i := range offending {
+				offending[i] = true
+			}
+		}
+	}
+
+	for i, row := range result.Rows {
+		problems := expectations.problems(row)
+		if len(problems) > 0 {
+			offending[i] = true
+			rowProblems[i] = problems
+		}
+	}
+
+	listed, unlisted := 0, 0
+	for i, row := range result.Rows {
+		if !offending[i] {
+			continue
+		}
+		if listed == maxListedRows {
+			unlisted++
+			continue
+		}
+		listed++
+		rowJSON, err := json.Marshal(row)
+		if err != nil {
+			return validationResult, fmt.Errorf("failed to encode row: %w", err)
+		}
+		if len(rowProblems[i]) == 0 {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Row %s.", rowJSON))
+			continue
+		}
+		latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Row %s: %s.", rowJSON, strings.Join(rowProblems[i], "; ")))
+	}
+	if unlisted > 0 {
+		latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("%d more offending row(s) not listed.", unlisted))
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "Query result doesn't match expectations. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// rowCountFailure checks the number of rows returned by a query against a row count expectation.
+// It returns a failure, or an empty string if the expectation is met, and whether there were too
+// many rows, which makes all of them offending.
+func rowCountFailure(count int, expected v1alpha1.ResourceGraphRowCount) (string, bool) {
+	if expected.Equals != nil && count != int(*expected.Equals) {
+		return fmt.Sprintf("Query returned %d row(s), expected %d.", count, *expected.Equals), count > int(*expected.Equals)
+	}
+	if expected.Min != nil && count < int(*expected.Min) {
+		return fmt.Sprintf("Query returned %d row(s), expected at least %d.", count, *expected.Min), false
+	}
+	if expected.Max != nil && count > int(*expected.Max) {
+		return fmt.Sprintf("Query returned %d row(s), expected at most %d.", count, *expected.Max), true
+	}
+	return "", false
+}
+
+// rowExpectation is a row expectation with its pattern compiled.
+type rowExpectation struct {
+	v1alpha1.ResourceGraphRowExpectation
+	pattern *regexp.Regexp
+}
+
+// rowExpectations are row expectations with their patterns compiled, so that the patterns are
+// compiled once per rule instead of once per row.
+type rowExpectations []rowExpectation
+
+// compileRowExpectations compiles the patterns of row expectations. Returns an error if an
+// expectation's pattern isn't a valid regular expression.
+func compileRowExpectations(expectations []v1alpha1.ResourceGraphRowExpectation) (rowExpectations, error) {
+	compiled := make(rowExpectations, 0, len(expectations))
+	for _, e := range expectations {
+		c := rowExpectation{ResourceGraphRowExpectation: e}
+		if e.Pattern != "" {
+			re, err := regexp.Compile(e.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern for field %s: %w", e.Field, err)
+			}
+			c.pattern = re
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// problems returns the reasons a row doesn't match the row expectations.
+func (expectations rowExpectations) problems(row map[string]any) []string {
+	problems := []string{}
+	for _, e := range expectations {
+		value, ok := fieldValue(row, e.Field)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("missing field '%s'", e.Field))
+			continue
+		}
+		if e.Value != "" && value != e.Value {
+			problems = append(problems, fmt.Sprintf("field '%s' has value '%s', expected '%s'", e.Field, value, e.Value))
+		}
+		if len(e.AllowedValues) > 0 && !slices.Contains(e.AllowedValues, value) {
+			problems = append(problems, fmt.Sprintf("field '%s' has value '%s', expected one of '%s'", e.Field, value, strings.Join(e.AllowedValues, "', '")))
+		}
+		if e.pattern != nil && !e.pattern.MatchString(value) {
+			problems = append(problems, fmt.Sprintf("field '%s' has value '%s', which doesn't match pattern '%s'", e.Field, value, e.Pattern))
+		}
+	}
+	return problems
+}
+
+// fieldValue gets the value of a field of a row, following dots into columns holding objects.
+// Values that aren't strings are returned as JSON. Returns false if the field is missing or null.
+func fieldValue(row map[string]any, field string) (string, bool) {
+	var value any = row
+	for _, part := range strings.Split(field, ".") {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if value, ok = obj[part]; !ok {
+			return "", false
+		}
+	}
+	if value == nil {
+		return "", false
+	}
+	if s, ok := value.(string); ok {
+		return s, true
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
@@ -0,0 +1,211 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type resourceGraphQueryAPIMock struct {
+	result *azutils.ResourceGraphQueryResult
+	err    error
+}
+
+func (m resourceGraphQueryAPIMock) QueryResources(_ string, _ []string, _ string) (*azutils.ResourceGraphQueryResult, error) {
+	return m.result, m.err
+}
+
+func TestResourceGraphQueryRuleService_ReconcileResourceGraphQueryRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.ResourceGraphQueryRule
+		apiMock        resourceGraphQueryAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	query := "Resources | where type =~ 'microsoft.compute/disks' | project name, diskSizeGB = properties.diskSizeGB, sku"
+
+	smallDisk := map[string]any{"name": "disk-1", "diskSizeGB": float64(128), "sku": map[string]any{"name": "Premium_LRS"}}
+	largeDisk := map[string]any{"name": "disk-2", "diskSizeGB": float64(2048), "sku": map[string]any{"name": "Standard_LRS"}}
+	unknownDisk := map[string]any{"name": "disk-3", "diskSizeGB": nil}
+
+	testCases := []testCase{
+		{
+			name: "Pass (row count and row expectations met)",
+			rule: v1alpha1.ResourceGraphQueryRule{
+				RuleName:        "rule-1",
+				Query:           query,
+				SubscriptionIDs: []string{"sub"},
+				RowCount:        &v1alpha1.ResourceGraphRowCount{Min: util.Ptr(int32(1)), Max: util.Ptr(int32(2))},
+				RowExpectations: []v1alpha1.ResourceGraphRowExpectation{
+					{Field: "sku.name", AllowedValues: []string{"Premium_LRS", "Standard_LRS"}},
+					{Field: "diskSizeGB", Pattern: "^[0-9]+$"},
+				},
+			},
+			apiMock: resourceGraphQueryAPIMock{
+				result: &azutils.ResourceGraphQueryResult{Rows: []map[string]any{smallDisk, largeDisk}},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-resource-graph-query",
+					ValidationRule: "validation-rule-1",
+					Message:        "Query result matches expectations.",
+					Details:        []string{"Query returned 2 row(s)."},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (too many rows)",
+			rule: v1alpha1.ResourceGraphQueryRule{
+				RuleName:        "rule-1",
+				Query:           query,
+				ManagementGroup: "mg",
+				RowCount:        &v1alpha1.ResourceGraphRowCount{Max: util.Ptr(int32(0))},
+			},
+			apiMock: resourceGraphQueryAPIMock{
+				result: &azutils.ResourceGraphQueryResult{Rows: []map[string]any{smallDisk}, Truncated: true},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-resource-graph-query",
+					ValidationRule: "validation-rule-1",
+					Message:        "Query result doesn't match expectations. See failures for details.",
+					Details: []string{
+						"Query returned 1 row(s).",
+						"Warning: Azure Resource Graph truncated the query result, so not all rows were checked. Include the id column in the query's result to get all rows.",
+					},
+					Failures: []string{
+						"Query returned 1 row(s), expected at most 0.",
+						`Row {"diskSizeGB":128,"name":"disk-1","sku":{"name":"Premium_LRS"}}.`,
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (too few rows)",
+			rule: v1alpha1.ResourceGraphQueryRule{
+				RuleName:        "rule-1",
+				Query:           query,
+				SubscriptionIDs: []string{"sub"},
+				RowCount:        &v1alpha1.ResourceGraphRowCount{Equals: util.Ptr(int32(3))},
+			},
+			apiMock: resourceGraphQueryAPIMock{
+				result: &azutils.ResourceGraphQueryResult{Rows: []map[string]any{smallDisk, largeDisk}},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-resource-graph-query",
+					ValidationRule: "validation-rule-1",
+					Message:        "Query result doesn't match expectations. See failures for details.",
+					Details:        []string{"Query returned 2 row(s)."},
+					Failures:       []string{"Query returned 2 row(s), expected 3."},
+					Status:         corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (rows don't match row expectations)",
+			rule: v1alpha1.ResourceGraphQueryRule{
+				RuleName:        "rule-1",
+				Query:           query,
+				SubscriptionIDs: []string{"sub"},
+				RowExpectations: []v1alpha1.ResourceGraphRowExpectation{
+					{Field: "sku.name", Value: "Premium_LRS"},
+					{Field: "diskSizeGB", Pattern: "^[0-9]{1,3}$"},
+				},
+			},
+			apiMock: resourceGraphQueryAPIMock{
+				result: &azutils.ResourceGraphQueryResult{Rows: []map[string]any{smallDisk, largeDisk, unknownDisk}},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-resource-graph-query",
+					ValidationRule: "validation-rule-1",
+					Message:        "Query result doesn't match expectations. See failures for details.",
+					Details:        []string{"Query returned 3 row(s)."},
+					Failures: []string{
+						`Row {"diskSizeGB":2048,"name":"disk-2","sku":{"name":"Standard_LRS"}}: field 'sku.name' has value 'Standard_LRS', expected 'Premium_LRS'; field 'diskSizeGB' has value '2048', which doesn't match pattern '^[0-9]{1,3}$'.`,
+						`Row {"diskSizeGB":null,"name":"disk-3"}: missing field 'sku.name'; missing field 'diskSizeGB'.`,
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Error (invalid query)",
+			rule: v1alpha1.ResourceGraphQueryRule{
+				RuleName:        "rule-1",
+				Query:           "Resources | whre",
+				SubscriptionIDs: []string{"sub"},
+				RowCount:        &v1alpha1.ResourceGraphRowCount{Max: util.Ptr(int32(0))},
+			},
+			apiMock: resourceGraphQueryAPIMock{
+				err: errors.New("RESPONSE 400: InvalidQuery"),
+			},
+			expectedError: errors.New("failed to run Resource Graph query: RESPONSE 400: InvalidQuery"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-resource-graph-query",
+					ValidationRule: "validation-rule-1",
+					Message:        "Query result matches expectations.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Error (invalid row expectation pattern with no rows)",
+			rule: v1alpha1.ResourceGraphQueryRule{
+				RuleName:        "rule-1",
+				Query:           "Resources | where type =~ 'microsoft.compute/disks'",
+				SubscriptionIDs: []string{"sub"},
+				RowExpectations: []v1alpha1.ResourceGraphRowExpectation{
+					{Field: "diskSizeGB", Pattern: "^[0-9+$"},
+				},
+			},
+			apiMock: resourceGraphQueryAPIMock{
+				result: &azutils.ResourceGraphQueryResult{Rows: []map[string]any{}},
+			},
+			expectedError: errors.New("invalid pattern for field diskSizeGB: error parsing regexp: missing closing ]: `[0-9+$`"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-resource-graph-query",
+					ValidationRule: "validation-rule-1",
+					Message:        "Query result matches expectations.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		svc := NewResourceGraphQueryRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileResourceGraphQueryRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
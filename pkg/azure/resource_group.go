@@ -0,0 +1,124 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	// resourceGroupProvisioningStateDeleting is the provisioning state of resource groups that are
+	// being deleted.
+	resourceGroupProvisioningStateDeleting = "Deleting"
+)
+
+var (
+	resourceGroupRulePermissions = []string{
+		"Microsoft.Resources/subscriptions/resourceGroups/read",
+	}
+)
+
+// resourceGroupAPI contains methods that allow getting all the information we need for resource
+// groups.
+type resourceGroupAPI interface {
+	GetResourceGroup(name, subscriptionID string) (*armresources.ResourceGroup, error)
+}
+
+// ResourceGroupRuleService reconciles resource group rules.
+type ResourceGroupRuleService struct {
+	api resourceGroupAPI
+}
+
+// NewResourceGroupRuleService creates a new ResourceGroupRuleService. Requires an Azure client
+// facade that supports getting resource groups.
+func NewResourceGroupRuleService(api resourceGroupAPI) *ResourceGroupRuleService {
+	return &ResourceGroupRuleService{
+		api: api,
+	}
+}
+
+// ReconcileResourceGroupRule reconciles a resource group rule.
+func (s *ResourceGroupRuleService) ReconcileResourceGroupRule(rule v1alpha1.ResourceGroupRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "Resource groups present with required location and tags."
+	if rule.Absent {
+		latestCondition.Message = "Resource groups not present."
+	}
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeResourceGroup
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	tagReqs, err := compileTagRequirements(rule.Tags)
+	if err != nil {
+		return validationResult, err
+	}
+
+	for _, name := range rule.ResourceGroups {
+		rg, err := s.api.GetResourceGroup(name, rule.SubscriptionID)
+		if err != nil {
+			if azerr.IsNotFound(err) {
+				if !rule.Absent {
+					latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Resource group '%s' not found using subscription %s.", name, rule.SubscriptionID))
+				}
+				continue
+			}
+			return validationResult, fmt.Errorf("failed to get resource group: %w", azerr.AsAugmented(err, resourceGroupRulePermissions))
+		}
+		if rule.Absent {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Resource group '%s' present, expected it not to exist.", name))
+			continue
+		}
+		processResourceGroup(rule, tagReqs, name, rg, &latestCondition.Failures)
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "One or more resource groups missing or lack required location or tags. See failures for details."
+		if rule.Absent {
+			latestCondition.Message = "One or more resource groups present. See failures for details."
+		}
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// processResourceGroup checks the provisioning state, location, and tags of a resource group
+// against the rule.
+func processResourceGroup(rule v1alpha1.ResourceGroupRule, tagReqs tagRequirements, name string, rg *armresources.ResourceGroup, failures *[]string) {
+	if rg.Properties != nil && rg.Properties.ProvisioningState != nil &&
+		strings.EqualFold(*rg.Properties.ProvisioningState, resourceGroupProvisioningStateDeleting) {
+		*failures = append(*failures, fmt.Sprintf("Resource group '%s' is being deleted.", name))
+	}
+
+	if rule.Location != "" {
+		location := ""
+		if rg.Location != nil {
+			location = *rg.Location
+		}
+		if normalizeRegion(location) != normalizeRegion(rule.Location) {
+			*failures = append(*failures, fmt.Sprintf("Resource group '%s' in location '%s', expected '%s'.", name, location, rule.Location))
+		}
+	}
+
+	for _, p := range tagReqs.problems(rg.Tags) {
+		*failures = append(*failures, fmt.Sprintf("Resource group '%s' %s.", name, p))
+	}
+}
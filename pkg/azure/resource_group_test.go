@@ -0,0 +1,211 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type resourceGroupAPIMock struct {
+	// keyed by name
+	data map[string]*armresources.ResourceGroup
+	err  error
+}
+
+func (m resourceGroupAPIMock) GetResourceGroup(name, _ string) (*armresources.ResourceGroup, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	rg, ok := m.data[name]
+	if !ok {
+		// Can be any error message, just has to have this as substring.
+		return nil, errors.New("RESPONSE 404")
+	}
+	return rg, nil
+}
+
+func resourceGroup(location, provisioningState string, tags map[string]string) *armresources.ResourceGroup {
+	t := map[string]*string{}
+	for k, v := range tags {
+		t[k] = util.Ptr(v)
+	}
+	return &armresources.ResourceGroup{
+		Location: util.Ptr(location),
+		Tags:     t,
+		Properties: &armresources.ResourceGroupProperties{
+			ProvisioningState: util.Ptr(provisioningState),
+		},
+	}
+}
+
+func TestResourceGroupRuleService_ReconcileResourceGroupRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.ResourceGroupRule
+		apiMock        resourceGroupAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	tags := []v1alpha1.TagRequirement{
+		{Key: "env", Value: "prod"},
+		{Key: "owner", Pattern: "^team-.+$"},
+		{Key: "costCenter"},
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (resource groups present with required location and tags)",
+			rule: v1alpha1.ResourceGroupRule{
+				RuleName:       "rule-1",
+				ResourceGroups: []string{"rg1", "rg2"},
+				Location:       "East US",
+				Tags:           tags,
+				SubscriptionID: "sub",
+			},
+			apiMock: resourceGroupAPIMock{
+				data: map[string]*armresources.ResourceGroup{
+					"rg1": resourceGroup("eastus", "Succeeded", map[string]string{"env": "prod", "owner": "team-a", "costCenter": "1"}),
+					// Tag names are compared case-insensitively.
+					"rg2": resourceGroup("eastus", "Succeeded", map[string]string{"ENV": "prod", "Owner": "team-b", "costcenter": "2"}),
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-resource-group",
+					ValidationRule: "validation-rule-1",
+					Message:        "Resource groups present with required location and tags.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (resource groups missing, being deleted, in wrong location, or lack tags)",
+			rule: v1alpha1.ResourceGroupRule{
+				RuleName:       "rule-1",
+				ResourceGroups: []string{"rg1", "rg2", "rg3"},
+				Location:       "eastus",
+				Tags:           tags,
+				SubscriptionID: "sub",
+			},
+			apiMock: resourceGroupAPIMock{
+				data: map[string]*armresources.ResourceGroup{
+					"rg1": resourceGroup("westus", "Deleting", map[string]string{"env": "prod", "owner": "team-a", "costCenter": "1"}),
+					"rg2": resourceGroup("eastus", "Succeeded", map[string]string{"env": "dev", "owner": "someone"}),
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-resource-group",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more resource groups missing or lack required location or tags. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Resource group 'rg1' is being deleted.",
+						"Resource group 'rg1' in location 'westus', expected 'eastus'.",
+						"Resource group 'rg2' tag 'env' has value 'dev', expected 'prod'.",
+						"Resource group 'rg2' tag 'owner' has value 'someone', which doesn't match pattern '^team-.+$'.",
+						"Resource group 'rg2' missing tag 'costCenter'.",
+						"Resource group 'rg3' not found using subscription sub.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (resource group present when it must not exist)",
+			rule: v1alpha1.ResourceGroupRule{
+				RuleName:       "rule-1",
+				ResourceGroups: []string{"rg1", "rg2"},
+				Absent:         true,
+				SubscriptionID: "sub",
+			},
+			apiMock: resourceGroupAPIMock{
+				data: map[string]*armresources.ResourceGroup{
+					"rg1": resourceGroup("eastus", "Succeeded", nil),
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-resource-group",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more resource groups present. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Resource group 'rg1' present, expected it not to exist.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (error getting resource group) - validation result remains passing, code returned to interprets error and changes result",
+			rule: v1alpha1.ResourceGroupRule{
+				RuleName:       "rule-1",
+				ResourceGroups: []string{"rg1"},
+				SubscriptionID: "sub",
+			},
+			apiMock: resourceGroupAPIMock{
+				err: errors.New("RESPONSE 403"),
+			},
+			expectedError: errors.New("failed to get resource group: RESPONSE 403"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-resource-group",
+					ValidationRule: "validation-rule-1",
+					Message:        "Resource groups present with required location and tags.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (invalid tag pattern) - resource groups not requested, validation result remains passing, code returned to interprets error and changes result",
+			rule: v1alpha1.ResourceGroupRule{
+				RuleName:       "rule-1",
+				ResourceGroups: []string{"rg1"},
+				Tags:           []v1alpha1.TagRequirement{{Key: "owner", Pattern: "team-("}},
+				SubscriptionID: "sub",
+			},
+			apiMock: resourceGroupAPIMock{
+				err: errors.New("RESPONSE 403"),
+			},
+			expectedError: errors.New("invalid pattern for tag owner: error parsing regexp: missing closing ): `team-(`"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-resource-group",
+					ValidationRule: "validation-rule-1",
+					Message:        "Resource groups present with required location and tags.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewResourceGroupRuleService(tc.apiMock)
+		result, err := svc.ReconcileResourceGroupRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
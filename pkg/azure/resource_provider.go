@@ -0,0 +1,110 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	// registrationStateRegistered is the registration state of a resource provider that has
+	// finished registering in a subscription.
+	registrationStateRegistered = "Registered"
+)
+
+var (
+	resourceProviderRulePermissions = []string{
+		"Microsoft.Resources/subscriptions/providers/read",
+	}
+)
+
+// resourceProviderAPI contains methods that allow getting all the information we need for the
+// resource providers of a subscription.
+type resourceProviderAPI interface {
+	GetProvidersForSubscription(subscriptionID string) ([]*armresources.Provider, error)
+}
+
+// ResourceProviderRuleService reconciles resource provider rules.
+type ResourceProviderRuleService struct {
+	api resourceProviderAPI
+	log logr.Logger
+}
+
+// NewResourceProviderRuleService creates a new ResourceProviderRuleService. Requires an Azure
+// client facade that supports getting all resource providers for a subscription.
+func NewResourceProviderRuleService(api resourceProviderAPI, log logr.Logger) *ResourceProviderRuleService {
+	return &ResourceProviderRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileResourceProviderRule reconciles a resource provider rule.
+func (s *ResourceProviderRuleService) ReconcileResourceProviderRule(rule v1alpha1.ResourceProviderRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "namespaces", rule.Namespaces, "subscription", rule.SubscriptionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "All required resource providers registered in subscription."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeResourceProvider
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	providers, err := s.api.GetProvidersForSubscription(rule.SubscriptionID)
+	if err != nil {
+		return validationResult, fmt.Errorf("failed to get resource providers for subscription: %w", azerr.AsAugmented(err, resourceProviderRulePermissions))
+	}
+
+	// Resource provider namespaces are case insensitive in Azure, so we key the map by lowercase
+	// namespace.
+	registrationStates := map[string]string{}
+	for _, provider := range providers {
+		if provider == nil || provider.Namespace == nil {
+			log.Error(nil, "Resource provider namespace in API response was nil.")
+			continue
+		}
+		registrationState := ""
+		if provider.RegistrationState != nil {
+			registrationState = *provider.RegistrationState
+		}
+		registrationStates[strings.ToLower(*provider.Namespace)] = registrationState
+	}
+
+	for _, namespace := range rule.Namespaces {
+		registrationState, ok := registrationStates[strings.ToLower(namespace)]
+		if !ok {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Resource provider '%s' not found in subscription.", namespace))
+			continue
+		}
+		if registrationState != registrationStateRegistered {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Resource provider '%s' not registered in subscription; Registration state: '%s'", namespace, registrationState))
+			continue
+		}
+		latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Found registered resource provider; Namespace: '%s'", namespace))
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "One or more required resource providers not registered in subscription. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
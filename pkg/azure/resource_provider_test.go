@@ -0,0 +1,140 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type resourceProviderAPIMock struct {
+	data []*armresources.Provider
+	err  error
+}
+
+func (m resourceProviderAPIMock) GetProvidersForSubscription(_ string) ([]*armresources.Provider, error) {
+	return m.data, m.err
+}
+
+func TestResourceProviderRuleService_ReconcileResourceProviderRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.ResourceProviderRule
+		apiMock        resourceProviderAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (all required resource providers registered, matched case insensitively)",
+			rule: v1alpha1.ResourceProviderRule{
+				RuleName:       "rule-1",
+				Namespaces:     []string{"Microsoft.Compute", "microsoft.network"},
+				SubscriptionID: "sub",
+			},
+			apiMock: resourceProviderAPIMock{
+				data: []*armresources.Provider{
+					{
+						Namespace:         util.Ptr("Microsoft.Compute"),
+						RegistrationState: util.Ptr("Registered"),
+					},
+					{
+						Namespace:         util.Ptr("Microsoft.Network"),
+						RegistrationState: util.Ptr("Registered"),
+					},
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-resource-provider",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required resource providers registered in subscription.",
+					Details: []string{
+						"Found registered resource provider; Namespace: 'Microsoft.Compute'",
+						"Found registered resource provider; Namespace: 'microsoft.network'",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (one resource provider not registered and one not found)",
+			rule: v1alpha1.ResourceProviderRule{
+				RuleName:       "rule-1",
+				Namespaces:     []string{"Microsoft.Compute", "Microsoft.ContainerService", "Microsoft.Network"},
+				SubscriptionID: "sub",
+			},
+			apiMock: resourceProviderAPIMock{
+				data: []*armresources.Provider{
+					{},
+					{
+						Namespace:         util.Ptr("Microsoft.Compute"),
+						RegistrationState: util.Ptr("Registered"),
+					},
+					{
+						Namespace:         util.Ptr("Microsoft.ContainerService"),
+						RegistrationState: util.Ptr("NotRegistered"),
+					},
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-resource-provider",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more required resource providers not registered in subscription. See failures for details.",
+					Details: []string{
+						"Found registered resource provider; Namespace: 'Microsoft.Compute'",
+					},
+					Failures: []string{
+						"Resource provider 'Microsoft.ContainerService' not registered in subscription; Registration state: 'NotRegistered'",
+						"Resource provider 'Microsoft.Network' not found in subscription.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (error getting resource providers) - validation result remains passing, code returned to interprets error and changes result",
+			rule: v1alpha1.ResourceProviderRule{
+				RuleName:       "rule-1",
+				Namespaces:     []string{"Microsoft.Compute"},
+				SubscriptionID: "sub",
+			},
+			apiMock: resourceProviderAPIMock{
+				err: errors.New("get providers failed"),
+			},
+			expectedError: errors.New("failed to get resource providers for subscription: get providers failed"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-resource-provider",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required resource providers registered in subscription.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewResourceProviderRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileResourceProviderRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
@@ -0,0 +1,208 @@
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+var (
+	roleDefinitionRulePermissions = []string{
+		"Microsoft.Authorization/roleDefinitions/read",
+	}
+)
+
+// roleDefinitionLookupAPI contains methods that allow getting role definitions by ID or by role
+// name.
+type roleDefinitionLookupAPI interface {
+	GetByID(roleID string) (*armauthorization.RoleDefinition, error)
+	GetByRoleName(scope, roleName string) ([]*armauthorization.RoleDefinition, error)
+}
+
+// RoleDefinitionRuleService reconciles role definition rules.
+type RoleDefinitionRuleService struct {
+	api roleDefinitionLookupAPI
+	log logr.Logger
+}
+
+// NewRoleDefinitionRuleService creates a new RoleDefinitionRuleService. Requires an Azure client
+// facade that supports getting role definitions by ID and by role name.
+func NewRoleDefinitionRuleService(api roleDefinitionLookupAPI, log logr.Logger) *RoleDefinitionRuleService {
+	return &RoleDefinitionRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileRoleDefinitionRule reconciles a role definition rule.
+func (s *RoleDefinitionRuleService) ReconcileRoleDefinitionRule(rule v1alpha1.RoleDefinitionRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "scope", rule.Scope, "roleName", rule.RoleName, "roleDefinitionId", rule.RoleDefinitionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "Role definition matches expected permissions and assignable scopes."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeRoleDefinition
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	roleDefinition, err := s.getRoleDefinition(rule)
+	if err != nil {
+		return validationResult, err
+	}
+	if roleDefinition.Properties == nil {
+		return validationResult, errors.New("role definition properties in API response nil")
+	}
+
+	var actions, notActions, dataActions, notDataActions []*string
+	for _, p := range roleDefinition.Properties.Permissions {
+		if p == nil {
+			log.Error(nil, "Role definition permission in API response was nil.")
+			continue
+		}
+		actions = append(actions, p.Actions...)
+		notActions = append(notActions, p.NotActions...)
+		dataActions = append(dataActions, p.DataActions...)
+		notDataActions = append(notDataActions, p.NotDataActions...)
+	}
+	processRoleDefinitionActions("Action", rule.Actions, actions, &latestCondition.Failures)
+	processRoleDefinitionActions("NotAction", rule.NotActions, notActions, &latestCondition.Failures)
+	processRoleDefinitionActions("DataAction", rule.DataActions, dataActions, &latestCondition.Failures)
+	processRoleDefinitionActions("NotDataAction", rule.NotDataActions, notDataActions, &latestCondition.Failures)
+
+	if len(rule.AssignableScopes) > 0 {
+		processAssignableScopes(rule.AssignableScopes, roleDefinition.Properties.AssignableScopes, &latestCondition.Failures)
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "Role definition has drifted from expected permissions or assignable scopes. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// getRoleDefinition gets the role definition from the rule, by ID or by role name.
+func (s *RoleDefinitionRuleService) getRoleDefinition(rule v1alpha1.RoleDefinitionRule) (*armauthorization.RoleDefinition, error) {
+	if rule.RoleDefinitionID != "" {
+		id := fmt.Sprintf("%s/providers/Microsoft.Authorization/roleDefinitions/%s", strings.TrimSuffix(rule.Scope, "/"), rule.RoleDefinitionID)
+		roleDefinition, err := s.api.GetByID(id)
+		if err != nil {
+			if azerr.IsNotFound(err) {
+				return nil, fmt.Errorf("role definition %s not found at scope %s", rule.RoleDefinitionID, rule.Scope)
+			}
+			return nil, fmt.Errorf("failed to get role definition: %w", azerr.AsAugmented(err, roleDefinitionRulePermissions))
+		}
+		return roleDefinition, nil
+	}
+
+	roleDefinitions, err := s.api.GetByRoleName(rule.Scope, rule.RoleName)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return nil, fmt.Errorf("scope %s not found", rule.Scope)
+		}
+		return nil, fmt.Errorf("failed to get role definitions: %w", azerr.AsAugmented(err, roleDefinitionRulePermissions))
+	}
+	if len(roleDefinitions) == 0 || roleDefinitions[0] == nil {
+		return nil, fmt.Errorf("role definition with role name %s not found at scope %s", rule.RoleName, rule.Scope)
+	}
+	return roleDefinitions[0], nil
+}
+
+// processRoleDefinitionActions compares a list of actions from the role definition with the
+// expected list. Expected actions are missing when the role definition neither has them nor has a
+// wildcard action that matches them, and actions of the role definition are added when the
+// expected list neither has them nor has a wildcard action that matches them. Actions are
+// compared case-insensitively, like Azure does.
+func processRoleDefinitionActions(kind string, expected []v1alpha1.ActionStr, actual []*string, failures *[]string) {
+	expectedLower := []string{}
+	for _, a := range expected {
+		expectedLower = append(expectedLower, strings.ToLower(string(a)))
+	}
+	actualOrig := []string{}
+	actualLower := []string{}
+	for _, a := range actual {
+		if a != nil {
+			actualOrig = append(actualOrig, *a)
+			actualLower = append(actualLower, strings.ToLower(*a))
+		}
+	}
+
+	for i, a := range expectedLower {
+		if !actionCovered(a, actualLower) {
+			*failures = append(*failures, fmt.Sprintf("%s '%s' missing from role definition.", kind, expected[i]))
+		}
+	}
+	for i, a := range actualLower {
+		if !actionCovered(a, expectedLower) {
+			*failures = append(*failures, fmt.Sprintf("%s '%s' added to role definition.", kind, actualOrig[i]))
+		}
+	}
+}
+
+// actionCovered returns whether an action is in a list of actions or, when it has no wildcards, is
+// matched by a wildcard action in the list.
+func actionCovered(action string, actions []string) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	if numWildcards(action) > 0 {
+		return false
+	}
+	comparable := []string{}
+	for _, a := range actions {
+		// The wildcard matching used for role assignments supports at most one wildcard per
+		// action.
+		if numWildcards(a) <= 1 {
+			comparable = append(comparable, a)
+		}
+	}
+	matches, _ := candidateActionMatches(action, comparable)
+	return matches
+}
+
+// processAssignableScopes compares the assignable scopes of the role definition with the expected
+// list. Scopes are compared case-insensitively, ignoring trailing slashes.
+func processAssignableScopes(expected []string, actual []*string, failures *[]string) {
+	normalize := func(scope string) string {
+		return strings.ToLower(strings.TrimSuffix(scope, "/"))
+	}
+	actualScopes := map[string]bool{}
+	for _, s := range actual {
+		if s != nil {
+			actualScopes[normalize(*s)] = true
+		}
+	}
+	expectedScopes := map[string]bool{}
+	for _, s := range expected {
+		expectedScopes[normalize(s)] = true
+		if !actualScopes[normalize(s)] {
+			*failures = append(*failures, fmt.Sprintf("Assignable scope '%s' missing from role definition.", s))
+		}
+	}
+	for _, s := range actual {
+		if s != nil && !expectedScopes[normalize(*s)] {
+			*failures = append(*failures, fmt.Sprintf("Assignable scope '%s' added to role definition.", *s))
+		}
+	}
+}
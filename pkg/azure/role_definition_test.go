@@ -0,0 +1,205 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type roleDefinitionLookupAPIMock struct {
+	roleDefinition *armauthorization.RoleDefinition
+	err            error
+}
+
+func (m roleDefinitionLookupAPIMock) GetByID(_ string) (*armauthorization.RoleDefinition, error) {
+	return m.roleDefinition, m.err
+}
+
+func (m roleDefinitionLookupAPIMock) GetByRoleName(_, _ string) ([]*armauthorization.RoleDefinition, error) {
+	if m.roleDefinition == nil {
+		return []*armauthorization.RoleDefinition{}, m.err
+	}
+	return []*armauthorization.RoleDefinition{m.roleDefinition}, m.err
+}
+
+func stringPtrs(strs ...string) []*string {
+	p := []*string{}
+	for _, s := range strs {
+		p = append(p, util.Ptr(s))
+	}
+	return p
+}
+
+func TestRoleDefinitionRuleService_ReconcileRoleDefinitionRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.RoleDefinitionRule
+		apiMock        roleDefinitionLookupAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	rule := v1alpha1.RoleDefinitionRule{
+		RuleName: "rule-1",
+		Scope:    "/subscriptions/sub",
+		RoleName: "Validator Plugin",
+		Actions: []v1alpha1.ActionStr{
+			"Microsoft.Compute/*/read",
+			"Microsoft.Network/virtualNetworks/read",
+			"Microsoft.Network/virtualNetworks/subnets/join/action",
+		},
+		NotActions:       []v1alpha1.ActionStr{"Microsoft.Compute/snapshots/read"},
+		DataActions:      []v1alpha1.ActionStr{"Microsoft.KeyVault/vaults/secrets/getSecret/action"},
+		AssignableScopes: []string{"/subscriptions/sub"},
+	}
+
+	roleDefinition := func(actions, notActions, dataActions, assignableScopes []*string) *armauthorization.RoleDefinition {
+		return &armauthorization.RoleDefinition{
+			Properties: &armauthorization.RoleDefinitionProperties{
+				AssignableScopes: assignableScopes,
+				Permissions: []*armauthorization.Permission{
+					{
+						Actions:        actions,
+						NotActions:     notActions,
+						DataActions:    dataActions,
+						NotDataActions: []*string{},
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (role definition matches, with actions differing only in case)",
+			rule: rule,
+			apiMock: roleDefinitionLookupAPIMock{
+				roleDefinition: roleDefinition(
+					stringPtrs("microsoft.compute/*/read", "Microsoft.Network/virtualNetworks/read", "Microsoft.Network/virtualNetworks/subnets/join/action"),
+					stringPtrs("Microsoft.Compute/snapshots/read"),
+					stringPtrs("Microsoft.KeyVault/vaults/secrets/getSecret/action"),
+					stringPtrs("/subscriptions/sub/"),
+				),
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-role-definition",
+					ValidationRule: "validation-rule-1",
+					Message:        "Role definition matches expected permissions and assignable scopes.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (role definition edited)",
+			rule: rule,
+			apiMock: roleDefinitionLookupAPIMock{
+				roleDefinition: roleDefinition(
+					// The wildcard action covers the expected virtual network actions, but it's
+					// broader than expected.
+					stringPtrs("Microsoft.Network/*", "Microsoft.Storage/storageAccounts/listKeys/action"),
+					stringPtrs(),
+					stringPtrs("Microsoft.KeyVault/vaults/secrets/getSecret/action"),
+					stringPtrs("/subscriptions/sub", "/subscriptions/other-sub"),
+				),
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-role-definition",
+					ValidationRule: "validation-rule-1",
+					Message:        "Role definition has drifted from expected permissions or assignable scopes. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Action 'Microsoft.Compute/*/read' missing from role definition.",
+						"Action 'Microsoft.Network/*' added to role definition.",
+						"Action 'Microsoft.Storage/storageAccounts/listKeys/action' added to role definition.",
+						"NotAction 'Microsoft.Compute/snapshots/read' missing from role definition.",
+						"Assignable scope '/subscriptions/other-sub' added to role definition.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name:          "Fail (role definition not found by role name) - validation result remains passing, code returned to interprets error and changes result",
+			rule:          rule,
+			apiMock:       roleDefinitionLookupAPIMock{},
+			expectedError: errors.New("role definition with role name Validator Plugin not found at scope /subscriptions/sub"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-role-definition",
+					ValidationRule: "validation-rule-1",
+					Message:        "Role definition matches expected permissions and assignable scopes.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (error getting role definition by ID) - validation result remains passing, code returned to interprets error and changes result",
+			rule: v1alpha1.RoleDefinitionRule{
+				RuleName:         "rule-1",
+				Scope:            "/subscriptions/sub",
+				RoleDefinitionID: "role_id",
+			},
+			apiMock: roleDefinitionLookupAPIMock{
+				// Can be any error message, just has to have this as substring.
+				err: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("role definition role_id not found at scope /subscriptions/sub"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-role-definition",
+					ValidationRule: "validation-rule-1",
+					Message:        "Role definition matches expected permissions and assignable scopes.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (role definition properties nil) - validation result remains passing, code returned to interprets error and changes result",
+			rule: rule,
+			apiMock: roleDefinitionLookupAPIMock{
+				roleDefinition: &armauthorization.RoleDefinition{ID: util.Ptr("role_id")},
+			},
+			expectedError: errors.New("role definition properties in API response nil"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-role-definition",
+					ValidationRule: "validation-rule-1",
+					Message:        "Role definition matches expected permissions and assignable scopes.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewRoleDefinitionRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileRoleDefinitionRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
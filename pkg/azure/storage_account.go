@@ -0,0 +1,163 @@
+package azure
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+var (
+	storageAccountRulePermissions = []string{
+		"Microsoft.Storage/storageAccounts/read",
+		"Microsoft.Storage/storageAccounts/blobServices/containers/read",
+	}
+
+	// tlsVersions are the minimum TLS versions a storage account can have, from lowest to highest.
+	tlsVersions = []string{
+		string(armstorage.MinimumTLSVersionTLS10),
+		string(armstorage.MinimumTLSVersionTLS11),
+		string(armstorage.MinimumTLSVersionTLS12),
+		string(armstorage.MinimumTLSVersionTLS13),
+	}
+)
+
+// storageAccountAPI contains methods that allow getting all the information we need for storage
+// accounts and the blob containers within them.
+type storageAccountAPI interface {
+	GetStorageAccount(resourceGroup, name, subscriptionID string) (*armstorage.Account, error)
+	GetBlobContainers(resourceGroup, accountName, subscriptionID string) ([]*armstorage.ListContainerItem, error)
+}
+
+// StorageAccountRuleService reconciles storage account rules.
+type StorageAccountRuleService struct {
+	api storageAccountAPI
+	log logr.Logger
+}
+
+// NewStorageAccountRuleService creates a new StorageAccountRuleService. Requires an Azure client
+// facade that supports getting storage accounts and their blob containers.
+func NewStorageAccountRuleService(api storageAccountAPI, log logr.Logger) *StorageAccountRuleService {
+	return &StorageAccountRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileStorageAccountRule reconciles a storage account rule.
+func (s *StorageAccountRuleService) ReconcileStorageAccountRule(rule v1alpha1.StorageAccountRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "storageAccount", rule.StorageAccount, "resourceGroup", rule.ResourceGroup, "subscription", rule.SubscriptionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "Storage account configured as required and has required containers."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeStorageAccount
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	account, err := s.api.GetStorageAccount(rule.ResourceGroup, rule.StorageAccount, rule.SubscriptionID)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return validationResult, fmt.Errorf("storage account %s not found in resource group %s using subscription %s", rule.StorageAccount, rule.ResourceGroup, rule.SubscriptionID)
+		}
+		return validationResult, fmt.Errorf("failed to get storage account: %w", azerr.AsAugmented(err, storageAccountRulePermissions))
+	}
+
+	props := account.Properties
+	if props == nil {
+		props = &armstorage.AccountProperties{}
+	}
+	processStorageAccountProperties(rule, props, &latestCondition.Failures)
+
+	if len(rule.Containers) > 0 {
+		containers, err := s.api.GetBlobContainers(rule.ResourceGroup, rule.StorageAccount, rule.SubscriptionID)
+		if err != nil {
+			return validationResult, fmt.Errorf("failed to get blob containers: %w", azerr.AsAugmented(err, storageAccountRulePermissions))
+		}
+		present := map[string]bool{}
+		for _, c := range containers {
+			if c == nil || c.Name == nil {
+				log.Error(nil, "Blob container name in API response was nil.")
+				continue
+			}
+			present[*c.Name] = true
+		}
+		for _, name := range rule.Containers {
+			// Blob container names are always lower case, so they're compared exactly.
+			if !present[name] {
+				latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Blob container '%s' not present in storage account.", name))
+			}
+		}
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "Storage account not configured as required or lacks required containers. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// processStorageAccountProperties checks the settings of the storage account against the settings
+// from the rule. Settings missing from the API response are compared using Azure's defaults for
+// them.
+func processStorageAccountProperties(rule v1alpha1.StorageAccountRule, props *armstorage.AccountProperties, failures *[]string) {
+	if rule.MinimumTLSVersion != "" {
+		actual := string(armstorage.MinimumTLSVersionTLS10)
+		if props.MinimumTLSVersion != nil {
+			actual = string(*props.MinimumTLSVersion)
+		}
+		if slices.Index(tlsVersions, actual) < slices.Index(tlsVersions, rule.MinimumTLSVersion) {
+			*failures = append(*failures, fmt.Sprintf("Storage account minimum TLS version is '%s', expected '%s' or higher.", actual, rule.MinimumTLSVersion))
+		}
+	}
+
+	if rule.AllowBlobPublicAccess != nil {
+		actual := props.AllowBlobPublicAccess != nil && *props.AllowBlobPublicAccess
+		if actual != *rule.AllowBlobPublicAccess {
+			*failures = append(*failures, fmt.Sprintf("Storage account allow blob public access is %t, expected %t.", actual, *rule.AllowBlobPublicAccess))
+		}
+	}
+
+	if rule.HTTPSTrafficOnly != nil {
+		actual := props.EnableHTTPSTrafficOnly == nil || *props.EnableHTTPSTrafficOnly
+		if actual != *rule.HTTPSTrafficOnly {
+			*failures = append(*failures, fmt.Sprintf("Storage account HTTPS traffic only is %t, expected %t.", actual, *rule.HTTPSTrafficOnly))
+		}
+	}
+
+	if rule.NetworkDefaultAction != "" {
+		actual := string(armstorage.DefaultActionAllow)
+		if props.NetworkRuleSet != nil && props.NetworkRuleSet.DefaultAction != nil {
+			actual = string(*props.NetworkRuleSet.DefaultAction)
+		}
+		if !strings.EqualFold(actual, rule.NetworkDefaultAction) {
+			*failures = append(*failures, fmt.Sprintf("Storage account network default action is '%s', expected '%s'.", actual, rule.NetworkDefaultAction))
+		}
+	}
+
+	if rule.HierarchicalNamespace != nil {
+		actual := props.IsHnsEnabled != nil && *props.IsHnsEnabled
+		if actual != *rule.HierarchicalNamespace {
+			*failures = append(*failures, fmt.Sprintf("Storage account hierarchical namespace enabled is %t, expected %t.", actual, *rule.HierarchicalNamespace))
+		}
+	}
+}
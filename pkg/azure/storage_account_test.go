@@ -0,0 +1,151 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type storageAccountAPIMock struct {
+	account       *armstorage.Account
+	accountErr    error
+	containers    []*armstorage.ListContainerItem
+	containersErr error
+}
+
+func (m storageAccountAPIMock) GetStorageAccount(_, _, _ string) (*armstorage.Account, error) {
+	return m.account, m.accountErr
+}
+
+func (m storageAccountAPIMock) GetBlobContainers(_, _, _ string) ([]*armstorage.ListContainerItem, error) {
+	return m.containers, m.containersErr
+}
+
+func TestStorageAccountRuleService_ReconcileStorageAccountRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.StorageAccountRule
+		apiMock        storageAccountAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	rule := v1alpha1.StorageAccountRule{
+		RuleName:              "rule-1",
+		ResourceGroup:         "rg",
+		StorageAccount:        "account",
+		MinimumTLSVersion:     "TLS1_2",
+		AllowBlobPublicAccess: util.Ptr(false),
+		HTTPSTrafficOnly:      util.Ptr(true),
+		NetworkDefaultAction:  "Deny",
+		HierarchicalNamespace: util.Ptr(false),
+		Containers:            []string{"etcd-backups", "image-cache"},
+		SubscriptionID:        "sub",
+	}
+
+	containers := []*armstorage.ListContainerItem{
+		{Name: util.Ptr("etcd-backups")},
+		{Name: util.Ptr("image-cache")},
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (storage account settings match and containers present)",
+			rule: rule,
+			apiMock: storageAccountAPIMock{
+				account: &armstorage.Account{
+					Properties: &armstorage.AccountProperties{
+						MinimumTLSVersion:     util.Ptr(armstorage.MinimumTLSVersionTLS13),
+						AllowBlobPublicAccess: util.Ptr(false),
+						// HTTPS traffic only defaults to true when missing.
+						NetworkRuleSet: &armstorage.NetworkRuleSet{
+							DefaultAction: util.Ptr(armstorage.DefaultActionDeny),
+						},
+					},
+				},
+				containers: containers,
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-storage-account",
+					ValidationRule: "validation-rule-1",
+					Message:        "Storage account configured as required and has required containers.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (storage account settings don't match and container missing)",
+			rule: rule,
+			apiMock: storageAccountAPIMock{
+				account: &armstorage.Account{
+					Properties: &armstorage.AccountProperties{
+						MinimumTLSVersion:      util.Ptr(armstorage.MinimumTLSVersionTLS10),
+						AllowBlobPublicAccess:  util.Ptr(true),
+						EnableHTTPSTrafficOnly: util.Ptr(false),
+						IsHnsEnabled:           util.Ptr(true),
+					},
+				},
+				containers: containers[:1],
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-storage-account",
+					ValidationRule: "validation-rule-1",
+					Message:        "Storage account not configured as required or lacks required containers. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Storage account minimum TLS version is 'TLS1_0', expected 'TLS1_2' or higher.",
+						"Storage account allow blob public access is true, expected false.",
+						"Storage account HTTPS traffic only is false, expected true.",
+						"Storage account network default action is 'Allow', expected 'Deny'.",
+						"Storage account hierarchical namespace enabled is true, expected false.",
+						"Blob container 'image-cache' not present in storage account.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (error getting storage account) - validation result remains passing, code returned to interprets error and changes result",
+			rule: rule,
+			apiMock: storageAccountAPIMock{
+				// Can be any error message, just has to have this as substring.
+				accountErr: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("storage account account not found in resource group rg using subscription sub"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-storage-account",
+					ValidationRule: "validation-rule-1",
+					Message:        "Storage account configured as required and has required containers.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewStorageAccountRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileStorageAccountRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
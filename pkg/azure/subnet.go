@@ -0,0 +1,196 @@
+package azure
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	// azureReservedIPs is the number of IP addresses Azure reserves in each subnet address prefix.
+	// See https://learn.microsoft.com/en-us/azure/virtual-network/virtual-networks-faq#are-there-any-restrictions-on-using-ip-addresses-within-these-subnets
+	azureReservedIPs = 5
+)
+
+var (
+	subnetRulePermissions = []string{
+		"Microsoft.Network/virtualNetworks/read",
+	}
+)
+
+// subnetAPI contains methods that allow getting all the information we need for virtual networks
+// and the subnets within them.
+type subnetAPI interface {
+	GetVirtualNetwork(resourceGroup, name, subscriptionID string) (*armnetwork.VirtualNetwork, error)
+}
+
+// SubnetRuleService reconciles subnet rules.
+type SubnetRuleService struct {
+	api subnetAPI
+	log logr.Logger
+}
+
+// NewSubnetRuleService creates a new SubnetRuleService. Requires an Azure client facade that
+// supports getting virtual networks.
+func NewSubnetRuleService(api subnetAPI, log logr.Logger) *SubnetRuleService {
+	return &SubnetRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileSubnetRule reconciles a subnet rule.
+func (s *SubnetRuleService) ReconcileSubnetRule(rule v1alpha1.SubnetRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "virtualNetwork", rule.VirtualNetwork, "subnet", rule.Subnet, "resourceGroup", rule.ResourceGroup, "subscription", rule.SubscriptionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "Subnet present in virtual network with required capacity and configuration."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeSubnet
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	vnet, err := s.api.GetVirtualNetwork(rule.ResourceGroup, rule.VirtualNetwork, rule.SubscriptionID)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return validationResult, fmt.Errorf("virtual network %s not found in resource group %s using subscription %s", rule.VirtualNetwork, rule.ResourceGroup, rule.SubscriptionID)
+		}
+		return validationResult, fmt.Errorf("failed to get virtual network: %w", azerr.AsAugmented(err, subnetRulePermissions))
+	}
+
+	var subnet *armnetwork.Subnet
+	if vnet.Properties != nil {
+		for _, sn := range vnet.Properties.Subnets {
+			if sn == nil || sn.Name == nil {
+				log.Error(nil, "Subnet name in API response was nil.")
+				continue
+			}
+			if strings.EqualFold(*sn.Name, rule.Subnet) {
+				subnet = sn
+				break
+			}
+		}
+	}
+
+	if subnet == nil {
+		latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Subnet '%s' not present in virtual network '%s'.", rule.Subnet, rule.VirtualNetwork))
+	} else {
+		processSubnet(rule, subnet, &latestCondition.Failures, &latestCondition.Details)
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "Subnet missing from virtual network or lacks required capacity or configuration. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// processSubnet checks the free IP addresses, delegations, and service endpoints of the subnet
+// from the rule.
+func processSubnet(rule v1alpha1.SubnetRule, subnet *armnetwork.Subnet, failures, details *[]string) {
+	var prefixes []string
+	var ipConfigurations int
+	delegations := map[string]bool{}
+	serviceEndpoints := map[string]bool{}
+	if subnet.Properties != nil {
+		if subnet.Properties.AddressPrefix != nil {
+			prefixes = append(prefixes, *subnet.Properties.AddressPrefix)
+		}
+		for _, prefix := range subnet.Properties.AddressPrefixes {
+			if prefix != nil {
+				prefixes = append(prefixes, *prefix)
+			}
+		}
+		ipConfigurations = ipv4IPConfigurations(subnet.Properties.IPConfigurations)
+		for _, d := range subnet.Properties.Delegations {
+			if d != nil && d.Properties != nil && d.Properties.ServiceName != nil {
+				delegations[strings.ToLower(*d.Properties.ServiceName)] = true
+			}
+		}
+		for _, se := range subnet.Properties.ServiceEndpoints {
+			if se != nil && se.Service != nil {
+				serviceEndpoints[strings.ToLower(*se.Service)] = true
+			}
+		}
+	}
+
+	numFailures := len(*failures)
+
+	freeIPs := subnetSize(prefixes) - ipConfigurations
+	if freeIPs < 0 {
+		freeIPs = 0
+	}
+	if freeIPs < rule.MinFreeIPs {
+		*failures = append(*failures, fmt.Sprintf("Subnet '%s' has %d free IP addresses, expected at least %d.", rule.Subnet, freeIPs, rule.MinFreeIPs))
+	}
+	for _, d := range rule.Delegations {
+		if !delegations[strings.ToLower(d)] {
+			*failures = append(*failures, fmt.Sprintf("Subnet '%s' not delegated to '%s'.", rule.Subnet, d))
+		}
+	}
+	for _, se := range rule.ServiceEndpoints {
+		if !serviceEndpoints[strings.ToLower(se)] {
+			*failures = append(*failures, fmt.Sprintf("Subnet '%s' lacks service endpoint for '%s'.", rule.Subnet, se))
+		}
+	}
+
+	if len(*failures) == numFailures {
+		*details = append(*details, fmt.Sprintf("Found subnet; Name: '%s', Free IP addresses: %d", rule.Subnet, freeIPs))
+	}
+}
+
+// ipv4IPConfigurations returns the number of IP configurations that use an IPv4 address from the
+// subnet. IP configurations whose private IP address isn't known are assumed to use one, since
+// Azure allocates an IPv4 address to every IP configuration by default.
+func ipv4IPConfigurations(ipConfigurations []*armnetwork.IPConfiguration) int {
+	n := 0
+	for _, c := range ipConfigurations {
+		if c == nil {
+			continue
+		}
+		if c.Properties != nil && c.Properties.PrivateIPAddress != nil {
+			if addr, err := netip.ParseAddr(*c.Properties.PrivateIPAddress); err == nil && !addr.Unmap().Is4() {
+				continue
+			}
+		}
+		n++
+	}
+	return n
+}
+
+// subnetSize returns the number of usable IPv4 addresses in a subnet's address prefixes, which is
+// the number of addresses in each prefix minus the addresses Azure reserves in it. IPv6 prefixes
+// and prefixes that can't be parsed are ignored.
+func subnetSize(prefixes []string) int {
+	size := 0
+	for _, p := range prefixes {
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil || !prefix.Addr().Is4() {
+			continue
+		}
+		if n := 1<<(32-prefix.Bits()) - azureReservedIPs; n > 0 {
+			size += n
+		}
+	}
+	return size
+}
@@ -0,0 +1,229 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type subnetAPIMock struct {
+	data *armnetwork.VirtualNetwork
+	err  error
+}
+
+func (m subnetAPIMock) GetVirtualNetwork(_, _, _ string) (*armnetwork.VirtualNetwork, error) {
+	return m.data, m.err
+}
+
+func vnetWithSubnet(name, prefix string, ipConfigurations int, delegations, serviceEndpoints []string) *armnetwork.VirtualNetwork {
+	ipConfigs := []*armnetwork.IPConfiguration{}
+	for i := 0; i < ipConfigurations; i++ {
+		ipConfigs = append(ipConfigs, &armnetwork.IPConfiguration{})
+	}
+	ds := []*armnetwork.Delegation{}
+	for _, d := range delegations {
+		ds = append(ds, &armnetwork.Delegation{Properties: &armnetwork.ServiceDelegationPropertiesFormat{ServiceName: util.Ptr(d)}})
+	}
+	ses := []*armnetwork.ServiceEndpointPropertiesFormat{}
+	for _, se := range serviceEndpoints {
+		ses = append(ses, &armnetwork.ServiceEndpointPropertiesFormat{Service: util.Ptr(se)})
+	}
+	return &armnetwork.VirtualNetwork{
+		Properties: &armnetwork.VirtualNetworkPropertiesFormat{
+			Subnets: []*armnetwork.Subnet{
+				{},
+				{
+					Name: util.Ptr(name),
+					Properties: &armnetwork.SubnetPropertiesFormat{
+						AddressPrefix:    util.Ptr(prefix),
+						IPConfigurations: ipConfigs,
+						Delegations:      ds,
+						ServiceEndpoints: ses,
+					},
+				},
+			},
+		},
+	}
+}
+
+func withIPConfigurations(vnet *armnetwork.VirtualNetwork, addresses ...string) *armnetwork.VirtualNetwork {
+	subnet := vnet.Properties.Subnets[len(vnet.Properties.Subnets)-1]
+	for _, a := range addresses {
+		subnet.Properties.IPConfigurations = append(subnet.Properties.IPConfigurations, &armnetwork.IPConfiguration{
+			Properties: &armnetwork.IPConfigurationPropertiesFormat{PrivateIPAddress: util.Ptr(a)},
+		})
+	}
+	return vnet
+}
+
+func TestSubnetRuleService_ReconcileSubnetRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.SubnetRule
+		apiMock        subnetAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (subnet has enough free IPs, delegations, and service endpoints)",
+			rule: v1alpha1.SubnetRule{
+				RuleName:         "rule-1",
+				ResourceGroup:    "rg1",
+				VirtualNetwork:   "vnet1",
+				Subnet:           "subnet1",
+				MinFreeIPs:       241,
+				Delegations:      []string{"Microsoft.ContainerService/managedClusters"},
+				ServiceEndpoints: []string{"Microsoft.Storage"},
+				SubscriptionID:   "sub",
+			},
+			apiMock: subnetAPIMock{
+				// /24 has 256 addresses, 251 usable after Azure reserves 5.
+				data: vnetWithSubnet("subnet1", "10.0.0.0/24", 10, []string{"Microsoft.ContainerService/managedClusters"}, []string{"Microsoft.Storage"}),
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-subnet",
+					ValidationRule: "validation-rule-1",
+					Message:        "Subnet present in virtual network with required capacity and configuration.",
+					Details: []string{
+						"Found subnet; Name: 'subnet1', Free IP addresses: 241",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Pass (IPv6 IP configurations not counted against IPv4 addresses)",
+			rule: v1alpha1.SubnetRule{
+				RuleName:       "rule-1",
+				ResourceGroup:  "rg1",
+				VirtualNetwork: "vnet1",
+				Subnet:         "subnet1",
+				MinFreeIPs:     249,
+				SubscriptionID: "sub",
+			},
+			apiMock: subnetAPIMock{
+				data: withIPConfigurations(vnetWithSubnet("subnet1", "10.0.0.0/24", 0, nil, nil), "10.0.0.4", "10.0.0.5", "fd00::4", "fd00::5"),
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-subnet",
+					ValidationRule: "validation-rule-1",
+					Message:        "Subnet present in virtual network with required capacity and configuration.",
+					Details: []string{
+						"Found subnet; Name: 'subnet1', Free IP addresses: 249",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (subnet lacks free IPs, delegation, and service endpoint)",
+			rule: v1alpha1.SubnetRule{
+				RuleName:         "rule-1",
+				ResourceGroup:    "rg1",
+				VirtualNetwork:   "vnet1",
+				Subnet:           "subnet1",
+				MinFreeIPs:       242,
+				Delegations:      []string{"Microsoft.ContainerService/managedClusters"},
+				ServiceEndpoints: []string{"Microsoft.Storage"},
+				SubscriptionID:   "sub",
+			},
+			apiMock: subnetAPIMock{
+				data: vnetWithSubnet("subnet1", "10.0.0.0/24", 10, nil, []string{"Microsoft.KeyVault"}),
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-subnet",
+					ValidationRule: "validation-rule-1",
+					Message:        "Subnet missing from virtual network or lacks required capacity or configuration. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Subnet 'subnet1' has 241 free IP addresses, expected at least 242.",
+						"Subnet 'subnet1' not delegated to 'Microsoft.ContainerService/managedClusters'.",
+						"Subnet 'subnet1' lacks service endpoint for 'Microsoft.Storage'.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (subnet not present in virtual network)",
+			rule: v1alpha1.SubnetRule{
+				RuleName:       "rule-1",
+				ResourceGroup:  "rg1",
+				VirtualNetwork: "vnet1",
+				Subnet:         "subnet2",
+				SubscriptionID: "sub",
+			},
+			apiMock: subnetAPIMock{
+				data: vnetWithSubnet("subnet1", "10.0.0.0/24", 0, nil, nil),
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-subnet",
+					ValidationRule: "validation-rule-1",
+					Message:        "Subnet missing from virtual network or lacks required capacity or configuration. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Subnet 'subnet2' not present in virtual network 'vnet1'.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (virtual network does not exist or is not accessible using subscription) - validation result remains passing, code returned to interprets error and changes result",
+			rule: v1alpha1.SubnetRule{
+				RuleName:       "rule-1",
+				ResourceGroup:  "rg1",
+				VirtualNetwork: "vnet1",
+				Subnet:         "subnet1",
+				SubscriptionID: "sub",
+			},
+			apiMock: subnetAPIMock{
+				// Can be any error message, just has to have this as substring.
+				err: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("virtual network vnet1 not found in resource group rg1 using subscription sub"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-subnet",
+					ValidationRule: "validation-rule-1",
+					Message:        "Subnet present in virtual network with required capacity and configuration.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewSubnetRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileSubnetRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
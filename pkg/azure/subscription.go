@@ -0,0 +1,155 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managementgroups/armmanagementgroups"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	// subscriptionStateEnabled is the state of subscriptions that can be used normally. The other
+	// states (e.g. "Warned", "PastDue", and "Disabled") restrict creating or using resources.
+	subscriptionStateEnabled = "Enabled"
+
+	// subscriptionSpendingLimitOn is the spending limit value of subscriptions whose spending limit
+	// is in effect.
+	subscriptionSpendingLimitOn = "On"
+)
+
+var (
+	subscriptionRulePermissions = []string{
+		"Microsoft.Resources/subscriptions/read",
+		"Microsoft.Management/managementGroups/read",
+	}
+)
+
+// subscriptionAPI contains methods that allow getting all the information we need for
+// subscriptions and the management groups they're in.
+type subscriptionAPI interface {
+	GetSubscription(subscriptionID string) (*azutils.Subscription, error)
+	GetManagementGroup(groupID string) (*armmanagementgroups.ManagementGroup, error)
+}
+
+// SubscriptionRuleService reconciles subscription rules.
+type SubscriptionRuleService struct {
+	api subscriptionAPI
+	log logr.Logger
+}
+
+// NewSubscriptionRuleService creates a new SubscriptionRuleService. Requires an Azure client facade
+// that supports getting subscriptions and management groups.
+func NewSubscriptionRuleService(api subscriptionAPI, log logr.Logger) *SubscriptionRuleService {
+	return &SubscriptionRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileSubscriptionRule reconciles a subscription rule.
+func (s *SubscriptionRuleService) ReconcileSubscriptionRule(rule v1alpha1.SubscriptionRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "subscription", rule.SubscriptionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "Subscription enabled and configured as required."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeSubscription
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	subscription, err := s.api.GetSubscription(rule.SubscriptionID)
+	if err != nil {
+		if azerr.IsNotFound(err) {
+			return validationResult, fmt.Errorf("subscription %s not found", rule.SubscriptionID)
+		}
+		return validationResult, fmt.Errorf("failed to get subscription: %w", azerr.AsAugmented(err, subscriptionRulePermissions))
+	}
+
+	if subscription.State == nil {
+		log.Error(nil, "Subscription state in API response was nil.")
+	} else if *subscription.State != subscriptionStateEnabled {
+		latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Subscription state is '%s', expected '%s'.", *subscription.State, subscriptionStateEnabled))
+	}
+
+	if rule.TenantID != "" {
+		tenantID := ""
+		if subscription.TenantID != nil {
+			tenantID = *subscription.TenantID
+		}
+		if !strings.EqualFold(tenantID, rule.TenantID) {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Subscription in tenant '%s', expected '%s'.", tenantID, rule.TenantID))
+		}
+	}
+
+	if !rule.AllowSpendingLimit && subscription.SubscriptionPolicies != nil && subscription.SubscriptionPolicies.SpendingLimit != nil &&
+		*subscription.SubscriptionPolicies.SpendingLimit == subscriptionSpendingLimitOn {
+		offer := "unknown"
+		if subscription.SubscriptionPolicies.QuotaID != nil {
+			offer = *subscription.SubscriptionPolicies.QuotaID
+		}
+		latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Subscription has a spending limit (offer '%s'); its resources are disabled once the spending limit is reached.", offer))
+	}
+
+	if rule.ManagementGroup != "" {
+		group, err := s.api.GetManagementGroup(rule.ManagementGroup)
+		if err != nil {
+			if azerr.IsNotFound(err) {
+				return validationResult, fmt.Errorf("management group %s not found", rule.ManagementGroup)
+			}
+			return validationResult, fmt.Errorf("failed to get management group: %w", azerr.AsAugmented(err, subscriptionRulePermissions))
+		}
+		var children []*armmanagementgroups.ManagementGroupChildInfo
+		if group.Properties != nil {
+			children = group.Properties.Children
+		}
+		if !managementGroupContainsSubscription(children, rule.SubscriptionID) {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Subscription not in management group '%s' or any of its descendant management groups.", rule.ManagementGroup))
+		}
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "Subscription not enabled or not configured as required. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// managementGroupContainsSubscription returns whether a subscription is among the children of a
+// management group, recursing into child management groups.
+func managementGroupContainsSubscription(children []*armmanagementgroups.ManagementGroupChildInfo, subscriptionID string) bool {
+	for _, c := range children {
+		if c == nil || c.Type == nil {
+			continue
+		}
+		switch *c.Type {
+		case armmanagementgroups.ManagementGroupChildTypeSubscriptions:
+			if c.Name != nil && strings.EqualFold(*c.Name, subscriptionID) {
+				return true
+			}
+		case armmanagementgroups.ManagementGroupChildTypeMicrosoftManagementManagementGroups:
+			if managementGroupContainsSubscription(c.Children, subscriptionID) {
+				return true
+			}
+		}
+	}
+	return false
+}
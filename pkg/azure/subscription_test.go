@@ -0,0 +1,180 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managementgroups/armmanagementgroups"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	azutils "github.com/validator-labs/validator-plugin-azure/pkg/utils/azure"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type subscriptionAPIMock struct {
+	subscription    *azutils.Subscription
+	subscriptionErr error
+	group           *armmanagementgroups.ManagementGroup
+}
+
+func (m subscriptionAPIMock) GetSubscription(_ string) (*azutils.Subscription, error) {
+	return m.subscription, m.subscriptionErr
+}
+
+func (m subscriptionAPIMock) GetManagementGroup(_ string) (*armmanagementgroups.ManagementGroup, error) {
+	return m.group, nil
+}
+
+func subscription(state, tenantID, quotaID, spendingLimit string) *azutils.Subscription {
+	return &azutils.Subscription{
+		State:    util.Ptr(state),
+		TenantID: util.Ptr(tenantID),
+		SubscriptionPolicies: &azutils.SubscriptionPolicies{
+			QuotaID:       util.Ptr(quotaID),
+			SpendingLimit: util.Ptr(spendingLimit),
+		},
+	}
+}
+
+func TestSubscriptionRuleService_ReconcileSubscriptionRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.SubscriptionRule
+		apiMock        subscriptionAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	rule := v1alpha1.SubscriptionRule{
+		RuleName:        "rule-1",
+		TenantID:        "tenant",
+		ManagementGroup: "platform",
+		SubscriptionID:  "sub",
+	}
+
+	// The subscription is in a management group nested in the one from the rule.
+	group := &armmanagementgroups.ManagementGroup{
+		Properties: &armmanagementgroups.ManagementGroupProperties{
+			Children: []*armmanagementgroups.ManagementGroupChildInfo{
+				{
+					Type: util.Ptr(armmanagementgroups.ManagementGroupChildTypeSubscriptions),
+					Name: util.Ptr("other-sub"),
+				},
+				{
+					Type: util.Ptr(armmanagementgroups.ManagementGroupChildTypeMicrosoftManagementManagementGroups),
+					Name: util.Ptr("landing-zones"),
+					Children: []*armmanagementgroups.ManagementGroupChildInfo{
+						{
+							Type: util.Ptr(armmanagementgroups.ManagementGroupChildTypeSubscriptions),
+							Name: util.Ptr("sub"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (subscription enabled, in expected tenant and management group, and without spending limit)",
+			rule: rule,
+			apiMock: subscriptionAPIMock{
+				subscription: subscription("Enabled", "tenant", "PayAsYouGo_2014-09-01", "Off"),
+				group:        group,
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-subscription",
+					ValidationRule: "validation-rule-1",
+					Message:        "Subscription enabled and configured as required.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (subscription disabled, in another tenant and management group, and with spending limit)",
+			rule: rule,
+			apiMock: subscriptionAPIMock{
+				subscription: subscription("Disabled", "other-tenant", "FreeTrial_2014-09-01", "On"),
+				group: &armmanagementgroups.ManagementGroup{
+					Properties: &armmanagementgroups.ManagementGroupProperties{},
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-subscription",
+					ValidationRule: "validation-rule-1",
+					Message:        "Subscription not enabled or not configured as required. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"Subscription state is 'Disabled', expected 'Enabled'.",
+						"Subscription in tenant 'other-tenant', expected 'tenant'.",
+						"Subscription has a spending limit (offer 'FreeTrial_2014-09-01'); its resources are disabled once the spending limit is reached.",
+						"Subscription not in management group 'platform' or any of its descendant management groups.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Pass (spending limit allowed)",
+			rule: v1alpha1.SubscriptionRule{
+				RuleName:           "rule-1",
+				AllowSpendingLimit: true,
+				SubscriptionID:     "sub",
+			},
+			apiMock: subscriptionAPIMock{
+				subscription: subscription("Enabled", "tenant", "MSDN_2014-09-01", "On"),
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-subscription",
+					ValidationRule: "validation-rule-1",
+					Message:        "Subscription enabled and configured as required.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (error getting subscription) - validation result remains passing, code returned to interprets error and changes result",
+			rule: rule,
+			apiMock: subscriptionAPIMock{
+				// Can be any error message, just has to have this as substring.
+				subscriptionErr: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("subscription sub not found"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-subscription",
+					ValidationRule: "validation-rule-1",
+					Message:        "Subscription enabled and configured as required.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewSubscriptionRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileSubscriptionRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
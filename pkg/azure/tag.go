@@ -0,0 +1,120 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	// maxNoncompliantResources is the maximum number of non-compliant resources listed in the
+	// failures of a tag rule, which keeps validation results readable for large scopes.
+	maxNoncompliantResources = 100
+)
+
+var (
+	tagRulePermissions = []string{
+		"Microsoft.Resources/subscriptions/resources/read",
+		"Microsoft.Resources/subscriptions/resourceGroups/resources/read",
+	}
+)
+
+// tagAPI contains methods that allow getting all the information we need for the tags of
+// resources.
+type tagAPI interface {
+	GetResources(resourceGroup, resourceType, subscriptionID string) ([]*armresources.GenericResourceExpanded, error)
+}
+
+// TagRuleService reconciles tag rules.
+type TagRuleService struct {
+	api tagAPI
+	log logr.Logger
+}
+
+// NewTagRuleService creates a new TagRuleService. Requires an Azure client facade that supports
+// listing resources.
+func NewTagRuleService(api tagAPI, log logr.Logger) *TagRuleService {
+	return &TagRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileTagRule reconciles a tag rule.
+func (s *TagRuleService) ReconcileTagRule(rule v1alpha1.TagRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "resourceGroup", rule.ResourceGroup, "subscription", rule.SubscriptionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "All resources have required tags."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeTag
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	tagReqs, err := compileTagRequirements(rule.Tags)
+	if err != nil {
+		return validationResult, err
+	}
+
+	// Without resource types, all resources are listed at once.
+	resourceTypes := rule.ResourceTypes
+	if len(resourceTypes) == 0 {
+		resourceTypes = []string{""}
+	}
+
+	checked, noncompliant := 0, 0
+	for _, resourceType := range resourceTypes {
+		resources, err := s.api.GetResources(rule.ResourceGroup, resourceType, rule.SubscriptionID)
+		if err != nil {
+			if azerr.IsNotFound(err) && rule.ResourceGroup != "" {
+				return validationResult, fmt.Errorf("resource group %s not found using subscription %s", rule.ResourceGroup, rule.SubscriptionID)
+			}
+			return validationResult, fmt.Errorf("failed to get resources: %w", azerr.AsAugmented(err, tagRulePermissions))
+		}
+		for _, r := range resources {
+			if r == nil || r.ID == nil {
+				log.Error(nil, "Resource ID in API response was nil.")
+				continue
+			}
+			checked++
+			problems := tagReqs.problems(r.Tags)
+			if len(problems) == 0 {
+				continue
+			}
+			noncompliant++
+			if noncompliant <= maxNoncompliantResources {
+				latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Resource %s: %s.", *r.ID, strings.Join(problems, "; ")))
+			}
+		}
+	}
+
+	latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Checked tags of %d resource(s).", checked))
+	if noncompliant > maxNoncompliantResources {
+		latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("%d more non-compliant resource(s) not listed.", noncompliant-maxNoncompliantResources))
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = fmt.Sprintf("%d resource(s) lack required tags. See failures for details.", noncompliant)
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
@@ -0,0 +1,218 @@
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type tagAPIMock struct {
+	// resources maps resource types to resources. The empty resource type maps to all resources.
+	resources map[string][]*armresources.GenericResourceExpanded
+	err       error
+}
+
+func (m tagAPIMock) GetResources(_, resourceType, _ string) ([]*armresources.GenericResourceExpanded, error) {
+	return m.resources[resourceType], m.err
+}
+
+func taggedResource(id string, tags map[string]*string) *armresources.GenericResourceExpanded {
+	return &armresources.GenericResourceExpanded{
+		ID:   util.Ptr(id),
+		Tags: tags,
+	}
+}
+
+func TestTagRuleService_ReconcileTagRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.TagRule
+		apiMock        tagAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	vmID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm"
+	diskID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/disks/disk"
+
+	vm := taggedResource(vmID, map[string]*string{"env": util.Ptr("prod"), "owner": util.Ptr("team-a")})
+	disk := taggedResource(diskID, map[string]*string{"env": util.Ptr("scratch")})
+
+	apiMock := tagAPIMock{
+		resources: map[string][]*armresources.GenericResourceExpanded{
+			"":                                  {vm, disk},
+			"Microsoft.Compute/virtualMachines": {vm},
+			"Microsoft.Compute/disks":           {disk},
+		},
+	}
+
+	manyResources := []*armresources.GenericResourceExpanded{}
+	for i := range maxNoncompliantResources + 2 {
+		manyResources = append(manyResources, taggedResource(fmt.Sprintf("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/disks/disk-%d", i), nil))
+	}
+	manyFailures := []string{}
+	for i := range maxNoncompliantResources {
+		manyFailures = append(manyFailures, fmt.Sprintf("Resource /subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/disks/disk-%d: missing tag 'env'.", i))
+	}
+	manyFailures = append(manyFailures, "2 more non-compliant resource(s) not listed.")
+
+	testCases := []testCase{
+		{
+			name: "Pass (resources of the resource type have required tags)",
+			rule: v1alpha1.TagRule{
+				RuleName:       "rule-1",
+				ResourceGroup:  "rg",
+				ResourceTypes:  []string{"Microsoft.Compute/virtualMachines"},
+				Tags:           []v1alpha1.TagRequirement{{Key: "env", AllowedValues: []string{"dev", "prod"}}, {Key: "owner", Pattern: "^team-"}},
+				SubscriptionID: "sub",
+			},
+			apiMock:       apiMock,
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-tag",
+					ValidationRule: "validation-rule-1",
+					Message:        "All resources have required tags.",
+					Details:        []string{"Checked tags of 1 resource(s)."},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (resource lacks required tags)",
+			rule: v1alpha1.TagRule{
+				RuleName:       "rule-1",
+				Tags:           []v1alpha1.TagRequirement{{Key: "env", AllowedValues: []string{"dev", "prod"}}, {Key: "owner", Pattern: "^team-"}},
+				SubscriptionID: "sub",
+			},
+			apiMock:       apiMock,
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-tag",
+					ValidationRule: "validation-rule-1",
+					Message:        "1 resource(s) lack required tags. See failures for details.",
+					Details:        []string{"Checked tags of 2 resource(s)."},
+					Failures: []string{
+						"Resource " + diskID + ": tag 'env' has value 'scratch', expected one of 'dev', 'prod'; missing tag 'owner'.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (resources of multiple resource types are checked)",
+			rule: v1alpha1.TagRule{
+				RuleName:       "rule-1",
+				ResourceGroup:  "rg",
+				ResourceTypes:  []string{"Microsoft.Compute/virtualMachines", "Microsoft.Compute/disks"},
+				Tags:           []v1alpha1.TagRequirement{{Key: "env", Value: "prod"}},
+				SubscriptionID: "sub",
+			},
+			apiMock:       apiMock,
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-tag",
+					ValidationRule: "validation-rule-1",
+					Message:        "1 resource(s) lack required tags. See failures for details.",
+					Details:        []string{"Checked tags of 2 resource(s)."},
+					Failures: []string{
+						"Resource " + diskID + ": tag 'env' has value 'scratch', expected 'prod'.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (non-compliant resources beyond the limit aren't listed)",
+			rule: v1alpha1.TagRule{
+				RuleName:       "rule-1",
+				Tags:           []v1alpha1.TagRequirement{{Key: "env"}},
+				SubscriptionID: "sub",
+			},
+			apiMock: tagAPIMock{
+				resources: map[string][]*armresources.GenericResourceExpanded{"": manyResources},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-tag",
+					ValidationRule: "validation-rule-1",
+					Message:        fmt.Sprintf("%d resource(s) lack required tags. See failures for details.", maxNoncompliantResources+2),
+					Details:        []string{fmt.Sprintf("Checked tags of %d resource(s).", maxNoncompliantResources+2)},
+					Failures:       manyFailures,
+					Status:         corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Error (resource group not found)",
+			rule: v1alpha1.TagRule{
+				RuleName:       "rule-1",
+				ResourceGroup:  "rg",
+				Tags:           []v1alpha1.TagRequirement{{Key: "env"}},
+				SubscriptionID: "sub",
+			},
+			apiMock: tagAPIMock{
+				err: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("resource group rg not found using subscription sub"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-tag",
+					ValidationRule: "validation-rule-1",
+					Message:        "All resources have required tags.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Error (invalid tag pattern) - resources not requested",
+			rule: v1alpha1.TagRule{
+				RuleName:       "rule-1",
+				ResourceGroup:  "rg",
+				Tags:           []v1alpha1.TagRequirement{{Key: "owner", Pattern: "team-("}},
+				SubscriptionID: "sub",
+			},
+			apiMock: tagAPIMock{
+				err: errors.New("RESPONSE 404"),
+			},
+			expectedError: errors.New("invalid pattern for tag owner: error parsing regexp: missing closing ): `team-(`"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-tag",
+					ValidationRule: "validation-rule-1",
+					Message:        "All resources have required tags.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		svc := NewTagRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileTagRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
@@ -0,0 +1,71 @@
+package azure
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+)
+
+// tagRequirement is a tag requirement with its pattern compiled.
+type tagRequirement struct {
+	v1alpha1.TagRequirement
+	pattern *regexp.Regexp
+}
+
+// tagRequirements are tag requirements with their patterns compiled, so that the patterns are
+// compiled once per rule instead of once per resource.
+type tagRequirements []tagRequirement
+
+// compileTagRequirements compiles the patterns of tag requirements. Returns an error if a
+// requirement's pattern isn't a valid regular expression.
+func compileTagRequirements(reqs []v1alpha1.TagRequirement) (tagRequirements, error) {
+	compiled := make(tagRequirements, 0, len(reqs))
+	for _, req := range reqs {
+		c := tagRequirement{TagRequirement: req}
+		if req.Pattern != "" {
+			re, err := regexp.Compile(req.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern for tag %s: %w", req.Key, err)
+			}
+			c.pattern = re
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// problems checks a resource's tags against the tag requirements and returns a description of each
+// requirement the tags don't meet. Tag names are compared case-insensitively, like Azure does, and
+// tag values are compared case-sensitively.
+func (reqs tagRequirements) problems(tags map[string]*string) []string {
+	values := map[string]string{}
+	for k, v := range tags {
+		if v == nil {
+			values[strings.ToLower(k)] = ""
+			continue
+		}
+		values[strings.ToLower(k)] = *v
+	}
+
+	problems := []string{}
+	for _, req := range reqs {
+		value, ok := values[strings.ToLower(req.Key)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("missing tag '%s'", req.Key))
+			continue
+		}
+		if req.Value != "" && value != req.Value {
+			problems = append(problems, fmt.Sprintf("tag '%s' has value '%s', expected '%s'", req.Key, value, req.Value))
+		}
+		if len(req.AllowedValues) > 0 && !slices.Contains(req.AllowedValues, value) {
+			problems = append(problems, fmt.Sprintf("tag '%s' has value '%s', expected one of '%s'", req.Key, value, strings.Join(req.AllowedValues, "', '")))
+		}
+		if req.pattern != nil && !req.pattern.MatchString(value) {
+			problems = append(problems, fmt.Sprintf("tag '%s' has value '%s', which doesn't match pattern '%s'", req.Key, value, req.Pattern))
+		}
+	}
+	return problems
+}
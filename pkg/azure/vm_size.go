@@ -0,0 +1,165 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/validator-labs/validator-plugin-azure/pkg/constants"
+	azerr "github.com/validator-labs/validator-plugin-azure/pkg/utils/azureerrors"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/validator-labs/validator/pkg/constants"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+)
+
+const (
+	// resourceTypeVirtualMachines is the resource type of compute resource SKUs that are VM sizes.
+	resourceTypeVirtualMachines = "virtualMachines"
+)
+
+var (
+	vmSizeRulePermissions = []string{
+		"Microsoft.Compute/skus/read",
+	}
+)
+
+// resourceSKUAPI contains methods that allow getting all the information we need for the compute
+// resource SKUs offered in a location.
+type resourceSKUAPI interface {
+	GetResourceSKUsForLocation(location, subscriptionID string) ([]*armcompute.ResourceSKU, error)
+}
+
+// VMSizeRuleService reconciles VM size rules.
+type VMSizeRuleService struct {
+	api resourceSKUAPI
+	log logr.Logger
+}
+
+// NewVMSizeRuleService creates a new VMSizeRuleService. Requires an Azure client facade that
+// supports getting all compute resource SKUs for a location.
+func NewVMSizeRuleService(api resourceSKUAPI, log logr.Logger) *VMSizeRuleService {
+	return &VMSizeRuleService{
+		api: api,
+		log: log,
+	}
+}
+
+// ReconcileVMSizeRule reconciles a VM size rule.
+func (s *VMSizeRuleService) ReconcileVMSizeRule(rule v1alpha1.VMSizeRule) (*vapitypes.ValidationRuleResult, error) {
+
+	log := s.log.WithValues("rule", rule.Name(), "vmSizes", rule.VMSizes, "location", rule.Location, "zones", rule.Zones, "subscription", rule.SubscriptionID)
+
+	// Build the default ValidationResult for this rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "All required VM sizes available to subscription."
+	latestCondition.ValidationRule = fmt.Sprintf(
+		"%s-%s",
+		vapiconstants.ValidationRulePrefix, util.Sanitize(rule.Name()),
+	)
+	latestCondition.ValidationType = constants.ValidationTypeVMSize
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	skus, err := s.api.GetResourceSKUsForLocation(rule.Location, rule.SubscriptionID)
+	if err != nil {
+		return validationResult, fmt.Errorf("failed to get resource SKUs for location: %w", azerr.AsAugmented(err, vmSizeRulePermissions))
+	}
+
+	// VM size names are case insensitive in Azure, so we key the map by lowercase name. Only SKUs
+	// that list the rule's location are kept, because the location filter applied by the API is
+	// the only thing telling us the SKU is offered there.
+	vmSKUs := map[string]*armcompute.ResourceSKU{}
+	for _, sku := range skus {
+		if sku == nil || sku.Name == nil || sku.ResourceType == nil {
+			log.Error(nil, "Resource SKU name or resource type in API response was nil.")
+			continue
+		}
+		if *sku.ResourceType != resourceTypeVirtualMachines || !containsFold(sku.Locations, rule.Location) {
+			continue
+		}
+		vmSKUs[strings.ToLower(*sku.Name)] = sku
+	}
+
+	for _, vmSize := range rule.VMSizes {
+		sku, ok := vmSKUs[strings.ToLower(vmSize)]
+		if !ok {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("VM size '%s' not offered in location '%s'.", vmSize, rule.Location))
+			continue
+		}
+		failures := vmSizeFailures(sku, vmSize, rule.Location, rule.Zones)
+		if len(failures) > 0 {
+			latestCondition.Failures = append(latestCondition.Failures, failures...)
+			continue
+		}
+		latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Found available VM size; Name: '%s'", vmSize))
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "One or more required VM sizes unavailable to subscription. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// vmSizeFailures determines why, if at all, a VM size's SKU can't be used by the subscription in
+// a location and its zones. Restrictions are reported with their reason code (e.g.
+// "NotAvailableForSubscription").
+func vmSizeFailures(sku *armcompute.ResourceSKU, vmSize, location string, zones []string) []string {
+	failures := []string{}
+
+	// Zones the SKU is offered in for the location, before restrictions are applied.
+	offeredZones := []*string{}
+	for _, info := range sku.LocationInfo {
+		if info != nil && info.Location != nil && strings.EqualFold(*info.Location, location) {
+			offeredZones = append(offeredZones, info.Zones...)
+		}
+	}
+	for _, zone := range zones {
+		if !containsFold(offeredZones, zone) {
+			failures = append(failures, fmt.Sprintf("VM size '%s' not offered in zone '%s' of location '%s'.", vmSize, zone, location))
+		}
+	}
+
+	for _, restriction := range sku.Restrictions {
+		if restriction == nil || restriction.Type == nil || restriction.RestrictionInfo == nil {
+			continue
+		}
+		reasonCode := ""
+		if restriction.ReasonCode != nil {
+			reasonCode = string(*restriction.ReasonCode)
+		}
+		switch *restriction.Type {
+		case armcompute.ResourceSKURestrictionsTypeLocation:
+			if containsFold(restriction.RestrictionInfo.Locations, location) {
+				failures = append(failures, fmt.Sprintf("VM size '%s' restricted in location '%s'; Reason code: '%s'", vmSize, location, reasonCode))
+			}
+		case armcompute.ResourceSKURestrictionsTypeZone:
+			for _, zone := range zones {
+				if containsFold(restriction.RestrictionInfo.Zones, zone) {
+					failures = append(failures, fmt.Sprintf("VM size '%s' restricted in zone '%s' of location '%s'; Reason code: '%s'", vmSize, zone, location, reasonCode))
+				}
+			}
+		}
+	}
+
+	return failures
+}
+
+// containsFold returns whether a list of strings from an Azure API response contains a value,
+// ignoring case.
+func containsFold(vals []*string, val string) bool {
+	for _, v := range vals {
+		if v != nil && strings.EqualFold(*v, val) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/go-logr/logr"
+	"github.com/validator-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/validator-labs/validator/api/v1alpha1"
+	"github.com/validator-labs/validator/pkg/test"
+	vapitypes "github.com/validator-labs/validator/pkg/types"
+	"github.com/validator-labs/validator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type resourceSKUAPIMock struct {
+	data []*armcompute.ResourceSKU
+	err  error
+}
+
+func (m resourceSKUAPIMock) GetResourceSKUsForLocation(_, _ string) ([]*armcompute.ResourceSKU, error) {
+	return m.data, m.err
+}
+
+func vmSKU(name string, zones []string, restrictions ...*armcompute.ResourceSKURestrictions) *armcompute.ResourceSKU {
+	zonePtrs := []*string{}
+	for _, z := range zones {
+		zonePtrs = append(zonePtrs, util.Ptr(z))
+	}
+	return &armcompute.ResourceSKU{
+		Name:         util.Ptr(name),
+		ResourceType: util.Ptr("virtualMachines"),
+		Locations:    []*string{util.Ptr("eastus")},
+		LocationInfo: []*armcompute.ResourceSKULocationInfo{
+			{
+				Location: util.Ptr("eastus"),
+				Zones:    zonePtrs,
+			},
+		},
+		Restrictions: restrictions,
+	}
+}
+
+func TestVMSizeRuleService_ReconcileVMSizeRule(t *testing.T) {
+
+	type testCase struct {
+		name           string
+		rule           v1alpha1.VMSizeRule
+		apiMock        resourceSKUAPIMock
+		expectedError  error
+		expectedResult vapitypes.ValidationRuleResult
+	}
+
+	testCases := []testCase{
+		{
+			name: "Pass (VM sizes offered in location and zones without restrictions)",
+			rule: v1alpha1.VMSizeRule{
+				RuleName:       "rule-1",
+				Location:       "eastus",
+				Zones:          []string{"1", "2"},
+				VMSizes:        []string{"Standard_D4s_v5", "standard_d8s_v5"},
+				SubscriptionID: "sub",
+			},
+			apiMock: resourceSKUAPIMock{
+				data: []*armcompute.ResourceSKU{
+					{
+						Name:         util.Ptr("Standard_LRS"),
+						ResourceType: util.Ptr("disks"),
+						Locations:    []*string{util.Ptr("eastus")},
+					},
+					vmSKU("Standard_D4s_v5", []string{"1", "2", "3"}),
+					vmSKU("Standard_D8s_v5", []string{"2", "1"}),
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-vm-size",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required VM sizes available to subscription.",
+					Details: []string{
+						"Found available VM size; Name: 'Standard_D4s_v5'",
+						"Found available VM size; Name: 'standard_d8s_v5'",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (VM size not offered in location, not offered in zone, and restricted in zone)",
+			rule: v1alpha1.VMSizeRule{
+				RuleName:       "rule-1",
+				Location:       "eastus",
+				Zones:          []string{"2", "3"},
+				VMSizes:        []string{"Standard_D4s_v5", "Standard_D8s_v5", "Standard_NC6"},
+				SubscriptionID: "sub",
+			},
+			apiMock: resourceSKUAPIMock{
+				data: []*armcompute.ResourceSKU{
+					vmSKU("Standard_D4s_v5", []string{"1", "2", "3"}, &armcompute.ResourceSKURestrictions{
+						Type:       util.Ptr(armcompute.ResourceSKURestrictionsTypeZone),
+						ReasonCode: util.Ptr(armcompute.ResourceSKURestrictionsReasonCodeNotAvailableForSubscription),
+						RestrictionInfo: &armcompute.ResourceSKURestrictionInfo{
+							Locations: []*string{util.Ptr("eastus")},
+							Zones:     []*string{util.Ptr("3")},
+						},
+					}),
+					vmSKU("Standard_D8s_v5", []string{"1", "2"}),
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-vm-size",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more required VM sizes unavailable to subscription. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"VM size 'Standard_D4s_v5' restricted in zone '3' of location 'eastus'; Reason code: 'NotAvailableForSubscription'",
+						"VM size 'Standard_D8s_v5' not offered in zone '3' of location 'eastus'.",
+						"VM size 'Standard_NC6' not offered in location 'eastus'.",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (VM size restricted in location)",
+			rule: v1alpha1.VMSizeRule{
+				RuleName:       "rule-1",
+				Location:       "eastus",
+				VMSizes:        []string{"Standard_D4s_v5"},
+				SubscriptionID: "sub",
+			},
+			apiMock: resourceSKUAPIMock{
+				data: []*armcompute.ResourceSKU{
+					vmSKU("Standard_D4s_v5", []string{"1", "2", "3"}, &armcompute.ResourceSKURestrictions{
+						Type:       util.Ptr(armcompute.ResourceSKURestrictionsTypeLocation),
+						ReasonCode: util.Ptr(armcompute.ResourceSKURestrictionsReasonCodeNotAvailableForSubscription),
+						RestrictionInfo: &armcompute.ResourceSKURestrictionInfo{
+							Locations: []*string{util.Ptr("eastus")},
+						},
+					}),
+				},
+			},
+			expectedError: nil,
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-vm-size",
+					ValidationRule: "validation-rule-1",
+					Message:        "One or more required VM sizes unavailable to subscription. See failures for details.",
+					Details:        []string{},
+					Failures: []string{
+						"VM size 'Standard_D4s_v5' restricted in location 'eastus'; Reason code: 'NotAvailableForSubscription'",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (error getting resource SKUs) - validation result remains passing, code returned to interprets error and changes result",
+			rule: v1alpha1.VMSizeRule{
+				RuleName:       "rule-1",
+				Location:       "eastus",
+				VMSizes:        []string{"Standard_D4s_v5"},
+				SubscriptionID: "sub",
+			},
+			apiMock: resourceSKUAPIMock{
+				err: errors.New("get SKUs failed"),
+			},
+			expectedError: errors.New("failed to get resource SKUs for location: get SKUs failed"),
+			expectedResult: vapitypes.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "azure-vm-size",
+					ValidationRule: "validation-rule-1",
+					Message:        "All required VM sizes available to subscription.",
+					Details:        []string{},
+					Failures:       []string{},
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := NewVMSizeRuleService(tc.apiMock, logr.Logger{})
+		result, err := svc.ReconcileVMSizeRule(tc.rule)
+		test.CheckTestCase(t, result, tc.expectedResult, err, tc.expectedError)
+	}
+}
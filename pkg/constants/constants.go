@@ -13,4 +13,73 @@ const (
 
 	// ValidationTypeQuota is the validation type for quota rules.
 	ValidationTypeQuota string = "azure-quota"
+
+	// ValidationTypeResourceProvider is the validation type for resource provider rules.
+	ValidationTypeResourceProvider string = "azure-resource-provider"
+
+	// ValidationTypeVMSize is the validation type for VM size rules.
+	ValidationTypeVMSize string = "azure-vm-size"
+
+	// ValidationTypeMarketplaceImage is the validation type for marketplace image rules.
+	ValidationTypeMarketplaceImage string = "azure-marketplace-image"
+
+	// ValidationTypeGalleryImages is the validation type for gallery image rules.
+	ValidationTypeGalleryImages string = "azure-gallery-image"
+
+	// ValidationTypeSubnet is the validation type for subnet rules.
+	ValidationTypeSubnet string = "azure-subnet"
+
+	// ValidationTypeNetworkSecurityGroup is the validation type for network security group rules.
+	ValidationTypeNetworkSecurityGroup string = "azure-network-security-group"
+
+	// ValidationTypePolicy is the validation type for policy rules.
+	ValidationTypePolicy string = "azure-policy"
+
+	// ValidationTypeKeyVault is the validation type for key vault rules.
+	ValidationTypeKeyVault string = "azure-key-vault"
+
+	// ValidationTypeStorageAccount is the validation type for storage account rules.
+	ValidationTypeStorageAccount string = "azure-storage-account"
+
+	// ValidationTypeDNSZone is the validation type for DNS zone rules.
+	ValidationTypeDNSZone string = "azure-dns-zone"
+
+	// ValidationTypeResourceGroup is the validation type for resource group rules.
+	ValidationTypeResourceGroup string = "azure-resource-group"
+
+	// ValidationTypeManagedIdentity is the validation type for managed identity rules.
+	ValidationTypeManagedIdentity string = "azure-managed-identity"
+
+	// ValidationTypeApplicationCredential is the validation type for application credential rules.
+	ValidationTypeApplicationCredential string = "azure-application-credential"
+
+	// ValidationTypeAKSCluster is the validation type for AKS cluster rules.
+	ValidationTypeAKSCluster string = "azure-aks-cluster"
+
+	// ValidationTypeSubscription is the validation type for subscription rules.
+	ValidationTypeSubscription string = "azure-subscription"
+
+	// ValidationTypeLock is the validation type for lock rules.
+	ValidationTypeLock string = "azure-lock"
+
+	// ValidationTypeRoleDefinition is the validation type for role definition rules.
+	ValidationTypeRoleDefinition string = "azure-role-definition"
+
+	// ValidationTypeTag is the validation type for tag rules.
+	ValidationTypeTag string = "azure-tag"
+
+	// ValidationTypeResourceGraphQuery is the validation type for Resource Graph query rules.
+	ValidationTypeResourceGraphQuery string = "azure-resource-graph-query"
+
+	// ValidationTypeGroupMembership is the validation type for group membership rules.
+	ValidationTypeGroupMembership string = "azure-group-membership"
+
+	// ValidationTypeFeatureRegistration is the validation type for feature registration rules.
+	ValidationTypeFeatureRegistration string = "azure-feature-registration"
+
+	// ValidationTypeCapacityReservation is the validation type for capacity reservation rules.
+	ValidationTypeCapacityReservation string = "azure-capacity-reservation"
+
+	// ValidationTypeBudget is the validation type for budget rules.
+	ValidationTypeBudget string = "azure-budget"
 )
@@ -6,32 +6,119 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/dns/armdns"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managementgroups/armmanagementgroups"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/quota/armquota"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/validator-labs/validator/pkg/util"
 )
 
 // TestClientTimeout is the timeout used for Azure clients during tests.
 const TestClientTimeout = 10 * time.Second
 
+const (
+	// armClientModuleName and armClientModuleVersion identify the plugin in the telemetry sent
+	// with requests made by the generic Azure Resource Manager client.
+	armClientModuleName    = "github.com/validator-labs/validator-plugin-azure"
+	armClientModuleVersion = "v0.0.0"
+
+	// marketplaceOrderingAPIVersion is the API version used for Microsoft.MarketplaceOrdering
+	// requests.
+	marketplaceOrderingAPIVersion = "2021-01-01"
+
+	// policyAPIVersion is the API version used for Azure Policy (Microsoft.Authorization policy
+	// assignment, policy definition, and policy set definition) requests.
+	policyAPIVersion = "2023-04-01"
+
+	// subscriptionAPIVersion is the API version used for Microsoft.Resources subscription
+	// requests.
+	subscriptionAPIVersion = "2022-12-01"
+
+	// locksAPIVersion is the API version used for Microsoft.Authorization management lock
+	// requests.
+	locksAPIVersion = "2016-09-01"
+
+	// featuresAPIVersion is the API version used for Microsoft.Features preview feature requests.
+	featuresAPIVersion = "2021-07-01"
+
+	// activityLogAPIVersion is the API version used for Microsoft.Insights activity log requests.
+	activityLogAPIVersion = "2015-04-01"
+
+	// budgetsAPIVersion is the API version used for Microsoft.Consumption budget requests.
+	budgetsAPIVersion = "2023-05-01"
+
+	// graphAPIVersion is the Microsoft Graph API version used for Microsoft Graph requests.
+	graphAPIVersion = "v1.0"
+)
+
 // API is an container that aggregates Azure service clients.
 type API struct {
-	DenyAssignmentsClient *armauthorization.DenyAssignmentsClient
-	RoleAssignmentsClient *armauthorization.RoleAssignmentsClient
-	RoleDefinitionsClient *armauthorization.RoleDefinitionsClient
+	DenyAssignmentsClient  *armauthorization.DenyAssignmentsClient
+	RoleAssignmentsClient  *armauthorization.RoleAssignmentsClient
+	RoleDefinitionsClient  *armauthorization.RoleDefinitionsClient
+	ManagementGroupsClient *armmanagementgroups.Client
+	ResourceGraphClient    *armresourcegraph.Client
 	// Subscription ID is needed per API call for this client, so the client can't be created until
 	// right before it's used while reconciling a rule.
-	CommunityGalleryImagesClientProducer func(string) (*armcompute.CommunityGalleryImagesClient, error)
-	QuotaLimitsClient                    *armquota.Client
-	UsagesClient                         *armquota.UsagesClient
+	CommunityGalleryImagesClientProducer        func(string) (*armcompute.CommunityGalleryImagesClient, error)
+	CommunityGalleryImageVersionsClientProducer func(string) (*armcompute.CommunityGalleryImageVersionsClient, error)
+	QuotaLimitsClient                           *armquota.Client
+	UsagesClient                                *armquota.UsagesClient
+	ProvidersClientProducer                     func(string) (*armresources.ProvidersClient, error)
+	ResourceSKUsClientProducer                  func(string) (*armcompute.ResourceSKUsClient, error)
+	VirtualMachineImagesClientProducer          func(string) (*armcompute.VirtualMachineImagesClient, error)
+	GalleryImagesClientProducer                 func(string) (*armcompute.GalleryImagesClient, error)
+	GalleryImageVersionsClientProducer          func(string) (*armcompute.GalleryImageVersionsClient, error)
+	VirtualNetworksClientProducer               func(string) (*armnetwork.VirtualNetworksClient, error)
+	SecurityGroupsClientProducer                func(string) (*armnetwork.SecurityGroupsClient, error)
+	VaultsClientProducer                        func(string) (*armkeyvault.VaultsClient, error)
+	// Key Vault data plane clients are per vault, so the client can't be created until right
+	// before it's used while reconciling a rule, when the vault URL is known.
+	SecretsClientProducer                      func(string) (*azsecrets.Client, error)
+	KeysClientProducer                         func(string) (*azkeys.Client, error)
+	StorageAccountsClientProducer              func(string) (*armstorage.AccountsClient, error)
+	BlobContainersClientProducer               func(string) (*armstorage.BlobContainersClient, error)
+	DNSZonesClientProducer                     func(string) (*armdns.ZonesClient, error)
+	PrivateDNSZonesClientProducer              func(string) (*armprivatedns.PrivateZonesClient, error)
+	VirtualNetworkLinksClientProducer          func(string) (*armprivatedns.VirtualNetworkLinksClient, error)
+	ResourceGroupsClientProducer               func(string) (*armresources.ResourceGroupsClient, error)
+	UserAssignedIdentitiesClientProducer       func(string) (*armmsi.UserAssignedIdentitiesClient, error)
+	FederatedIdentityCredentialsClientProducer func(string) (*armmsi.FederatedIdentityCredentialsClient, error)
+	ManagedClustersClientProducer              func(string) (*armcontainerservice.ManagedClustersClient, error)
+	ResourcesClientProducer                    func(string) (*armresources.Client, error)
+	CapacityReservationGroupsClientProducer    func(string) (*armcompute.CapacityReservationGroupsClient, error)
+	CapacityReservationsClientProducer         func(string) (*armcompute.CapacityReservationsClient, error)
+	// ARMClient is a generic Azure Resource Manager client. It's used for the Azure APIs whose Azure
+	// SDK modules aren't dependencies of this repo.
+	ARMClient *arm.Client
+	// GraphClient is a generic Microsoft Graph client, used for the Microsoft Entra ID objects
+	// (e.g. applications) Azure Resource Manager doesn't manage. GraphEndpoint is the Microsoft
+	// Graph endpoint of the Azure cloud connected to.
+	GraphClient   *azcore.Client
+	GraphEndpoint string
 }
 
 // NewAzureAPI creates an AzureAPI.
@@ -84,6 +171,90 @@ func NewAzureAPI() (*API, error) {
 	cgiClientProducer := func(subscriptionID string) (*armcompute.CommunityGalleryImagesClient, error) {
 		return armcompute.NewCommunityGalleryImagesClient(subscriptionID, cred, opts)
 	}
+	cgivClientProducer := func(subscriptionID string) (*armcompute.CommunityGalleryImageVersionsClient, error) {
+		return armcompute.NewCommunityGalleryImageVersionsClient(subscriptionID, cred, opts)
+	}
+	providersClientProducer := func(subscriptionID string) (*armresources.ProvidersClient, error) {
+		return armresources.NewProvidersClient(subscriptionID, cred, opts)
+	}
+	resourceSKUsClientProducer := func(subscriptionID string) (*armcompute.ResourceSKUsClient, error) {
+		return armcompute.NewResourceSKUsClient(subscriptionID, cred, opts)
+	}
+	vmImagesClientProducer := func(subscriptionID string) (*armcompute.VirtualMachineImagesClient, error) {
+		return armcompute.NewVirtualMachineImagesClient(subscriptionID, cred, opts)
+	}
+	galleryImagesClientProducer := func(subscriptionID string) (*armcompute.GalleryImagesClient, error) {
+		return armcompute.NewGalleryImagesClient(subscriptionID, cred, opts)
+	}
+	galleryImageVersionsClientProducer := func(subscriptionID string) (*armcompute.GalleryImageVersionsClient, error) {
+		return armcompute.NewGalleryImageVersionsClient(subscriptionID, cred, opts)
+	}
+	vnetClientProducer := func(subscriptionID string) (*armnetwork.VirtualNetworksClient, error) {
+		return armnetwork.NewVirtualNetworksClient(subscriptionID, cred, opts)
+	}
+	nsgClientProducer := func(subscriptionID string) (*armnetwork.SecurityGroupsClient, error) {
+		return armnetwork.NewSecurityGroupsClient(subscriptionID, cred, opts)
+	}
+	vaultsClientProducer := func(subscriptionID string) (*armkeyvault.VaultsClient, error) {
+		return armkeyvault.NewVaultsClient(subscriptionID, cred, opts)
+	}
+	secretsClientProducer := func(vaultURL string) (*azsecrets.Client, error) {
+		return azsecrets.NewClient(vaultURL, cred, &azsecrets.ClientOptions{ClientOptions: opts.ClientOptions})
+	}
+	keysClientProducer := func(vaultURL string) (*azkeys.Client, error) {
+		return azkeys.NewClient(vaultURL, cred, &azkeys.ClientOptions{ClientOptions: opts.ClientOptions})
+	}
+	storageAccountsClientProducer := func(subscriptionID string) (*armstorage.AccountsClient, error) {
+		return armstorage.NewAccountsClient(subscriptionID, cred, opts)
+	}
+	blobContainersClientProducer := func(subscriptionID string) (*armstorage.BlobContainersClient, error) {
+		return armstorage.NewBlobContainersClient(subscriptionID, cred, opts)
+	}
+	dnsZonesClientProducer := func(subscriptionID string) (*armdns.ZonesClient, error) {
+		return armdns.NewZonesClient(subscriptionID, cred, opts)
+	}
+	privateDNSZonesClientProducer := func(subscriptionID string) (*armprivatedns.PrivateZonesClient, error) {
+		return armprivatedns.NewPrivateZonesClient(subscriptionID, cred, opts)
+	}
+	virtualNetworkLinksClientProducer := func(subscriptionID string) (*armprivatedns.VirtualNetworkLinksClient, error) {
+		return armprivatedns.NewVirtualNetworkLinksClient(subscriptionID, cred, opts)
+	}
+	resourceGroupsClientProducer := func(subscriptionID string) (*armresources.ResourceGroupsClient, error) {
+		return armresources.NewResourceGroupsClient(subscriptionID, cred, opts)
+	}
+	userAssignedIdentitiesClientProducer := func(subscriptionID string) (*armmsi.UserAssignedIdentitiesClient, error) {
+		return armmsi.NewUserAssignedIdentitiesClient(subscriptionID, cred, opts)
+	}
+	federatedIdentityCredentialsClientProducer := func(subscriptionID string) (*armmsi.FederatedIdentityCredentialsClient, error) {
+		return armmsi.NewFederatedIdentityCredentialsClient(subscriptionID, cred, opts)
+	}
+	managedClustersClientProducer := func(subscriptionID string) (*armcontainerservice.ManagedClustersClient, error) {
+		return armcontainerservice.NewManagedClustersClient(subscriptionID, cred, opts)
+	}
+	resourcesClientProducer := func(subscriptionID string) (*armresources.Client, error) {
+		return armresources.NewClient(subscriptionID, cred, opts)
+	}
+	capacityReservationGroupsClientProducer := func(subscriptionID string) (*armcompute.CapacityReservationGroupsClient, error) {
+		return armcompute.NewCapacityReservationGroupsClient(subscriptionID, cred, opts)
+	}
+	capacityReservationsClientProducer := func(subscriptionID string) (*armcompute.CapacityReservationsClient, error) {
+		return armcompute.NewCapacityReservationsClient(subscriptionID, cred, opts)
+	}
+
+	armClient, err := arm.NewClient(armClientModuleName, armClientModuleVersion, cred, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Resource Manager client: %w", err)
+	}
+
+	graphEndpoint := graphEndpointFromEnv()
+	graphClient, err := azcore.NewClient(armClientModuleName, armClientModuleVersion, runtime.PipelineOptions{
+		PerRetry: []policy.Policy{
+			runtime.NewBearerTokenPolicy(cred, []string{runtime.JoinPaths(graphEndpoint, ".default")}, nil),
+		},
+	}, &opts.ClientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Microsoft Graph client: %w", err)
+	}
 
 	quotaLimitsClient, err := armquota.NewClient(cred, opts)
 	if err != nil {
@@ -93,14 +264,50 @@ func NewAzureAPI() (*API, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Azure usages client: %w", err)
 	}
+	managementGroupsClient, err := armmanagementgroups.NewClient(cred, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure management groups client: %w", err)
+	}
+	resourceGraphClient, err := armresourcegraph.NewClient(cred, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Resource Graph client: %w", err)
+	}
 
 	return &API{
-		DenyAssignmentsClient:                daClient,
-		RoleAssignmentsClient:                raClient,
-		RoleDefinitionsClient:                rdClient,
-		CommunityGalleryImagesClientProducer: cgiClientProducer,
-		QuotaLimitsClient:                    quotaLimitsClient,
-		UsagesClient:                         usagesClient,
+		DenyAssignmentsClient:                       daClient,
+		RoleAssignmentsClient:                       raClient,
+		RoleDefinitionsClient:                       rdClient,
+		ManagementGroupsClient:                      managementGroupsClient,
+		ResourceGraphClient:                         resourceGraphClient,
+		CommunityGalleryImagesClientProducer:        cgiClientProducer,
+		CommunityGalleryImageVersionsClientProducer: cgivClientProducer,
+		QuotaLimitsClient:                           quotaLimitsClient,
+		UsagesClient:                                usagesClient,
+		ProvidersClientProducer:                     providersClientProducer,
+		ResourceSKUsClientProducer:                  resourceSKUsClientProducer,
+		VirtualMachineImagesClientProducer:          vmImagesClientProducer,
+		GalleryImagesClientProducer:                 galleryImagesClientProducer,
+		GalleryImageVersionsClientProducer:          galleryImageVersionsClientProducer,
+		VirtualNetworksClientProducer:               vnetClientProducer,
+		SecurityGroupsClientProducer:                nsgClientProducer,
+		VaultsClientProducer:                        vaultsClientProducer,
+		SecretsClientProducer:                       secretsClientProducer,
+		KeysClientProducer:                          keysClientProducer,
+		StorageAccountsClientProducer:               storageAccountsClientProducer,
+		BlobContainersClientProducer:                blobContainersClientProducer,
+		DNSZonesClientProducer:                      dnsZonesClientProducer,
+		PrivateDNSZonesClientProducer:               privateDNSZonesClientProducer,
+		VirtualNetworkLinksClientProducer:           virtualNetworkLinksClientProducer,
+		ResourceGroupsClientProducer:                resourceGroupsClientProducer,
+		UserAssignedIdentitiesClientProducer:        userAssignedIdentitiesClientProducer,
+		FederatedIdentityCredentialsClientProducer:  federatedIdentityCredentialsClientProducer,
+		ManagedClustersClientProducer:               managedClustersClientProducer,
+		ResourcesClientProducer:                     resourcesClientProducer,
+		CapacityReservationGroupsClientProducer:     capacityReservationGroupsClientProducer,
+		CapacityReservationsClientProducer:          capacityReservationsClientProducer,
+		ARMClient:                                   armClient,
+		GraphClient:                                 graphClient,
+		GraphEndpoint:                               graphEndpoint,
 	}, err
 }
 
@@ -223,6 +430,39 @@ func (c *RoleDefinitionsClient) GetByID(roleID string) (*armauthorization.RoleDe
 	return &roleDefinitionResp.RoleDefinition, nil
 }
 
+// GetByRoleName gets the role definitions available at a scope with a role name (aka the display
+// name of the role, e.g. "Contributor"). Role names are unique within a tenant, so at most one is
+// expected to be returned.
+func (c *RoleDefinitionsClient) GetByRoleName(scope, roleName string) ([]*armauthorization.RoleDefinition, error) {
+	var roleDefinitions []*armauthorization.RoleDefinition
+	pager := c.client.NewListPager(scope, &armauthorization.RoleDefinitionsClientListOptions{
+		Filter: util.Ptr(fmt.Sprintf("roleName eq '%s'", strings.ReplaceAll(roleName, "'", "''"))),
+	})
+
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			nextResult, err := pager.NextPage(c.ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", err)
+				return
+			}
+			if nextResult.Value != nil {
+				roleDefinitions = append(roleDefinitions, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err := <-ch:
+		return roleDefinitions, err
+	case <-c.ctx.Done():
+		return roleDefinitions, fmt.Errorf("context cancelled")
+	}
+}
+
 // RoleNameFromRoleDefinitionID extracts the name of a role (aka the non-fully-qualified ID of the
 // role) from an Azure role definition ID (aka the fully-qualified ID of the role definition).
 func RoleNameFromRoleDefinitionID(roleDefinitionID string) string {
@@ -231,19 +471,21 @@ func RoleNameFromRoleDefinitionID(roleDefinitionID string) string {
 	return roleName
 }
 
-// CommunityGalleryImagesClient is a facade over the Azure community gallery images client.
-// Exists to make our code easier to test (it handles paging).
+// CommunityGalleryImagesClient is a facade over the Azure community gallery images and community
+// gallery image versions clients. Exists to make our code easier to test (it handles paging).
 type CommunityGalleryImagesClient struct {
-	ctx            context.Context
-	clientProducer func(string) (*armcompute.CommunityGalleryImagesClient, error)
+	ctx                    context.Context
+	clientProducer         func(string) (*armcompute.CommunityGalleryImagesClient, error)
+	versionsClientProducer func(string) (*armcompute.CommunityGalleryImageVersionsClient, error)
 }
 
-// NewCommunityGalleryImagesClient creates a new AzureRoleDefinitionsClient (our facade
-// client) from a client from the Azure SDK.
-func NewCommunityGalleryImagesClient(ctx context.Context, azClientProducer func(subscriptionID string) (*armcompute.CommunityGalleryImagesClient, error)) *CommunityGalleryImagesClient {
+// NewCommunityGalleryImagesClient creates a new CommunityGalleryImagesClient (our facade client)
+// from clients from the Azure SDK.
+func NewCommunityGalleryImagesClient(ctx context.Context, azClientProducer func(subscriptionID string) (*armcompute.CommunityGalleryImagesClient, error), azVersionsClientProducer func(subscriptionID string) (*armcompute.CommunityGalleryImageVersionsClient, error)) *CommunityGalleryImagesClient {
 	return &CommunityGalleryImagesClient{
-		ctx:            ctx,
-		clientProducer: azClientProducer,
+		ctx:                    ctx,
+		clientProducer:         azClientProducer,
+		versionsClientProducer: azVersionsClientProducer,
 	}
 }
 
@@ -280,6 +522,55 @@ func (c *CommunityGalleryImagesClient) GetImagesForGallery(location, name, subsc
 	}
 }
 
+// GetImageVersion gets a version of an image in a community gallery. The version is only found if
+// it is published to the location.
+func (c *CommunityGalleryImagesClient) GetImageVersion(location, name, image, version, subscriptionID string) (*armcompute.CommunityGalleryImageVersion, error) {
+	client, err := c.versionsClientProducer(subscriptionID)
+	if err != nil {
+		return &armcompute.CommunityGalleryImageVersion{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+	resp, err := client.Get(c.ctx, location, name, image, version, nil)
+	if err != nil {
+		return &armcompute.CommunityGalleryImageVersion{}, fmt.Errorf("failed to get version %s of image %s: %w", version, image, err)
+	}
+	return &resp.CommunityGalleryImageVersion, nil
+}
+
+// GetImageVersions gets all the versions of an image in a community gallery that are published to
+// the location.
+func (c *CommunityGalleryImagesClient) GetImageVersions(location, name, image, subscriptionID string) ([]*armcompute.CommunityGalleryImageVersion, error) {
+	client, err := c.versionsClientProducer(subscriptionID)
+	if err != nil {
+		return []*armcompute.CommunityGalleryImageVersion{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+
+	var versions []*armcompute.CommunityGalleryImageVersion
+	pager := client.NewListPager(location, name, image, nil)
+
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			nextResult, err := pager.NextPage(c.ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", err)
+				return
+			}
+			if nextResult.Value != nil {
+				versions = append(versions, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err := <-ch:
+		return versions, err
+	case <-c.ctx.Done():
+		return versions, fmt.Errorf("context cancelled")
+	}
+}
+
 // QuotasClient is a facade over the Azure quotas client role definitions client.
 // Exists to make our code easier to test (it handles paging).
 type QuotasClient struct {
@@ -356,16 +647,1533 @@ func (c *QuotasClient) GetUsagesForScope(scope string) ([]*armquota.CurrentUsage
 	}
 }
 
-// azureCloudFromEnv returns the Azure cloud to use based on the AZURE_ENVIRONMENT environment
-// variable.
-func azureCloudFromEnv() cloud.Configuration {
-	switch os.Getenv("AZURE_ENVIRONMENT") {
-	case "AzureUSGovernment":
-		return cloud.AzureGovernment
-	case "AzureChinaCloud":
-		return cloud.AzureChina
-	default:
-		// Includes the env var being unset and it being set to "AzureCloud" or any other value.
-		return cloud.AzurePublic
+// ResourceProvidersClient is a facade over the Azure resource providers client. Exists to make our
+// code easier to test (it handles paging).
+type ResourceProvidersClient struct {
+	ctx            context.Context
+	clientProducer func(string) (*armresources.ProvidersClient, error)
+}
+
+// NewResourceProvidersClient creates a new ResourceProvidersClient (our facade client) from a
+// client from the Azure SDK.
+func NewResourceProvidersClient(ctx context.Context, azClientProducer func(subscriptionID string) (*armresources.ProvidersClient, error)) *ResourceProvidersClient {
+	return &ResourceProvidersClient{
+		ctx:            ctx,
+		clientProducer: azClientProducer,
+	}
+}
+
+// GetProvidersForSubscription gets all the resource providers for a subscription, including their
+// registration states.
+func (c *ResourceProvidersClient) GetProvidersForSubscription(subscriptionID string) ([]*armresources.Provider, error) {
+	client, err := c.clientProducer(subscriptionID)
+	if err != nil {
+		return []*armresources.Provider{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+
+	var providers []*armresources.Provider
+	pager := client.NewListPager(nil)
+
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			nextResult, err := pager.NextPage(c.ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", err)
+				return
+			}
+			if nextResult.Value != nil {
+				providers = append(providers, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err := <-ch:
+		return providers, err
+	case <-c.ctx.Done():
+		return providers, fmt.Errorf("context cancelled")
+	}
+}
+
+// GetProvider gets a resource provider of a subscription by its namespace.
+func (c *ResourceProvidersClient) GetProvider(namespace, subscriptionID string) (*armresources.Provider, error) {
+	client, err := c.clientProducer(subscriptionID)
+	if err != nil {
+		return &armresources.Provider{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+
+	resp, err := client.Get(c.ctx, namespace, nil)
+	if err != nil {
+		return &armresources.Provider{}, fmt.Errorf("failed to get resource provider %s: %w", namespace, err)
+	}
+	return &resp.Provider, nil
+}
+
+// ResourceSKUsClient is a facade over the Azure compute resource SKUs client. Exists to make our
+// code easier to test (it handles paging).
+type ResourceSKUsClient struct {
+	ctx            context.Context
+	clientProducer func(string) (*armcompute.ResourceSKUsClient, error)
+}
+
+// NewResourceSKUsClient creates a new ResourceSKUsClient (our facade client) from a client from
+// the Azure SDK.
+func NewResourceSKUsClient(ctx context.Context, azClientProducer func(subscriptionID string) (*armcompute.ResourceSKUsClient, error)) *ResourceSKUsClient {
+	return &ResourceSKUsClient{
+		ctx:            ctx,
+		clientProducer: azClientProducer,
+	}
+}
+
+// GetResourceSKUsForLocation gets all the compute resource SKUs available to a subscription in a
+// location, including any restrictions that apply to the subscription.
+func (c *ResourceSKUsClient) GetResourceSKUsForLocation(location, subscriptionID string) ([]*armcompute.ResourceSKU, error) {
+	client, err := c.clientProducer(subscriptionID)
+	if err != nil {
+		return []*armcompute.ResourceSKU{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+
+	var skus []*armcompute.ResourceSKU
+	pager := client.NewListPager(&armcompute.ResourceSKUsClientListOptions{
+		Filter: util.Ptr(fmt.Sprintf("location eq '%s'", location)),
+	})
+
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			nextResult, err := pager.NextPage(c.ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", err)
+				return
+			}
+			if nextResult.Value != nil {
+				skus = append(skus, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err := <-ch:
+		return skus, err
+	case <-c.ctx.Done():
+		return skus, fmt.Errorf("context cancelled")
+	}
+}
+
+// MarketplaceAgreement is the legal terms agreement of a marketplace image plan in a subscription.
+// We model the parts of the Microsoft.MarketplaceOrdering API response we need ourselves because its
+// Azure SDK module (armmarketplaceordering) isn't a dependency of this repo.
+type MarketplaceAgreement struct {
+	Properties *MarketplaceAgreementProperties `json:"properties,omitempty"`
+}
+
+// MarketplaceAgreementProperties are the properties of a MarketplaceAgreement.
+type MarketplaceAgreementProperties struct {
+	Accepted  *bool   `json:"accepted,omitempty"`
+	Publisher *string `json:"publisher,omitempty"`
+	Product   *string `json:"product,omitempty"`
+	Plan      *string `json:"plan,omitempty"`
+}
+
+// MarketplaceImagesClient is a facade over the Azure Resource Manager client for marketplace
+// agreements and the Azure virtual machine images client. Code that uses this instead of the
+// actual Azure clients is easier to test because it won't need to deal with HTTP requests.
+type MarketplaceImagesClient struct {
+	ctx                  context.Context
+	armClient            *arm.Client
+	imagesClientProducer func(string) (*armcompute.VirtualMachineImagesClient, error)
+}
+
+// NewMarketplaceImagesClient creates a new MarketplaceImagesClient (our facade client) from
+// clients from the Azure SDK.
+func NewMarketplaceImagesClient(ctx context.Context, armClient *arm.Client, azImagesClientProducer func(subscriptionID string) (*armcompute.VirtualMachineImagesClient, error)) *MarketplaceImagesClient {
+	return &MarketplaceImagesClient{
+		ctx:                  ctx,
+		armClient:            armClient,
+		imagesClientProducer: azImagesClientProducer,
+	}
+}
+
+// GetAgreement gets the current legal terms agreement of a virtual machine marketplace image plan
+// in a subscription.
+func (c *MarketplaceImagesClient) GetAgreement(publisher, offer, plan, subscriptionID string) (*MarketplaceAgreement, error) {
+	path := fmt.Sprintf(
+		"/subscriptions/%s/providers/Microsoft.MarketplaceOrdering/offerTypes/virtualmachine/publishers/%s/offers/%s/plans/%s/agreements/current",
+		url.PathEscape(subscriptionID), url.PathEscape(publisher), url.PathEscape(offer), url.PathEscape(plan),
+	)
+	agreement := &MarketplaceAgreement{}
+	if err := armGet(c.ctx, c.armClient, path, marketplaceOrderingAPIVersion, agreement); err != nil {
+		return &MarketplaceAgreement{}, fmt.Errorf("failed to get marketplace agreement for plan %s/%s/%s: %w", publisher, offer, plan, err)
+	}
+	return agreement, nil
+}
+
+// GetImageVersions gets all the versions of a virtual machine marketplace image in a location.
+func (c *MarketplaceImagesClient) GetImageVersions(location, publisher, offer, sku, subscriptionID string) ([]*armcompute.VirtualMachineImageResource, error) {
+	client, err := c.imagesClientProducer(subscriptionID)
+	if err != nil {
+		return []*armcompute.VirtualMachineImageResource{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+	resp, err := client.List(c.ctx, location, publisher, offer, sku, nil)
+	if err != nil {
+		return []*armcompute.VirtualMachineImageResource{}, fmt.Errorf("failed to get versions of image %s/%s/%s: %w", publisher, offer, sku, err)
+	}
+	return resp.VirtualMachineImageResourceArray, nil
+}
+
+// GalleryImagesClient is a facade over the Azure gallery images and gallery image versions
+// clients. Exists to make our code easier to test (it handles paging).
+type GalleryImagesClient struct {
+	ctx                    context.Context
+	imagesClientProducer   func(string) (*armcompute.GalleryImagesClient, error)
+	versionsClientProducer func(string) (*armcompute.GalleryImageVersionsClient, error)
+}
+
+// NewGalleryImagesClient creates a new GalleryImagesClient (our facade client) from clients from
+// the Azure SDK.
+func NewGalleryImagesClient(ctx context.Context, azImagesClientProducer func(subscriptionID string) (*armcompute.GalleryImagesClient, error), azVersionsClientProducer func(subscriptionID string) (*armcompute.GalleryImageVersionsClient, error)) *GalleryImagesClient {
+	return &GalleryImagesClient{
+		ctx:                    ctx,
+		imagesClientProducer:   azImagesClientProducer,
+		versionsClientProducer: azVersionsClientProducer,
+	}
+}
+
+// GetImageDefinitions gets all the image definitions in a gallery.
+func (c *GalleryImagesClient) GetImageDefinitions(resourceGroup, gallery, subscriptionID string) ([]*armcompute.GalleryImage, error) {
+	client, err := c.imagesClientProducer(subscriptionID)
+	if err != nil {
+		return []*armcompute.GalleryImage{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+
+	var images []*armcompute.GalleryImage
+	pager := client.NewListByGalleryPager(resourceGroup, gallery, nil)
+
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			nextResult, err := pager.NextPage(c.ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", err)
+				return
+			}
+			if nextResult.Value != nil {
+				images = append(images, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err := <-ch:
+		return images, err
+	case <-c.ctx.Done():
+		return images, fmt.Errorf("context cancelled")
+	}
+}
+
+// GetImageVersion gets an image version of an image definition in a gallery, including its
+// replication status.
+func (c *GalleryImagesClient) GetImageVersion(resourceGroup, gallery, image, version, subscriptionID string) (*armcompute.GalleryImageVersion, error) {
+	client, err := c.versionsClientProducer(subscriptionID)
+	if err != nil {
+		return &armcompute.GalleryImageVersion{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+	resp, err := client.Get(c.ctx, resourceGroup, gallery, image, version, &armcompute.GalleryImageVersionsClientGetOptions{
+		Expand: util.Ptr(armcompute.ReplicationStatusTypesReplicationStatus),
+	})
+	if err != nil {
+		return &armcompute.GalleryImageVersion{}, fmt.Errorf("failed to get version %s of image %s: %w", version, image, err)
+	}
+	return &resp.GalleryImageVersion, nil
+}
+
+// VirtualNetworksClient is a facade over the Azure virtual networks client. Code that uses this
+// instead of the actual Azure client is easier to test because it won't need to deal with
+// producing clients per subscription.
+type VirtualNetworksClient struct {
+	ctx            context.Context
+	clientProducer func(string) (*armnetwork.VirtualNetworksClient, error)
+}
+
+// NewVirtualNetworksClient creates a new VirtualNetworksClient (our facade client) from a client
+// from the Azure SDK.
+func NewVirtualNetworksClient(ctx context.Context, azClientProducer func(subscriptionID string) (*armnetwork.VirtualNetworksClient, error)) *VirtualNetworksClient {
+	return &VirtualNetworksClient{
+		ctx:            ctx,
+		clientProducer: azClientProducer,
+	}
+}
+
+// GetVirtualNetwork gets a virtual network, including its subnets and the IP configurations in
+// them.
+func (c *VirtualNetworksClient) GetVirtualNetwork(resourceGroup, name, subscriptionID string) (*armnetwork.VirtualNetwork, error) {
+	client, err := c.clientProducer(subscriptionID)
+	if err != nil {
+		return &armnetwork.VirtualNetwork{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+	resp, err := client.Get(c.ctx, resourceGroup, name, nil)
+	if err != nil {
+		return &armnetwork.VirtualNetwork{}, fmt.Errorf("failed to get virtual network %s: %w", name, err)
+	}
+	return &resp.VirtualNetwork, nil
+}
+
+// NetworkSecurityGroupsClient is a facade over the Azure network security groups client. Code
+// that uses this instead of the actual Azure client is easier to test because it won't need to
+// deal with producing clients per subscription.
+type NetworkSecurityGroupsClient struct {
+	ctx            context.Context
+	clientProducer func(string) (*armnetwork.SecurityGroupsClient, error)
+}
+
+// NewNetworkSecurityGroupsClient creates a new NetworkSecurityGroupsClient (our facade client)
+// from a client from the Azure SDK.
+func NewNetworkSecurityGroupsClient(ctx context.Context, azClientProducer func(subscriptionID string) (*armnetwork.SecurityGroupsClient, error)) *NetworkSecurityGroupsClient {
+	return &NetworkSecurityGroupsClient{
+		ctx:            ctx,
+		clientProducer: azClientProducer,
+	}
+}
+
+// GetNetworkSecurityGroup gets a network security group, including its security rules and default
+// security rules.
+func (c *NetworkSecurityGroupsClient) GetNetworkSecurityGroup(resourceGroup, name, subscriptionID string) (*armnetwork.SecurityGroup, error) {
+	client, err := c.clientProducer(subscriptionID)
+	if err != nil {
+		return &armnetwork.SecurityGroup{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+	resp, err := client.Get(c.ctx, resourceGroup, name, nil)
+	if err != nil {
+		return &armnetwork.SecurityGroup{}, fmt.Errorf("failed to get network security group %s: %w", name, err)
+	}
+	return &resp.SecurityGroup, nil
+}
+
+// StorageAccountsClient is a facade over the Azure storage accounts and blob containers clients.
+// Code that uses this instead of the actual Azure clients is easier to test because it won't need
+// to deal with paging or producing clients per subscription.
+type StorageAccountsClient struct {
+	ctx                          context.Context
+	accountsClientProducer       func(string) (*armstorage.AccountsClient, error)
+	blobContainersClientProducer func(string) (*armstorage.BlobContainersClient, error)
+}
+
+// NewStorageAccountsClient creates a new StorageAccountsClient (our facade client) from clients
+// from the Azure SDK.
+func NewStorageAccountsClient(ctx context.Context, azAccountsClientProducer func(subscriptionID string) (*armstorage.AccountsClient, error), azBlobContainersClientProducer func(subscriptionID string) (*armstorage.BlobContainersClient, error)) *StorageAccountsClient {
+	return &StorageAccountsClient{
+		ctx:                          ctx,
+		accountsClientProducer:       azAccountsClientProducer,
+		blobContainersClientProducer: azBlobContainersClientProducer,
+	}
+}
+
+// GetStorageAccount gets a storage account, including its properties.
+func (c *StorageAccountsClient) GetStorageAccount(resourceGroup, name, subscriptionID string) (*armstorage.Account, error) {
+	client, err := c.accountsClientProducer(subscriptionID)
+	if err != nil {
+		return &armstorage.Account{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+	resp, err := client.GetProperties(c.ctx, resourceGroup, name, nil)
+	if err != nil {
+		return &armstorage.Account{}, fmt.Errorf("failed to get storage account %s: %w", name, err)
+	}
+	return &resp.Account, nil
+}
+
+// GetBlobContainers gets all the blob containers in a storage account.
+func (c *StorageAccountsClient) GetBlobContainers(resourceGroup, accountName, subscriptionID string) ([]*armstorage.ListContainerItem, error) {
+	client, err := c.blobContainersClientProducer(subscriptionID)
+	if err != nil {
+		return []*armstorage.ListContainerItem{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+
+	var containers []*armstorage.ListContainerItem
+	pager := client.NewListPager(resourceGroup, accountName, nil)
+
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			nextResult, err := pager.NextPage(c.ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", err)
+				return
+			}
+			if nextResult.Value != nil {
+				containers = append(containers, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err := <-ch:
+		return containers, err
+	case <-c.ctx.Done():
+		return containers, fmt.Errorf("context cancelled")
+	}
+}
+
+// DNSZonesClient is a facade over the Azure DNS zones, private DNS zones, and virtual network links
+// clients. Code that uses this instead of the actual Azure clients is easier to test because it
+// won't need to deal with paging or producing clients per subscription.
+type DNSZonesClient struct {
+	ctx                               context.Context
+	zonesClientProducer               func(string) (*armdns.ZonesClient, error)
+	privateZonesClientProducer        func(string) (*armprivatedns.PrivateZonesClient, error)
+	virtualNetworkLinksClientProducer func(string) (*armprivatedns.VirtualNetworkLinksClient, error)
+}
+
+// NewDNSZonesClient creates a new DNSZonesClient (our facade client) from clients from the Azure
+// SDK.
+func NewDNSZonesClient(ctx context.Context, azZonesClientProducer func(subscriptionID string) (*armdns.ZonesClient, error), azPrivateZonesClientProducer func(subscriptionID string) (*armprivatedns.PrivateZonesClient, error), azVirtualNetworkLinksClientProducer func(subscriptionID string) (*armprivatedns.VirtualNetworkLinksClient, error)) *DNSZonesClient {
+	return &DNSZonesClient{
+		ctx:                               ctx,
+		zonesClientProducer:               azZonesClientProducer,
+		privateZonesClientProducer:        azPrivateZonesClientProducer,
+		virtualNetworkLinksClientProducer: azVirtualNetworkLinksClientProducer,
+	}
+}
+
+// GetZone gets a public DNS zone.
+func (c *DNSZonesClient) GetZone(resourceGroup, name, subscriptionID string) (*armdns.Zone, error) {
+	client, err := c.zonesClientProducer(subscriptionID)
+	if err != nil {
+		return &armdns.Zone{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+	resp, err := client.Get(c.ctx, resourceGroup, name, nil)
+	if err != nil {
+		return &armdns.Zone{}, fmt.Errorf("failed to get DNS zone %s: %w", name, err)
+	}
+	return &resp.Zone, nil
+}
+
+// GetPrivateZone gets a private DNS zone.
+func (c *DNSZonesClient) GetPrivateZone(resourceGroup, name, subscriptionID string) (*armprivatedns.PrivateZone, error) {
+	client, err := c.privateZonesClientProducer(subscriptionID)
+	if err != nil {
+		return &armprivatedns.PrivateZone{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+	resp, err := client.Get(c.ctx, resourceGroup, name, nil)
+	if err != nil {
+		return &armprivatedns.PrivateZone{}, fmt.Errorf("failed to get private DNS zone %s: %w", name, err)
+	}
+	return &resp.PrivateZone, nil
+}
+
+// GetVirtualNetworkLinks gets all the virtual network links of a private DNS zone.
+func (c *DNSZonesClient) GetVirtualNetworkLinks(resourceGroup, zoneName, subscriptionID string) ([]*armprivatedns.VirtualNetworkLink, error) {
+	client, err := c.virtualNetworkLinksClientProducer(subscriptionID)
+	if err != nil {
+		return []*armprivatedns.VirtualNetworkLink{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+
+	var links []*armprivatedns.VirtualNetworkLink
+	pager := client.NewListPager(resourceGroup, zoneName, nil)
+
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			nextResult, err := pager.NextPage(c.ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", err)
+				return
+			}
+			if nextResult.Value != nil {
+				links = append(links, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err := <-ch:
+		return links, err
+	case <-c.ctx.Done():
+		return links, fmt.Errorf("context cancelled")
+	}
+}
+
+// ResourceGroupsClient is a facade over the Azure resource groups client. Code that uses this
+// instead of the actual Azure client is easier to test because it won't need to deal with
+// producing clients per subscription.
+type ResourceGroupsClient struct {
+	ctx            context.Context
+	clientProducer func(string) (*armresources.ResourceGroupsClient, error)
+}
+
+// NewResourceGroupsClient creates a new ResourceGroupsClient (our facade client) from a client
+// from the Azure SDK.
+func NewResourceGroupsClient(ctx context.Context, azClientProducer func(subscriptionID string) (*armresources.ResourceGroupsClient, error)) *ResourceGroupsClient {
+	return &ResourceGroupsClient{
+		ctx:            ctx,
+		clientProducer: azClientProducer,
+	}
+}
+
+// GetResourceGroup gets a resource group, including its location, tags, and provisioning state.
+func (c *ResourceGroupsClient) GetResourceGroup(name, subscriptionID string) (*armresources.ResourceGroup, error) {
+	client, err := c.clientProducer(subscriptionID)
+	if err != nil {
+		return &armresources.ResourceGroup{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+	resp, err := client.Get(c.ctx, name, nil)
+	if err != nil {
+		return &armresources.ResourceGroup{}, fmt.Errorf("failed to get resource group %s: %w", name, err)
+	}
+	return &resp.ResourceGroup, nil
+}
+
+// ManagedIdentitiesClient is a facade over the Azure user-assigned identities and federated
+// identity credentials clients. Code that uses this instead of the actual Azure clients is easier
+// to test because it won't need to deal with paging or producing clients per subscription.
+type ManagedIdentitiesClient struct {
+	ctx                                        context.Context
+	userAssignedIdentitiesClientProducer       func(string) (*armmsi.UserAssignedIdentitiesClient, error)
+	federatedIdentityCredentialsClientProducer func(string) (*armmsi.FederatedIdentityCredentialsClient, error)
+}
+
+// NewManagedIdentitiesClient creates a new ManagedIdentitiesClient (our facade client) from
+// clients from the Azure SDK.
+func NewManagedIdentitiesClient(ctx context.Context, azUserAssignedIdentitiesClientProducer func(subscriptionID string) (*armmsi.UserAssignedIdentitiesClient, error), azFederatedIdentityCredentialsClientProducer func(subscriptionID string) (*armmsi.FederatedIdentityCredentialsClient, error)) *ManagedIdentitiesClient {
+	return &ManagedIdentitiesClient{
+		ctx:                                  ctx,
+		userAssignedIdentitiesClientProducer: azUserAssignedIdentitiesClientProducer,
+		federatedIdentityCredentialsClientProducer: azFederatedIdentityCredentialsClientProducer,
+	}
+}
+
+// GetUserAssignedIdentity gets a user-assigned managed identity.
+func (c *ManagedIdentitiesClient) GetUserAssignedIdentity(resourceGroup, name, subscriptionID string) (*armmsi.Identity, error) {
+	client, err := c.userAssignedIdentitiesClientProducer(subscriptionID)
+	if err != nil {
+		return &armmsi.Identity{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+	resp, err := client.Get(c.ctx, resourceGroup, name, nil)
+	if err != nil {
+		return &armmsi.Identity{}, fmt.Errorf("failed to get user-assigned identity %s: %w", name, err)
+	}
+	return &resp.Identity, nil
+}
+
+// GetFederatedIdentityCredentials gets all the federated identity credentials of a user-assigned
+// managed identity.
+func (c *ManagedIdentitiesClient) GetFederatedIdentityCredentials(resourceGroup, identityName, subscriptionID string) ([]*armmsi.FederatedIdentityCredential, error) {
+	client, err := c.federatedIdentityCredentialsClientProducer(subscriptionID)
+	if err != nil {
+		return []*armmsi.FederatedIdentityCredential{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+
+	var credentials []*armmsi.FederatedIdentityCredential
+	pager := client.NewListPager(resourceGroup, identityName, nil)
+
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			nextResult, err := pager.NextPage(c.ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", err)
+				return
+			}
+			if nextResult.Value != nil {
+				credentials = append(credentials, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err := <-ch:
+		return credentials, err
+	case <-c.ctx.Done():
+		return credentials, fmt.Errorf("context cancelled")
+	}
+}
+
+// GraphPasswordCredential is a client secret of a Microsoft Entra ID application or service
+// principal.
+type GraphPasswordCredential struct {
+	KeyID       *string `json:"keyId,omitempty"`
+	DisplayName *string `json:"displayName,omitempty"`
+	// Hint is the first three characters of the client secret.
+	Hint        *string    `json:"hint,omitempty"`
+	EndDateTime *time.Time `json:"endDateTime,omitempty"`
+}
+
+// GraphKeyCredential is a certificate of a Microsoft Entra ID application or service principal.
+type GraphKeyCredential struct {
+	KeyID       *string    `json:"keyId,omitempty"`
+	DisplayName *string    `json:"displayName,omitempty"`
+	Type        *string    `json:"type,omitempty"`
+	Usage       *string    `json:"usage,omitempty"`
+	EndDateTime *time.Time `json:"endDateTime,omitempty"`
+}
+
+// GraphApplication is a Microsoft Entra ID application (app registration) or service principal.
+// Only the properties the plugin needs are included. Applications and service principals have the
+// same credential properties, so the same type is used for both.
+type GraphApplication struct {
+	ID                  *string                    `json:"id,omitempty"`
+	AppID               *string                    `json:"appId,omitempty"`
+	DisplayName         *string                    `json:"displayName,omitempty"`
+	PasswordCredentials []*GraphPasswordCredential `json:"passwordCredentials,omitempty"`
+	KeyCredentials      []*GraphKeyCredential      `json:"keyCredentials,omitempty"`
+}
+
+// ApplicationsClient is a facade over Microsoft Graph for getting Microsoft Entra ID applications
+// and service principals. There is no Azure SDK module for Microsoft Graph, so the generic
+// Microsoft Graph client is used.
+type ApplicationsClient struct {
+	ctx      context.Context
+	client   *azcore.Client
+	endpoint string
+}
+
+// NewApplicationsClient creates a new ApplicationsClient (our facade client) from a generic
+// Microsoft Graph client and the Microsoft Graph endpoint to use.
+func NewApplicationsClient(ctx context.Context, client *azcore.Client, endpoint string) *ApplicationsClient {
+	return &ApplicationsClient{
+		ctx:      ctx,
+		client:   client,
+		endpoint: endpoint,
+	}
+}
+
+// GetApplication gets an application by its application (client) ID, including its credentials.
+func (c *ApplicationsClient) GetApplication(appID string) (*GraphApplication, error) {
+	return c.getByAppID("applications", appID)
+}
+
+// GetServicePrincipal gets a service principal by the application (client) ID of its application,
+// including its credentials.
+func (c *ApplicationsClient) GetServicePrincipal(appID string) (*GraphApplication, error) {
+	return c.getByAppID("servicePrincipals", appID)
+}
+
+// getByAppID gets an application or service principal from a Microsoft Graph collection using the
+// alternate key appId.
+func (c *ApplicationsClient) getByAppID(collection, appID string) (*GraphApplication, error) {
+	path := fmt.Sprintf("%s/%s(appId='%s')", graphAPIVersion, collection, url.PathEscape(strings.ReplaceAll(appID, "'", "''")))
+	query := url.Values{"$select": []string{"id,appId,displayName,passwordCredentials,keyCredentials"}}
+	app := &GraphApplication{}
+	if err := getURL(c.ctx, c.client.Pipeline(), runtime.JoinPaths(c.endpoint, path), query, app); err != nil {
+		return &GraphApplication{}, fmt.Errorf("failed to get %s with app ID %s: %w", collection, appID, err)
+	}
+	return app, nil
+}
+
+// GraphGroup is a Microsoft Entra ID group. Only the properties the plugin needs are included.
+type GraphGroup struct {
+	ID          *string `json:"id,omitempty"`
+	DisplayName *string `json:"displayName,omitempty"`
+}
+
+// GroupsClient is a facade over Microsoft Graph for getting the Microsoft Entra ID groups principals
+// are members of. There is no Azure SDK module for Microsoft Graph, so the generic Microsoft Graph
+// client is used.
+type GroupsClient struct {
+	ctx      context.Context
+	client   *azcore.Client
+	endpoint string
+}
+
+// NewGroupsClient creates a new GroupsClient (our facade client) from a generic Microsoft Graph
+// client and the Microsoft Graph endpoint to use.
+func NewGroupsClient(ctx context.Context, client *azcore.Client, endpoint string) *GroupsClient {
+	return &GroupsClient{
+		ctx:      ctx,
+		client:   client,
+		endpoint: endpoint,
+	}
+}
+
+// GetTransitiveGroups gets all the groups a principal (a user, service principal, or managed
+// identity) is a member of, either directly or through nested groups, by the principal's object
+// ID.
+func (c *GroupsClient) GetTransitiveGroups(principalID string) ([]*GraphGroup, error) {
+	path := fmt.Sprintf("%s/directoryObjects/%s/transitiveMemberOf/microsoft.graph.group", graphAPIVersion, url.PathEscape(principalID))
+	query := url.Values{"$select": []string{"id,displayName"}}
+	groups, err := graphList[GraphGroup](c.ctx, c.client, runtime.JoinPaths(c.endpoint, path), query)
+	if err != nil {
+		return []*GraphGroup{}, fmt.Errorf("failed to get groups of principal %s: %w", principalID, err)
+	}
+	return groups, nil
+}
+
+// KeyVaultsClient is a facade over the Azure Key Vault vaults, secrets, and keys clients. Exists
+// to make our code easier to test (it handles paging).
+type KeyVaultsClient struct {
+	ctx                   context.Context
+	vaultsClientProducer  func(string) (*armkeyvault.VaultsClient, error)
+	secretsClientProducer func(string) (*azsecrets.Client, error)
+	keysClientProducer    func(string) (*azkeys.Client, error)
+}
+
+// NewKeyVaultsClient creates a new KeyVaultsClient (our facade client) from clients from the Azure
+// SDK.
+func NewKeyVaultsClient(ctx context.Context, azVaultsClientProducer func(subscriptionID string) (*armkeyvault.VaultsClient, error), azSecretsClientProducer func(vaultURL string) (*azsecrets.Client, error), azKeysClientProducer func(vaultURL string) (*azkeys.Client, error)) *KeyVaultsClient {
+	return &KeyVaultsClient{
+		ctx:                   ctx,
+		vaultsClientProducer:  azVaultsClientProducer,
+		secretsClientProducer: azSecretsClientProducer,
+		keysClientProducer:    azKeysClientProducer,
+	}
+}
+
+// GetVault gets a key vault, including its permission model and access policies.
+func (c *KeyVaultsClient) GetVault(resourceGroup, name, subscriptionID string) (*armkeyvault.Vault, error) {
+	client, err := c.vaultsClientProducer(subscriptionID)
+	if err != nil {
+		return &armkeyvault.Vault{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+	resp, err := client.Get(c.ctx, resourceGroup, name, nil)
+	if err != nil {
+		return &armkeyvault.Vault{}, fmt.Errorf("failed to get key vault %s: %w", name, err)
+	}
+	return &resp.Vault, nil
+}
+
+// GetSecretProperties gets the properties of all the secrets in a key vault, including the secrets
+// that back certificates. Secret values are not retrieved.
+func (c *KeyVaultsClient) GetSecretProperties(vaultURL string) ([]*azsecrets.SecretProperties, error) {
+	client, err := c.secretsClientProducer(vaultURL)
+	if err != nil {
+		return []*azsecrets.SecretProperties{}, fmt.Errorf("failed to produce client with vault URL %s: %w", vaultURL, err)
+	}
+
+	var secrets []*azsecrets.SecretProperties
+	pager := client.NewListSecretPropertiesPager(nil)
+
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			nextResult, err := pager.NextPage(c.ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", err)
+				return
+			}
+			if nextResult.Value != nil {
+				secrets = append(secrets, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err := <-ch:
+		return secrets, err
+	case <-c.ctx.Done():
+		return secrets, fmt.Errorf("context cancelled")
+	}
+}
+
+// GetKeyProperties gets the properties of all the keys in a key vault. Key material is not
+// retrieved.
+func (c *KeyVaultsClient) GetKeyProperties(vaultURL string) ([]*azkeys.KeyProperties, error) {
+	client, err := c.keysClientProducer(vaultURL)
+	if err != nil {
+		return []*azkeys.KeyProperties{}, fmt.Errorf("failed to produce client with vault URL %s: %w", vaultURL, err)
+	}
+
+	var keys []*azkeys.KeyProperties
+	pager := client.NewListKeyPropertiesPager(nil)
+
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			nextResult, err := pager.NextPage(c.ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", err)
+				return
+			}
+			if nextResult.Value != nil {
+				keys = append(keys, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err := <-ch:
+		return keys, err
+	case <-c.ctx.Done():
+		return keys, fmt.Errorf("context cancelled")
+	}
+}
+
+// PolicyAssignment is an Azure Policy assignment. We model the parts of the Azure Policy API
+// responses we need ourselves because its Azure SDK module (armpolicy) isn't a dependency of this
+// repo.
+type PolicyAssignment struct {
+	ID         *string                     `json:"id,omitempty"`
+	Name       *string                     `json:"name,omitempty"`
+	Properties *PolicyAssignmentProperties `json:"properties,omitempty"`
+}
+
+// PolicyAssignmentProperties are the properties of a PolicyAssignment.
+type PolicyAssignmentProperties struct {
+	DisplayName        *string                          `json:"displayName,omitempty"`
+	PolicyDefinitionID *string                          `json:"policyDefinitionId,omitempty"`
+	Parameters         map[string]*PolicyParameterValue `json:"parameters,omitempty"`
+	EnforcementMode    *string                          `json:"enforcementMode,omitempty"`
+	NotScopes          []*string                        `json:"notScopes,omitempty"`
+}
+
+// PolicyParameterValue is the value of a parameter passed to a policy definition or policy set
+// definition.
+type PolicyParameterValue struct {
+	Value any `json:"value,omitempty"`
+}
+
+// PolicyParameterDefinition is the definition of a parameter of a policy definition or policy set
+// definition.
+type PolicyParameterDefinition struct {
+	DefaultValue any `json:"defaultValue,omitempty"`
+}
+
+// PolicyDefinition is an Azure Policy definition.
+type PolicyDefinition struct {
+	ID         *string                     `json:"id,omitempty"`
+	Name       *string                     `json:"name,omitempty"`
+	Properties *PolicyDefinitionProperties `json:"properties,omitempty"`
+}
+
+// PolicyDefinitionProperties are the properties of a PolicyDefinition.
+type PolicyDefinitionProperties struct {
+	DisplayName *string                               `json:"displayName,omitempty"`
+	Mode        *string                               `json:"mode,omitempty"`
+	Parameters  map[string]*PolicyParameterDefinition `json:"parameters,omitempty"`
+	PolicyRule  *PolicyDefinitionRule                 `json:"policyRule,omitempty"`
+}
+
+// PolicyDefinitionRule is the rule of a PolicyDefinition. If is the condition, which is left as
+// generic JSON because it's a recursive structure of logical operators and conditions.
+type PolicyDefinitionRule struct {
+	If   map[string]any              `json:"if,omitempty"`
+	Then *PolicyDefinitionRuleEffect `json:"then,omitempty"`
+}
+
+// PolicyDefinitionRuleEffect is the effect of a PolicyDefinitionRule. Effect is either an effect
+// (e.g. "deny") or a parameter expression (e.g. "[parameters('effect')]").
+type PolicyDefinitionRuleEffect struct {
+	Effect *string `json:"effect,omitempty"`
+}
+
+// PolicySetDefinition is an Azure Policy set definition (aka initiative).
+type PolicySetDefinition struct {
+	ID         *string                        `json:"id,omitempty"`
+	Name       *string                        `json:"name,omitempty"`
+	Properties *PolicySetDefinitionProperties `json:"properties,omitempty"`
+}
+
+// PolicySetDefinitionProperties are the properties of a PolicySetDefinition.
+type PolicySetDefinitionProperties struct {
+	DisplayName       *string                               `json:"displayName,omitempty"`
+	Parameters        map[string]*PolicyParameterDefinition `json:"parameters,omitempty"`
+	PolicyDefinitions []*PolicyDefinitionReference          `json:"policyDefinitions,omitempty"`
+}
+
+// PolicyDefinitionReference is a reference to a policy definition in a PolicySetDefinition. The
+// values of Parameters can be parameter expressions referring to parameters of the set definition.
+type PolicyDefinitionReference struct {
+	PolicyDefinitionID          *string                          `json:"policyDefinitionId,omitempty"`
+	PolicyDefinitionReferenceID *string                          `json:"policyDefinitionReferenceId,omitempty"`
+	Parameters                  map[string]*PolicyParameterValue `json:"parameters,omitempty"`
+}
+
+// PoliciesClient is a facade over the Azure Resource Manager client for Azure Policy. Code that
+// uses this instead of the actual Azure client is easier to test because it won't need to deal
+// with HTTP requests.
+type PoliciesClient struct {
+	ctx       context.Context
+	armClient *arm.Client
+}
+
+// NewPoliciesClient creates a new PoliciesClient (our facade client) from a client from the Azure
+// SDK.
+func NewPoliciesClient(ctx context.Context, armClient *arm.Client) *PoliciesClient {
+	return &PoliciesClient{
+		ctx:       ctx,
+		armClient: armClient,
+	}
+}
+
+// GetPolicyAssignmentsForScope gets all the policy assignments that apply to a scope, including
+// those inherited from higher level scopes.
+func (c *PoliciesClient) GetPolicyAssignmentsForScope(scope string) ([]*PolicyAssignment, error) {
+	path := fmt.Sprintf("%s/providers/Microsoft.Authorization/policyAssignments", strings.TrimSuffix(scope, "/"))
+	assignments, err := armList[PolicyAssignment](c.ctx, c.armClient, path, policyAPIVersion, url.Values{"$filter": []string{"atScope()"}})
+	if err != nil {
+		return []*PolicyAssignment{}, fmt.Errorf("failed to get policy assignments for scope %s: %w", scope, err)
+	}
+	return assignments, nil
+}
+
+// GetPolicyDefinition gets a policy definition by its fully-qualified ID. Works for both built-in
+// and custom policy definitions.
+func (c *PoliciesClient) GetPolicyDefinition(id string) (*PolicyDefinition, error) {
+	definition := &PolicyDefinition{}
+	if err := armGet(c.ctx, c.armClient, id, policyAPIVersion, definition); err != nil {
+		return &PolicyDefinition{}, fmt.Errorf("failed to get policy definition %s: %w", id, err)
+	}
+	return definition, nil
+}
+
+// GetPolicySetDefinition gets a policy set definition by its fully-qualified ID. Works for both
+// built-in and custom policy set definitions.
+func (c *PoliciesClient) GetPolicySetDefinition(id string) (*PolicySetDefinition, error) {
+	setDefinition := &PolicySetDefinition{}
+	if err := armGet(c.ctx, c.armClient, id, policyAPIVersion, setDefinition); err != nil {
+		return &PolicySetDefinition{}, fmt.Errorf("failed to get policy set definition %s: %w", id, err)
+	}
+	return setDefinition, nil
+}
+
+// ManagedClustersClient is a facade over the Azure AKS managed clusters client. Code that uses this
+// instead of the actual Azure client is easier to test because it won't need to deal with
+// producing clients per subscription.
+type ManagedClustersClient struct {
+	ctx                           context.Context
+	managedClustersClientProducer func(string) (*armcontainerservice.ManagedClustersClient, error)
+}
+
+// NewManagedClustersClient creates a new ManagedClustersClient (our facade client) from a client
+// from the Azure SDK.
+func NewManagedClustersClient(ctx context.Context, azManagedClustersClientProducer func(subscriptionID string) (*armcontainerservice.ManagedClustersClient, error)) *ManagedClustersClient {
+	return &ManagedClustersClient{
+		ctx:                           ctx,
+		managedClustersClientProducer: azManagedClustersClientProducer,
+	}
+}
+
+// GetManagedCluster gets an AKS cluster, including its node pools.
+func (c *ManagedClustersClient) GetManagedCluster(resourceGroup, name, subscriptionID string) (*armcontainerservice.ManagedCluster, error) {
+	client, err := c.managedClustersClientProducer(subscriptionID)
+	if err != nil {
+		return &armcontainerservice.ManagedCluster{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+	resp, err := client.Get(c.ctx, resourceGroup, name, nil)
+	if err != nil {
+		return &armcontainerservice.ManagedCluster{}, fmt.Errorf("failed to get AKS cluster %s: %w", name, err)
+	}
+	return &resp.ManagedCluster, nil
+}
+
+// GetKubernetesVersions gets the Kubernetes versions AKS supports in a location.
+func (c *ManagedClustersClient) GetKubernetesVersions(location, subscriptionID string) ([]*armcontainerservice.KubernetesVersion, error) {
+	client, err := c.managedClustersClientProducer(subscriptionID)
+	if err != nil {
+		return []*armcontainerservice.KubernetesVersion{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+	resp, err := client.ListKubernetesVersions(c.ctx, location, nil)
+	if err != nil {
+		return []*armcontainerservice.KubernetesVersion{}, fmt.Errorf("failed to list Kubernetes versions in location %s: %w", location, err)
+	}
+	return resp.Values, nil
+}
+
+// Subscription is an Azure subscription. Only the properties the plugin needs are included.
+type Subscription struct {
+	ID                   *string               `json:"id,omitempty"`
+	SubscriptionID       *string               `json:"subscriptionId,omitempty"`
+	DisplayName          *string               `json:"displayName,omitempty"`
+	TenantID             *string               `json:"tenantId,omitempty"`
+	State                *string               `json:"state,omitempty"`
+	SubscriptionPolicies *SubscriptionPolicies `json:"subscriptionPolicies,omitempty"`
+}
+
+// SubscriptionPolicies are the policies of a Subscription. QuotaID identifies the subscription's
+// offer (e.g. "FreeTrial_2014-09-01") and SpendingLimit is "On", "Off", or "CurrentPeriodOff".
+type SubscriptionPolicies struct {
+	QuotaID       *string `json:"quotaId,omitempty"`
+	SpendingLimit *string `json:"spendingLimit,omitempty"`
+}
+
+// SubscriptionsClient is a facade over the Azure Resource Manager client for subscriptions and the
+// Azure management groups client. Code that uses this instead of the actual Azure clients is
+// easier to test because it won't need to deal with HTTP requests.
+type SubscriptionsClient struct {
+	ctx                    context.Context
+	armClient              *arm.Client
+	managementGroupsClient *armmanagementgroups.Client
+}
+
+// NewSubscriptionsClient creates a new SubscriptionsClient (our facade client) from clients from
+// the Azure SDK.
+func NewSubscriptionsClient(ctx context.Context, armClient *arm.Client, azManagementGroupsClient *armmanagementgroups.Client) *SubscriptionsClient {
+	return &SubscriptionsClient{
+		ctx:                    ctx,
+		armClient:              armClient,
+		managementGroupsClient: azManagementGroupsClient,
+	}
+}
+
+// GetSubscription gets a subscription.
+func (c *SubscriptionsClient) GetSubscription(subscriptionID string) (*Subscription, error) {
+	subscription := &Subscription{}
+	if err := armGet(c.ctx, c.armClient, fmt.Sprintf("/subscriptions/%s", url.PathEscape(subscriptionID)), subscriptionAPIVersion, subscription); err != nil {
+		return &Subscription{}, fmt.Errorf("failed to get subscription %s: %w", subscriptionID, err)
+	}
+	return subscription, nil
+}
+
+// GetManagementGroup gets a management group, including its entire hierarchy of descendant
+// management groups and subscriptions.
+func (c *SubscriptionsClient) GetManagementGroup(groupID string) (*armmanagementgroups.ManagementGroup, error) {
+	resp, err := c.managementGroupsClient.Get(c.ctx, groupID, &armmanagementgroups.ClientGetOptions{
+		Expand:  util.Ptr(armmanagementgroups.ManagementGroupExpandTypeChildren),
+		Recurse: util.Ptr(true),
+	})
+	if err != nil {
+		return &armmanagementgroups.ManagementGroup{}, fmt.Errorf("failed to get management group %s: %w", groupID, err)
+	}
+	return &resp.ManagementGroup, nil
+}
+
+// ManagementLock is an Azure management lock.
+type ManagementLock struct {
+	ID         *string                   `json:"id,omitempty"`
+	Name       *string                   `json:"name,omitempty"`
+	Properties *ManagementLockProperties `json:"properties,omitempty"`
+}
+
+// ManagementLockProperties are the properties of a ManagementLock. Level is "CanNotDelete" or
+// "ReadOnly".
+type ManagementLockProperties struct {
+	Level *string `json:"level,omitempty"`
+	Notes *string `json:"notes,omitempty"`
+}
+
+// LocksClient is a facade over the Azure Resource Manager client for management locks. Code that
+// uses this instead of the actual Azure client is easier to test because it won't need to deal
+// with HTTP requests.
+type LocksClient struct {
+	ctx       context.Context
+	armClient *arm.Client
+}
+
+// NewLocksClient creates a new LocksClient (our facade client) from a client from the Azure SDK.
+func NewLocksClient(ctx context.Context, armClient *arm.Client) *LocksClient {
+	return &LocksClient{
+		ctx:       ctx,
+		armClient: armClient,
+	}
+}
+
+// GetLocksForScope gets all the management locks that apply to a scope, including those at higher
+// level scopes and those on resources under the scope (e.g. on resources in a resource group).
+// Filtering by atScope() only returns the former, so locks are listed with and without the filter.
+func (c *LocksClient) GetLocksForScope(scope string) ([]*ManagementLock, error) {
+	path := fmt.Sprintf("%s/providers/Microsoft.Authorization/locks", strings.TrimSuffix(scope, "/"))
+	locks, err := armList[ManagementLock](c.ctx, c.armClient, path, locksAPIVersion, url.Values{"$filter": []string{"atScope()"}})
+	if err != nil {
+		return []*ManagementLock{}, fmt.Errorf("failed to get management locks for scope %s: %w", scope, err)
+	}
+	childLocks, err := armList[ManagementLock](c.ctx, c.armClient, path, locksAPIVersion, url.Values{})
+	if err != nil {
+		return []*ManagementLock{}, fmt.Errorf("failed to get management locks under scope %s: %w", scope, err)
+	}
+
+	ids := map[string]bool{}
+	for _, l := range locks {
+		if l != nil && l.ID != nil {
+			ids[strings.ToLower(*l.ID)] = true
+		}
+	}
+	for _, l := range childLocks {
+		if l != nil && l.ID != nil && !ids[strings.ToLower(*l.ID)] {
+			ids[strings.ToLower(*l.ID)] = true
+			locks = append(locks, l)
+		}
+	}
+	return locks, nil
+}
+
+// ResourcesClient is a facade over the Azure resources client. Code that uses this instead of the
+// actual Azure client is easier to test because it won't need to deal with paging or producing
+// clients per subscription.
+type ResourcesClient struct {
+	ctx                     context.Context
+	resourcesClientProducer func(string) (*armresources.Client, error)
+}
+
+// NewResourcesClient creates a new ResourcesClient (our facade client) from a client from the
+// Azure SDK.
+func NewResourcesClient(ctx context.Context, azResourcesClientProducer func(subscriptionID string) (*armresources.Client, error)) *ResourcesClient {
+	return &ResourcesClient{
+		ctx:                     ctx,
+		resourcesClientProducer: azResourcesClientProducer,
+	}
+}
+
+// GetResources gets all the resources in a subscription, or in a resource group of it when a
+// resource group is provided. When a resource type is provided, only resources of that type are
+// returned.
+func (c *ResourcesClient) GetResources(resourceGroup, resourceType, subscriptionID string) ([]*armresources.GenericResourceExpanded, error) {
+	client, err := c.resourcesClientProducer(subscriptionID)
+	if err != nil {
+		return []*armresources.GenericResourceExpanded{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+
+	var filter *string
+	if resourceType != "" {
+		filter = util.Ptr(fmt.Sprintf("resourceType eq '%s'", resourceType))
+	}
+
+	var resources []*armresources.GenericResourceExpanded
+	var pager *runtime.Pager[armresources.ClientListResponse]
+	if resourceGroup == "" {
+		pager = client.NewListPager(&armresources.ClientListOptions{Filter: filter})
+	} else {
+		rgPager := client.NewListByResourceGroupPager(resourceGroup, &armresources.ClientListByResourceGroupOptions{Filter: filter})
+		pager = runtime.NewPager(runtime.PagingHandler[armresources.ClientListResponse]{
+			More: func(armresources.ClientListResponse) bool {
+				return rgPager.More()
+			},
+			Fetcher: func(ctx context.Context, _ *armresources.ClientListResponse) (armresources.ClientListResponse, error) {
+				resp, err := rgPager.NextPage(ctx)
+				return armresources.ClientListResponse{ResourceListResult: resp.ResourceListResult}, err
+			},
+		})
+	}
+
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			nextResult, err := pager.NextPage(c.ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", err)
+				return
+			}
+			if nextResult.Value != nil {
+				resources = append(resources, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err := <-ch:
+		return resources, err
+	case <-c.ctx.Done():
+		return resources, fmt.Errorf("context cancelled")
+	}
+}
+
+// ResourceGraphQueryResult is the result of an Azure Resource Graph query. Each row maps the
+// query's column names to values. Truncated is true when Azure Resource Graph didn't return all of
+// the rows matching the query.
+type ResourceGraphQueryResult struct {
+	Rows      []map[string]any
+	Truncated bool
+}
+
+// ResourceGraphClient is a facade over the Azure Resource Graph client. Code that uses this instead
+// of the actual Azure client is easier to test because it won't need to deal with paging or
+// decoding untyped query results.
+type ResourceGraphClient struct {
+	ctx    context.Context
+	client *armresourcegraph.Client
+}
+
+// NewResourceGraphClient creates a new ResourceGraphClient (our facade client) from a client from
+// the Azure SDK.
+func NewResourceGraphClient(ctx context.Context, azResourceGraphClient *armresourcegraph.Client) *ResourceGraphClient {
+	return &ResourceGraphClient{
+		ctx:    ctx,
+		client: azResourceGraphClient,
+	}
+}
+
+// QueryResources runs a KQL query against Azure Resource Graph, scoped to either a set of
+// subscriptions or a management group, and returns all pages of the result.
+func (c *ResourceGraphClient) QueryResources(query string, subscriptionIDs []string, managementGroup string) (*ResourceGraphQueryResult, error) {
+	req := armresourcegraph.QueryRequest{
+		Query: util.Ptr(query),
+		Options: &armresourcegraph.QueryRequestOptions{
+			ResultFormat: util.Ptr(armresourcegraph.ResultFormatObjectArray),
+		},
+	}
+	for _, id := range subscriptionIDs {
+		req.Subscriptions = append(req.Subscriptions, util.Ptr(id))
+	}
+	if managementGroup != "" {
+		req.ManagementGroups = []*string{util.Ptr(managementGroup)}
+	}
+
+	result := &ResourceGraphQueryResult{Rows: []map[string]any{}}
+	for {
+		resp, err := c.client.Resources(c.ctx, req, nil)
+		if err != nil {
+			return &ResourceGraphQueryResult{}, fmt.Errorf("failed to query Azure Resource Graph: %w", err)
+		}
+		data, ok := resp.Data.([]any)
+		if !ok {
+			return &ResourceGraphQueryResult{}, fmt.Errorf("unexpected Azure Resource Graph result format %T", resp.Data)
+		}
+		for _, d := range data {
+			row, ok := d.(map[string]any)
+			if !ok {
+				return &ResourceGraphQueryResult{}, fmt.Errorf("unexpected Azure Resource Graph row format %T", d)
+			}
+			result.Rows = append(result.Rows, row)
+		}
+		if resp.SkipToken == nil || *resp.SkipToken == "" {
+			result.Truncated = resp.ResultTruncated != nil && *resp.ResultTruncated == armresourcegraph.ResultTruncatedTrue
+			return result, nil
+		}
+		req.Options.SkipToken = resp.SkipToken
+	}
+}
+
+// Feature is a preview feature of a resource provider. State is e.g. "NotRegistered",
+// "Registering", or "Registered".
+type Feature struct {
+	ID         *string            `json:"id,omitempty"`
+	Name       *string            `json:"name,omitempty"`
+	Properties *FeatureProperties `json:"properties,omitempty"`
+}
+
+// FeatureProperties are the properties of a Feature.
+type FeatureProperties struct {
+	State *string `json:"state,omitempty"`
+}
+
+// FeatureRegistration is the registration of a preview feature in a subscription. Unlike Feature,
+// it includes when the feature was registered. RegistrationDate is left as a string because its
+// format isn't guaranteed to be RFC 3339.
+type FeatureRegistration struct {
+	ID         *string                        `json:"id,omitempty"`
+	Properties *FeatureRegistrationProperties `json:"properties,omitempty"`
+}
+
+// FeatureRegistrationProperties are the properties of a FeatureRegistration.
+type FeatureRegistrationProperties struct {
+	State            *string `json:"state,omitempty"`
+	RegistrationDate *string `json:"registrationDate,omitempty"`
+}
+
+// ActivityLogEvent is an event in the activity log of a subscription. Only the properties the
+// plugin needs are included.
+type ActivityLogEvent struct {
+	OperationName  *LocalizableString `json:"operationName,omitempty"`
+	Status         *LocalizableString `json:"status,omitempty"`
+	EventTimestamp *time.Time         `json:"eventTimestamp,omitempty"`
+}
+
+// LocalizableString is a string returned by Azure with a localized version of it.
+type LocalizableString struct {
+	Value          *string `json:"value,omitempty"`
+	LocalizedValue *string `json:"localizedValue,omitempty"`
+}
+
+// FeaturesClient is a facade over the Azure Resource Manager client for preview features and the
+// activity log, which shows when resource providers were registered. Code that uses this instead
+// of the actual Azure client is easier to test because it won't need to deal with HTTP requests.
+type FeaturesClient struct {
+	ctx       context.Context
+	armClient *arm.Client
+}
+
+// NewFeaturesClient creates a new FeaturesClient (our facade client) from a client from the Azure
+// SDK.
+func NewFeaturesClient(ctx context.Context, armClient *arm.Client) *FeaturesClient {
+	return &FeaturesClient{
+		ctx:       ctx,
+		armClient: armClient,
+	}
+}
+
+// GetFeature gets a preview feature of a resource provider, including its state in a subscription.
+func (c *FeaturesClient) GetFeature(providerNamespace, featureName, subscriptionID string) (*Feature, error) {
+	path := fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Features/providers/%s/features/%s", url.PathEscape(subscriptionID), url.PathEscape(providerNamespace), url.PathEscape(featureName))
+	feature := &Feature{}
+	if err := armGet(c.ctx, c.armClient, path, featuresAPIVersion, feature); err != nil {
+		return &Feature{}, fmt.Errorf("failed to get feature %s/%s: %w", providerNamespace, featureName, err)
+	}
+	return feature, nil
+}
+
+// GetFeatureRegistration gets the registration of a preview feature in a subscription.
+func (c *FeaturesClient) GetFeatureRegistration(providerNamespace, featureName, subscriptionID string) (*FeatureRegistration, error) {
+	path := fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Features/featureProviders/%s/subscriptionFeatureRegistrations/%s", url.PathEscape(subscriptionID), url.PathEscape(providerNamespace), url.PathEscape(featureName))
+	registration := &FeatureRegistration{}
+	if err := armGet(c.ctx, c.armClient, path, featuresAPIVersion, registration); err != nil {
+		return &FeatureRegistration{}, fmt.Errorf("failed to get registration of feature %s/%s: %w", providerNamespace, featureName, err)
+	}
+	return registration, nil
+}
+
+// GetProviderEvents gets the activity log events of a resource provider in a subscription since a
+// time. The activity log only retains events for 90 days.
+func (c *FeaturesClient) GetProviderEvents(providerNamespace, subscriptionID string, since time.Time) ([]*ActivityLogEvent, error) {
+	path := fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Insights/eventtypes/management/values", subscriptionID)
+	filter := fmt.Sprintf("eventTimestamp ge '%s' and eventTimestamp le '%s' and resourceProvider eq '%s'",
+		since.UTC().Format(time.RFC3339), time.Now().UTC().Format(time.RFC3339), providerNamespace)
+	query := url.Values{
+		"$filter": []string{filter},
+		"$select": []string{"operationName,status,eventTimestamp"},
+	}
+	events, err := armList[ActivityLogEvent](c.ctx, c.armClient, path, activityLogAPIVersion, query)
+	if err != nil {
+		return []*ActivityLogEvent{}, fmt.Errorf("failed to get activity log events of resource provider %s: %w", providerNamespace, err)
+	}
+	return events, nil
+}
+
+// CapacityReservationsClient is a facade over the Azure capacity reservation groups and capacity
+// reservations clients. Code that uses this instead of the actual Azure clients is easier to test
+// because it won't need to deal with paging or producing clients per subscription.
+type CapacityReservationsClient struct {
+	ctx                                     context.Context
+	capacityReservationGroupsClientProducer func(string) (*armcompute.CapacityReservationGroupsClient, error)
+	capacityReservationsClientProducer      func(string) (*armcompute.CapacityReservationsClient, error)
+}
+
+// NewCapacityReservationsClient creates a new CapacityReservationsClient (our facade client) from
+// clients from the Azure SDK.
+func NewCapacityReservationsClient(ctx context.Context, azCapacityReservationGroupsClientProducer func(subscriptionID string) (*armcompute.CapacityReservationGroupsClient, error), azCapacityReservationsClientProducer func(subscriptionID string) (*armcompute.CapacityReservationsClient, error)) *CapacityReservationsClient {
+	return &CapacityReservationsClient{
+		ctx:                                     ctx,
+		capacityReservationGroupsClientProducer: azCapacityReservationGroupsClientProducer,
+		capacityReservationsClientProducer:      azCapacityReservationsClientProducer,
+	}
+}
+
+// GetCapacityReservationGroup gets a capacity reservation group, including its instance view,
+// which shows the utilization of each of its capacity reservations.
+func (c *CapacityReservationsClient) GetCapacityReservationGroup(resourceGroup, groupName, subscriptionID string) (*armcompute.CapacityReservationGroup, error) {
+	client, err := c.capacityReservationGroupsClientProducer(subscriptionID)
+	if err != nil {
+		return &armcompute.CapacityReservationGroup{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+
+	resp, err := client.Get(c.ctx, resourceGroup, groupName, &armcompute.CapacityReservationGroupsClientGetOptions{
+		Expand: util.Ptr(armcompute.CapacityReservationGroupInstanceViewTypesInstanceView),
+	})
+	if err != nil {
+		return &armcompute.CapacityReservationGroup{}, fmt.Errorf("failed to get capacity reservation group %s in resource group %s: %w", groupName, resourceGroup, err)
+	}
+	return &resp.CapacityReservationGroup, nil
+}
+
+// GetCapacityReservations gets all the capacity reservations in a capacity reservation group.
+func (c *CapacityReservationsClient) GetCapacityReservations(resourceGroup, groupName, subscriptionID string) ([]*armcompute.CapacityReservation, error) {
+	client, err := c.capacityReservationsClientProducer(subscriptionID)
+	if err != nil {
+		return []*armcompute.CapacityReservation{}, fmt.Errorf("failed to produce client with subscription ID %s: %w", subscriptionID, err)
+	}
+
+	var reservations []*armcompute.CapacityReservation
+	pager := client.NewListByCapacityReservationGroupPager(resourceGroup, groupName, nil)
+
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			nextResult, err := pager.NextPage(c.ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", err)
+				return
+			}
+			if nextResult.Value != nil {
+				reservations = append(reservations, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err := <-ch:
+		return reservations, err
+	case <-c.ctx.Done():
+		return reservations, fmt.Errorf("context cancelled")
+	}
+}
+
+// Budget is a Cost Management budget. Only the properties the plugin needs are included.
+type Budget struct {
+	ID         *string           `json:"id,omitempty"`
+	Name       *string           `json:"name,omitempty"`
+	Properties *BudgetProperties `json:"properties,omitempty"`
+}
+
+// BudgetProperties are the properties of a Budget. TimeGrain is e.g. "Monthly" or "Annually".
+// CurrentSpend and ForecastSpend are for the budget's current time grain period. Notifications are
+// keyed by notification name.
+type BudgetProperties struct {
+	Category      *string                        `json:"category,omitempty"`
+	Amount        *float64                       `json:"amount,omitempty"`
+	TimeGrain     *string                        `json:"timeGrain,omitempty"`
+	CurrentSpend  *BudgetSpend                   `json:"currentSpend,omitempty"`
+	ForecastSpend *BudgetSpend                   `json:"forecastSpend,omitempty"`
+	Notifications map[string]*BudgetNotification `json:"notifications,omitempty"`
+}
+
+// BudgetSpend is an amount spent or forecasted to be spent against a budget.
+type BudgetSpend struct {
+	Amount *float64 `json:"amount,omitempty"`
+	Unit   *string  `json:"unit,omitempty"`
+}
+
+// BudgetNotification is a notification of a Budget. Threshold is a percentage of the budget's
+// amount. ThresholdType is "Actual" or "Forecasted".
+type BudgetNotification struct {
+	Enabled       *bool    `json:"enabled,omitempty"`
+	Operator      *string  `json:"operator,omitempty"`
+	Threshold     *float64 `json:"threshold,omitempty"`
+	ThresholdType *string  `json:"thresholdType,omitempty"`
+}
+
+// BudgetsClient is a facade over the Azure Resource Manager client for Cost Management budgets. Code
+// that uses this instead of the actual Azure client is easier to test because it won't need to deal
+// with HTTP requests.
+type BudgetsClient struct {
+	ctx       context.Context
+	armClient *arm.Client
+}
+
+// NewBudgetsClient creates a new BudgetsClient (our facade client) from a client from the Azure
+// SDK.
+func NewBudgetsClient(ctx context.Context, armClient *arm.Client) *BudgetsClient {
+	return &BudgetsClient{
+		ctx:       ctx,
+		armClient: armClient,
+	}
+}
+
+// GetBudget gets a budget at a scope, including the current and forecasted spend against it.
+func (c *BudgetsClient) GetBudget(scope, budgetName string) (*Budget, error) {
+	path := fmt.Sprintf("%s/providers/Microsoft.Consumption/budgets/%s", strings.TrimSuffix(scope, "/"), url.PathEscape(budgetName))
+	budget := &Budget{}
+	if err := armGet(c.ctx, c.armClient, path, budgetsAPIVersion, budget); err != nil {
+		return &Budget{}, fmt.Errorf("failed to get budget %s at scope %s: %w", budgetName, scope, err)
+	}
+	return budget, nil
+}
+
+// armGet makes a GET request to an Azure Resource Manager path with the generic Azure Resource
+// Manager client and unmarshals the JSON response body into v. Errors for non-200 responses are
+// Azure SDK response errors, so they can be inspected the same way as errors from SDK clients.
+func armGet(ctx context.Context, client *arm.Client, path, apiVersion string, v any) error {
+	return armGetURL(ctx, client, runtime.JoinPaths(client.Endpoint(), path), url.Values{"api-version": []string{apiVersion}}, v)
+}
+
+// armList makes GET requests to an Azure Resource Manager path that returns a list, following
+// next links until all pages have been retrieved, and returns the items from all pages.
+func armList[T any](ctx context.Context, client *arm.Client, path, apiVersion string, query url.Values) ([]*T, error) {
+	var items []*T
+	endpoint := runtime.JoinPaths(client.Endpoint(), path)
+	query.Set("api-version", apiVersion)
+	for endpoint != "" {
+		page := struct {
+			Value    []*T    `json:"value"`
+			NextLink *string `json:"nextLink"`
+		}{}
+		if err := armGetURL(ctx, client, endpoint, query, &page); err != nil {
+			return items, fmt.Errorf("failed to get next page of results: %w", err)
+		}
+		items = append(items, page.Value...)
+		endpoint = ""
+		if page.NextLink != nil {
+			// Next links already include the query parameters needed for the next page.
+			endpoint = *page.NextLink
+			query = url.Values{}
+		}
+	}
+	return items, nil
+}
+
+// armGetURL makes a GET request to a URL with the generic Azure Resource Manager client, adding
+// query parameters to the ones already in the URL, and unmarshals the JSON response body into v.
+func armGetURL(ctx context.Context, client *arm.Client, endpoint string, query url.Values, v any) error {
+	return getURL(ctx, client.Pipeline(), endpoint, query, v)
+}
+
+// graphList makes GET requests to a Microsoft Graph URL that returns a collection, following next
+// links until all pages have been retrieved, and returns the items from all pages.
+func graphList[T any](ctx context.Context, client *azcore.Client, endpoint string, query url.Values) ([]*T, error) {
+	var items []*T
+	for endpoint != "" {
+		page := struct {
+			Value    []*T    `json:"value"`
+			NextLink *string `json:"@odata.nextLink"`
+		}{}
+		if err := getURL(ctx, client.Pipeline(), endpoint, query, &page); err != nil {
+			return items, fmt.Errorf("failed to get next page of results: %w", err)
+		}
+		items = append(items, page.Value...)
+		endpoint = ""
+		if page.NextLink != nil {
+			// Next links already include the query parameters needed for the next page.
+			endpoint = *page.NextLink
+			query = url.Values{}
+		}
+	}
+	return items, nil
+}
+
+// getURL makes a GET request to a URL with a pipeline, adding query parameters to the ones already
+// in the URL, and unmarshals the JSON response body into v.
+func getURL(ctx context.Context, pl runtime.Pipeline, endpoint string, query url.Values, v any) error {
+	req, err := runtime.NewRequest(ctx, http.MethodGet, endpoint)
+	if err != nil {
+		return err
+	}
+	reqQP := req.Raw().URL.Query()
+	for k, vals := range query {
+		reqQP[k] = vals
+	}
+	req.Raw().URL.RawQuery = reqQP.Encode()
+	req.Raw().Header["Accept"] = []string{"application/json"}
+
+	resp, err := pl.Do(req)
+	if err != nil {
+		return err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return runtime.NewResponseError(resp)
+	}
+	return runtime.UnmarshalAsJSON(resp, v)
+}
+
+// azureCloudFromEnv returns the Azure cloud to use based on the AZURE_ENVIRONMENT environment
+// variable.
+func azureCloudFromEnv() cloud.Configuration {
+	switch os.Getenv("AZURE_ENVIRONMENT") {
+	case "AzureUSGovernment":
+		return cloud.AzureGovernment
+	case "AzureChinaCloud":
+		return cloud.AzureChina
+	default:
+		// Includes the env var being unset and it being set to "AzureCloud" or any other value.
+		return cloud.AzurePublic
+	}
+}
+
+// graphEndpointFromEnv returns the Microsoft Graph endpoint of the Azure cloud to use based on the
+// AZURE_ENVIRONMENT environment variable.
+func graphEndpointFromEnv() string {
+	switch os.Getenv("AZURE_ENVIRONMENT") {
+	case "AzureUSGovernment":
+		return "https://graph.microsoft.us"
+	case "AzureChinaCloud":
+		return "https://microsoftgraph.chinacloudapi.cn"
+	default:
+		// Includes the env var being unset and it being set to "AzureCloud" or any other value.
+		return "https://graph.microsoft.com"
 	}
 }
@@ -39,6 +39,21 @@ func authFailed(err error) bool {
 	return errors.As(err, &rerr) && rerr.ErrorCode == "AuthorizationFailed"
 }
 
+// graphAuthFailed returns whether the issue that caused error err to be returned by Microsoft Graph
+// when it was used for an API request was that the security principal authenticated was
+// unauthorized (e.g. missing required Microsoft Graph application permission).
+//   - err: An error returned by Microsoft Graph during an API request.
+func graphAuthFailed(err error) bool {
+	return strings.Contains(err.Error(), "Authorization_RequestDenied")
+}
+
+// IsNotFound returns whether the issue that caused error err to be returned by the Azure SDK when it
+// was used for an API request was that the requested resource does not exist.
+//   - err: An error returned by the Azure SDK during an API request.
+func IsNotFound(err error) bool {
+	return strings.Contains(err.Error(), "RESPONSE 404")
+}
+
 // AsAugmented checks whether an error returned by the Azure SDK matched some known Azure errors. If
 // the error matches, it produces a new, augmented error by adding information we think will help
 // the user use the plugin correctly. If it didn't match, it returns the error as is.
@@ -57,6 +72,9 @@ func AsAugmented(err error, permissionsNeeded []string) error {
 	if authFailed(err) {
 		return fmt.Errorf("plugin authenticated as service principal successfully but principal was unauthorized; ensure principal has permissions %s via role assignments and that no deny assignments forbid them: %w", permissionsNeeded, err)
 	}
+	if graphAuthFailed(err) {
+		return fmt.Errorf("plugin authenticated as service principal successfully but principal was unauthorized; ensure principal has Microsoft Graph application permissions %s and that admin consent was granted for them: %w", permissionsNeeded, err)
+	}
 
 	return err
 }
@@ -56,8 +56,30 @@ func Validate(ctx context.Context, spec v1alpha1.AzureValidatorSpec, log logr.Lo
 	daClient := utils.NewDenyAssignmentsClient(ctx, azureAPI.DenyAssignmentsClient)
 	raClient := utils.NewRoleAssignmentsClient(ctx, azureAPI.RoleAssignmentsClient)
 	rdClient := utils.NewRoleDefinitionsClient(ctx, azureAPI.RoleDefinitionsClient)
-	cgiClient := utils.NewCommunityGalleryImagesClient(ctx, azureAPI.CommunityGalleryImagesClientProducer)
+	cgiClient := utils.NewCommunityGalleryImagesClient(ctx, azureAPI.CommunityGalleryImagesClientProducer, azureAPI.CommunityGalleryImageVersionsClientProducer)
 	qClient := utils.NewQuotasClient(ctx, azureAPI.QuotaLimitsClient, azureAPI.UsagesClient)
+	rpClient := utils.NewResourceProvidersClient(ctx, azureAPI.ProvidersClientProducer)
+	skuClient := utils.NewResourceSKUsClient(ctx, azureAPI.ResourceSKUsClientProducer)
+	miClient := utils.NewMarketplaceImagesClient(ctx, azureAPI.ARMClient, azureAPI.VirtualMachineImagesClientProducer)
+	giClient := utils.NewGalleryImagesClient(ctx, azureAPI.GalleryImagesClientProducer, azureAPI.GalleryImageVersionsClientProducer)
+	vnetClient := utils.NewVirtualNetworksClient(ctx, azureAPI.VirtualNetworksClientProducer)
+	nsgClient := utils.NewNetworkSecurityGroupsClient(ctx, azureAPI.SecurityGroupsClientProducer)
+	policyClient := utils.NewPoliciesClient(ctx, azureAPI.ARMClient)
+	kvClient := utils.NewKeyVaultsClient(ctx, azureAPI.VaultsClientProducer, azureAPI.SecretsClientProducer, azureAPI.KeysClientProducer)
+	saClient := utils.NewStorageAccountsClient(ctx, azureAPI.StorageAccountsClientProducer, azureAPI.BlobContainersClientProducer)
+	dnsClient := utils.NewDNSZonesClient(ctx, azureAPI.DNSZonesClientProducer, azureAPI.PrivateDNSZonesClientProducer, azureAPI.VirtualNetworkLinksClientProducer)
+	rgClient := utils.NewResourceGroupsClient(ctx, azureAPI.ResourceGroupsClientProducer)
+	idClient := utils.NewManagedIdentitiesClient(ctx, azureAPI.UserAssignedIdentitiesClientProducer, azureAPI.FederatedIdentityCredentialsClientProducer)
+	appClient := utils.NewApplicationsClient(ctx, azureAPI.GraphClient, azureAPI.GraphEndpoint)
+	aksClient := utils.NewManagedClustersClient(ctx, azureAPI.ManagedClustersClientProducer)
+	subClient := utils.NewSubscriptionsClient(ctx, azureAPI.ARMClient, azureAPI.ManagementGroupsClient)
+	lockClient := utils.NewLocksClient(ctx, azureAPI.ARMClient)
+	resClient := utils.NewResourcesClient(ctx, azureAPI.ResourcesClientProducer)
+	rgqClient := utils.NewResourceGraphClient(ctx, azureAPI.ResourceGraphClient)
+	groupClient := utils.NewGroupsClient(ctx, azureAPI.GraphClient, azureAPI.GraphEndpoint)
+	featureClient := utils.NewFeaturesClient(ctx, azureAPI.ARMClient)
+	crClient := utils.NewCapacityReservationsClient(ctx, azureAPI.CapacityReservationGroupsClientProducer, azureAPI.CapacityReservationsClientProducer)
+	budgetClient := utils.NewBudgetsClient(ctx, azureAPI.ARMClient)
 
 	// RBAC rules
 	rbacSvc := azure.NewRBACRuleService(daClient, raClient, rdClient)
@@ -89,6 +111,243 @@ func Validate(ctx context.Context, spec v1alpha1.AzureValidatorSpec, log logr.Lo
 		resp.AddResult(vrr, err)
 	}
 
+	// Resource provider rules
+	rpSvc := azure.NewResourceProviderRuleService(rpClient, log)
+	for _, rule := range spec.ResourceProviderRules {
+		vrr, err := rpSvc.ReconcileResourceProviderRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile resource provider rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// VM size rules
+	vmSizeSvc := azure.NewVMSizeRuleService(skuClient, log)
+	for _, rule := range spec.VMSizeRules {
+		vrr, err := vmSizeSvc.ReconcileVMSizeRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile VM size rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Marketplace image rules
+	miSvc := azure.NewMarketplaceImageRuleService(miClient, log)
+	for _, rule := range spec.MarketplaceImageRules {
+		vrr, err := miSvc.ReconcileMarketplaceImageRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile marketplace image rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Gallery image rules
+	giSvc := azure.NewGalleryImageRuleService(giClient, log)
+	for _, rule := range spec.GalleryImageRules {
+		vrr, err := giSvc.ReconcileGalleryImageRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile gallery image rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Subnet rules
+	subnetSvc := azure.NewSubnetRuleService(vnetClient, log)
+	for _, rule := range spec.SubnetRules {
+		vrr, err := subnetSvc.ReconcileSubnetRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile subnet rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Network security group rules
+	nsgSvc := azure.NewNetworkSecurityGroupRuleService(nsgClient, vnetClient, log)
+	for _, rule := range spec.NetworkSecurityGroupRules {
+		vrr, err := nsgSvc.ReconcileNetworkSecurityGroupRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile network security group rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Policy rules
+	policySvc := azure.NewPolicyRuleService(policyClient, log)
+	for _, rule := range spec.PolicyRules {
+		vrr, err := policySvc.ReconcilePolicyRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile policy rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Key Vault rules
+	kvSvc := azure.NewKeyVaultRuleService(kvClient, rbacSvc, log)
+	for _, rule := range spec.KeyVaultRules {
+		vrr, err := kvSvc.ReconcileKeyVaultRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile key vault rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Storage account rules
+	saSvc := azure.NewStorageAccountRuleService(saClient, log)
+	for _, rule := range spec.StorageAccountRules {
+		vrr, err := saSvc.ReconcileStorageAccountRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile storage account rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// DNS zone rules
+	dnsSvc := azure.NewDNSZoneRuleService(dnsClient, rbacSvc, log)
+	for _, rule := range spec.DNSZoneRules {
+		vrr, err := dnsSvc.ReconcileDNSZoneRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile DNS zone rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Resource group rules
+	rgSvc := azure.NewResourceGroupRuleService(rgClient)
+	for _, rule := range spec.ResourceGroupRules {
+		vrr, err := rgSvc.ReconcileResourceGroupRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile resource group rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Managed identity rules
+	idSvc := azure.NewManagedIdentityRuleService(idClient, log)
+	for _, rule := range spec.ManagedIdentityRules {
+		vrr, err := idSvc.ReconcileManagedIdentityRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile managed identity rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Application credential rules
+	//
+	// Microsoft Graph returns the first three characters of client secrets as their hints, which
+	// lets the service find the client secret the plugin authenticates with.
+	authSecretHint := os.Getenv("AZURE_CLIENT_SECRET")
+	if len(authSecretHint) > 3 {
+		authSecretHint = authSecretHint[:3]
+	}
+	appSvc := azure.NewApplicationCredentialRuleService(appClient, os.Getenv("AZURE_CLIENT_ID"), authSecretHint, log)
+	for _, rule := range spec.ApplicationCredentialRules {
+		vrr, err := appSvc.ReconcileApplicationCredentialRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile application credential rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// AKS cluster rules
+	aksSvc := azure.NewAKSClusterRuleService(aksClient, log)
+	for _, rule := range spec.AKSClusterRules {
+		vrr, err := aksSvc.ReconcileAKSClusterRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile AKS cluster rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Subscription rules
+	subSvc := azure.NewSubscriptionRuleService(subClient, log)
+	for _, rule := range spec.SubscriptionRules {
+		vrr, err := subSvc.ReconcileSubscriptionRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile subscription rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Lock rules
+	lockSvc := azure.NewLockRuleService(lockClient, log)
+	for _, rule := range spec.LockRules {
+		vrr, err := lockSvc.ReconcileLockRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile lock rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Role definition rules
+	rdefSvc := azure.NewRoleDefinitionRuleService(rdClient, log)
+	for _, rule := range spec.RoleDefinitionRules {
+		vrr, err := rdefSvc.ReconcileRoleDefinitionRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile role definition rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Tag rules
+	tagSvc := azure.NewTagRuleService(resClient, log)
+	for _, rule := range spec.TagRules {
+		vrr, err := tagSvc.ReconcileTagRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile tag rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Resource Graph query rules
+	rgqSvc := azure.NewResourceGraphQueryRuleService(rgqClient, log)
+	for _, rule := range spec.ResourceGraphQueryRules {
+		vrr, err := rgqSvc.ReconcileResourceGraphQueryRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile Resource Graph query rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Group membership rules
+	groupSvc := azure.NewGroupMembershipRuleService(groupClient, log)
+	for _, rule := range spec.GroupMembershipRules {
+		vrr, err := groupSvc.ReconcileGroupMembershipRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile group membership rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Feature registration rules
+	featureSvc := azure.NewFeatureRegistrationRuleService(featureClient, rpClient, log)
+	for _, rule := range spec.FeatureRegistrationRules {
+		vrr, err := featureSvc.ReconcileFeatureRegistrationRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile feature registration rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Capacity reservation rules
+	crSvc := azure.NewCapacityReservationRuleService(crClient, log)
+	for _, rule := range spec.CapacityReservationRules {
+		vrr, err := crSvc.ReconcileCapacityReservationRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile capacity reservation rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
+	// Budget rules
+	budgetSvc := azure.NewBudgetRuleService(budgetClient, log)
+	for _, rule := range spec.BudgetRules {
+		vrr, err := budgetSvc.ReconcileBudgetRule(rule)
+		if err != nil {
+			log.Error(err, "failed to reconcile budget rule")
+		}
+		resp.AddResult(vrr, err)
+	}
+
 	return resp
 }
 